@@ -2,14 +2,20 @@ package bot
 
 import (
 	"log"
+	"strings"
 
 	"github.com/bwmarrin/discordgo"
 
 	"github.com/example/discord-bookmark-manager/internal/commands"
 	"github.com/example/discord-bookmark-manager/internal/config"
+	"github.com/example/discord-bookmark-manager/internal/email"
+	"github.com/example/discord-bookmark-manager/internal/feed"
 	"github.com/example/discord-bookmark-manager/internal/handlers"
+	"github.com/example/discord-bookmark-manager/internal/outbox"
 	"github.com/example/discord-bookmark-manager/internal/reminders"
 	"github.com/example/discord-bookmark-manager/internal/store"
+	"github.com/example/discord-bookmark-manager/internal/vault"
+	"github.com/example/discord-bookmark-manager/internal/webhook"
 )
 
 // Bot encapsulates the Discord session and all registered handlers.
@@ -17,13 +23,26 @@ type Bot struct {
 	session         *discordgo.Session
 	config          *config.Config
 	store           *store.EmojiStore
+	bookmarks       *store.BookmarkStore
+	guilds          *store.GuildStore
 	registerCmd     *commands.SetBookmarkCommand
 	removeCmd       *commands.RemoveBookmarkCommand
 	listCmd         *commands.ListBookmarksCommand
 	helpCmd         *commands.HelpCommand
+	adminCmd        *commands.BookmarkAdminCommand
+	fallbackCmd     *commands.DMFallbackCommand
+	targetCmd       *commands.BookmarkTargetCommand
+	webhookCmd      *commands.BookmarkWebhookCommand
+	vaultCmd        *commands.BookmarkVaultCommand
+	emailCmd        *commands.BookmarkEmailCommand
+	feedCmd         *commands.BookmarkFeedCommand
+	bookmarksCmd    *commands.BookmarksCommand
 	reactionHandle  *handlers.ReactionHandler
 	componentHandle *handlers.ComponentHandler
+	syncHandle      *handlers.SourceSyncHandler
 	reminders       *reminders.Service
+	outbox          *outbox.Service
+	feeds           *feed.Server
 	commandIDs      []string
 }
 
@@ -39,34 +58,104 @@ func New(cfg *config.Config) (*Bot, error) {
 		return nil, err
 	}
 
+	bookmarkStore, err := store.NewBookmarkStore(cfg.BookmarkIndexPath)
+	if err != nil {
+		return nil, err
+	}
+
+	guildStore, err := store.NewGuildStore(cfg.GuildStorePath)
+	if err != nil {
+		return nil, err
+	}
+
+	starboardStore, err := store.NewStarboardStore(cfg.StarboardStorePath)
+	if err != nil {
+		return nil, err
+	}
+
 	reminderService, err := reminders.NewService(session, cfg.ReminderStorePath)
 	if err != nil {
 		return nil, err
 	}
 
-	registerCommand := commands.NewSetBookmarkCommand(emojiStore)
+	deliveryOutbox, err := outbox.NewService(session, cfg.OutboxStorePath)
+	if err != nil {
+		return nil, err
+	}
+
+	notes := vault.New(cfg.VaultRoot, cfg.VaultNameTemplate)
+	mailer := email.NewSender(email.Config{
+		Host:     cfg.SMTPHost,
+		Port:     cfg.SMTPPort,
+		Username: cfg.SMTPUsername,
+		Password: cfg.SMTPPassword,
+		From:     cfg.SMTPFrom,
+		StartTLS: cfg.SMTPStartTLS,
+	})
+	reminderService.SetEmail(mailer)
+	feedServer := feed.NewServer(cfg.FeedAddr, cfg.FeedBaseURL, emojiStore, bookmarkStore)
+	feedServer.SetReminders(reminderService)
+	webhooks := webhook.NewClient()
+	webhooks.SetAllowLoopback(cfg.WebhookAllowLoopback)
+
+	registerCommand := commands.NewSetBookmarkCommand(emojiStore, notes, mailer)
 	removeCommand := commands.NewRemoveBookmarkCommand(emojiStore)
-	listCommand := commands.NewListBookmarksCommand(emojiStore)
+	listCommand := commands.NewListBookmarksCommand(emojiStore, guildStore)
 	helpCommand := commands.NewHelpCommand()
-	reactionHandler := handlers.NewReactionHandler(emojiStore, reminderService)
-	componentHandler := handlers.NewComponentHandler(reminderService)
+	adminCommand := commands.NewBookmarkAdminCommand(guildStore, webhooks)
+	fallbackCommand := commands.NewDMFallbackCommand(emojiStore)
+	targetCommand := commands.NewBookmarkTargetCommand(emojiStore, notes, mailer)
+	webhookCommand := commands.NewBookmarkWebhookCommand(emojiStore, webhooks)
+	vaultCommand := commands.NewBookmarkVaultCommand(emojiStore, notes)
+	emailCommand := commands.NewBookmarkEmailCommand(emojiStore, mailer)
+	feedCommand := commands.NewBookmarkFeedCommand(emojiStore, feedServer)
+	bookmarksCommand := commands.NewBookmarksCommand(bookmarkStore)
+	reactionHandler := handlers.NewReactionHandler(emojiStore, bookmarkStore, guildStore, starboardStore, reminderService, deliveryOutbox, webhooks, notes, mailer)
+	reactionHandler.SetFoldSkinTones(cfg.FoldSkinTones)
+	reactionHandler.SetFeeds(cfg.FeedAddr != "")
+	componentHandler := handlers.NewComponentHandler(bookmarkStore, reminderService, notes)
+	syncHandler := handlers.NewSourceSyncHandler(bookmarkStore, guildStore, reminderService)
+	syncHandler.SetFeeds(cfg.FeedAddr != "")
+
+	dmFallback := handlers.NewDMFallback(emojiStore)
+	deliveryOutbox.SetDMFallback(dmFallback.Resolve)
+	reminderService.SetDMFallback(dmFallback.Resolve)
 
 	b := &Bot{
 		session:         session,
 		config:          cfg,
 		store:           emojiStore,
+		bookmarks:       bookmarkStore,
+		guilds:          guildStore,
 		registerCmd:     registerCommand,
 		removeCmd:       removeCommand,
 		listCmd:         listCommand,
 		helpCmd:         helpCommand,
+		adminCmd:        adminCommand,
+		fallbackCmd:     fallbackCommand,
+		targetCmd:       targetCommand,
+		webhookCmd:      webhookCommand,
+		vaultCmd:        vaultCommand,
+		emailCmd:        emailCommand,
+		feedCmd:         feedCommand,
+		bookmarksCmd:    bookmarksCommand,
 		reactionHandle:  reactionHandler,
 		componentHandle: componentHandler,
+		syncHandle:      syncHandler,
 		reminders:       reminderService,
+		outbox:          deliveryOutbox,
+		feeds:           feedServer,
 	}
 
 	session.AddHandler(b.onInteraction)
 	session.AddHandler(reactionHandler.Handle)
+	session.AddHandler(reactionHandler.HandleRemove)
+	session.AddHandler(reactionHandler.HandleRemoveAll)
+	session.AddHandler(reactionHandler.HandleEvent)
 	session.AddHandler(componentHandler.Handle)
+	session.AddHandler(syncHandler.HandleUpdate)
+	session.AddHandler(syncHandler.HandleDelete)
+	session.AddHandler(syncHandler.HandleDeleteBulk)
 
 	session.Identify.Intents = discordgo.IntentsGuilds | discordgo.IntentsGuildMessages | discordgo.IntentsGuildMessageReactions | discordgo.IntentsDirectMessages
 
@@ -83,6 +172,13 @@ func (b *Bot) Open() error {
 		return err
 	}
 
+	// Retry deliveries left over from the previous run now that the session is connected.
+	b.outbox.Start()
+
+	if err := b.feeds.Start(); err != nil {
+		return err
+	}
+
 	log.Println("bot is running. Press CTRL-C to exit")
 	return nil
 }
@@ -93,6 +189,12 @@ func (b *Bot) Close() error {
 		// Ensure no reminders fire after shutdown.
 		b.reminders.Close()
 	}
+	if b.outbox != nil {
+		b.outbox.Close()
+	}
+	if err := b.feeds.Close(); err != nil {
+		log.Printf("failed to stop feed server: %v", err)
+	}
 	if len(b.commandIDs) > 0 {
 		for _, id := range b.commandIDs {
 			if err := b.session.ApplicationCommandDelete(b.config.AppID, b.config.GuildID, id); err != nil {
@@ -114,6 +216,14 @@ func (b *Bot) registerCommands() error {
 		b.removeCmd.Definition(),
 		b.listCmd.Definition(),
 		b.helpCmd.Definition(),
+		b.adminCmd.Definition(),
+		b.fallbackCmd.Definition(),
+		b.targetCmd.Definition(),
+		b.webhookCmd.Definition(),
+		b.vaultCmd.Definition(),
+		b.emailCmd.Definition(),
+		b.feedCmd.Definition(),
+		b.bookmarksCmd.Definition(),
 	}
 
 	for _, cmd := range definitions {
@@ -141,6 +251,22 @@ func (b *Bot) onInteraction(s *discordgo.Session, i *discordgo.InteractionCreate
 			err = b.listCmd.Handle(s, i)
 		case commands.HelpCommandName:
 			err = b.helpCmd.Handle(s, i)
+		case commands.BookmarkAdminCommandName:
+			err = b.adminCmd.Handle(s, i)
+		case commands.DMFallbackCommandName:
+			err = b.fallbackCmd.Handle(s, i)
+		case commands.BookmarkTargetCommandName:
+			err = b.targetCmd.Handle(s, i)
+		case commands.BookmarkWebhookCommandName:
+			err = b.webhookCmd.Handle(s, i)
+		case commands.BookmarkVaultCommandName:
+			err = b.vaultCmd.Handle(s, i)
+		case commands.BookmarkEmailCommandName:
+			err = b.emailCmd.Handle(s, i)
+		case commands.BookmarkFeedCommandName:
+			err = b.feedCmd.Handle(s, i)
+		case commands.BookmarksCommandName:
+			err = b.bookmarksCmd.Handle(s, i)
 		}
 
 		if err != nil {
@@ -154,9 +280,48 @@ func (b *Bot) onInteraction(s *discordgo.Session, i *discordgo.InteractionCreate
 				},
 			})
 		}
+
+		b.sendDeliveryNotices(s, i)
 	case discordgo.InteractionMessageComponent:
 		if b.componentHandle != nil {
 			b.componentHandle.Handle(s, i)
 		}
 	}
 }
+
+// sendDeliveryNotices follows up on a command with any bookmarks that could not be delivered
+// while the user was unreachable by DM.
+func (b *Bot) sendDeliveryNotices(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	userID := interactionUserID(i)
+	if userID == "" || b.outbox == nil {
+		return
+	}
+
+	notices := b.outbox.Notices(userID)
+	if len(notices) == 0 {
+		return
+	}
+
+	_, err := s.FollowupMessageCreate(i.Interaction, false, &discordgo.WebhookParams{
+		Content:         strings.Join(notices, "\n"),
+		Flags:           discordgo.MessageFlagsEphemeral,
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	})
+	if err != nil {
+		// The notices stay queued for the user's next command.
+		log.Printf("failed to send delivery notices: %v", err)
+		return
+	}
+
+	b.outbox.ClearNotices(userID, notices)
+}
+
+func interactionUserID(i *discordgo.InteractionCreate) string {
+	if i.Member != nil && i.Member.User != nil {
+		return i.Member.User.ID
+	}
+	if i.User != nil {
+		return i.User.ID
+	}
+	return ""
+}
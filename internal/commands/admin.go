@@ -0,0 +1,543 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/example/discord-bookmark-manager/internal/store"
+	"github.com/example/discord-bookmark-manager/internal/webhook"
+)
+
+// BookmarkAdminCommandName identifies the slash command guild admins use for server settings.
+const BookmarkAdminCommandName = "bookmark-admin"
+
+// BookmarkAdminCommand handles the `/bookmark-admin` slash command lifecycle.
+type BookmarkAdminCommand struct {
+	guilds   *store.GuildStore
+	webhooks *webhook.Client
+}
+
+// NewBookmarkAdminCommand constructs a new BookmarkAdminCommand. Server webhook URLs are vetted
+// against the addresses webhooks may reach.
+func NewBookmarkAdminCommand(guilds *store.GuildStore, webhooks *webhook.Client) *BookmarkAdminCommand {
+	return &BookmarkAdminCommand{guilds: guilds, webhooks: webhooks}
+}
+
+// Definition returns the discordgo.ApplicationCommand definition for registration.
+func (c *BookmarkAdminCommand) Definition() *discordgo.ApplicationCommand {
+	manageGuild := int64(discordgo.PermissionManageServer)
+	dmPermission := false
+
+	return &discordgo.ApplicationCommand{
+		Name:                     BookmarkAdminCommandName,
+		Description:              "Server-wide bookmark settings (requires Manage Server)",
+		DefaultMemberPermissions: &manageGuild,
+		DMPermission:             &dmPermission,
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "show",
+				Description: "Show the current server settings",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "source-deleted",
+				Description: "Choose what happens to saved bookmarks when their source message is deleted",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "action",
+						Description: "Annotate saved copies or purge them",
+						Required:    true,
+						Choices: []*discordgo.ApplicationCommandOptionChoice{
+							{Name: "Annotate and keep the saved copy", Value: string(store.SourceDeleteAnnotate)},
+							{Name: "Purge saved copies", Value: string(store.SourceDeletePurge)},
+						},
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "privacy",
+				Description: "Choose what happens when a bookmark would reach a broader audience than its source",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "action",
+						Description: "Refuse the bookmark or post it without content",
+						Required:    true,
+						Choices: []*discordgo.ApplicationCommandOptionChoice{
+							{Name: "Refuse to post", Value: string(store.PrivacyRefuse)},
+							{Name: "Post redacted", Value: string(store.PrivacyRedact)},
+						},
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "webhook",
+				Description: "Set the webhook that receives members' webhook bookmarks (omit url to remove it)",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "url",
+						Description: "HTTPS URL that receives bookmark documents",
+						Required:    false,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommandGroup,
+				Name:        "team-emoji",
+				Description: "Emojis that save messages for everyone reacting in this server",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "set",
+						Description: "File messages anyone reacts to with an emoji into a channel",
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type:        discordgo.ApplicationCommandOptionString,
+								Name:        "emoji",
+								Description: "Emoji members react with",
+								Required:    true,
+							},
+							{
+								Type:         discordgo.ApplicationCommandOptionChannel,
+								Name:         "channel",
+								Description:  "Channel that collects the saved messages",
+								Required:     true,
+								ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText, discordgo.ChannelTypeGuildNews, discordgo.ChannelTypeGuildPublicThread, discordgo.ChannelTypeGuildPrivateThread, discordgo.ChannelTypeGuildNewsThread, discordgo.ChannelTypeGuildForum},
+							},
+							{
+								Type:        discordgo.ApplicationCommandOptionString,
+								Name:        "mode",
+								Description: "Save mode: lightweight, balanced, or complete",
+								Required:    true,
+								Choices: []*discordgo.ApplicationCommandOptionChoice{
+									{Name: "Lightweight", Value: string(store.ModeLightweight)},
+									{Name: "Balanced", Value: string(store.ModeBalanced)},
+									{Name: "Complete", Value: string(store.ModeComplete)},
+								},
+							},
+							{
+								Type:        discordgo.ApplicationCommandOptionString,
+								Name:        "color",
+								Description: "Optional hex color (e.g. #ffcc00) for the saved message embed",
+								Required:    false,
+							},
+						},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "remove",
+						Description: "Stop an emoji from saving messages for the whole server",
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type:        discordgo.ApplicationCommandOptionString,
+								Name:        "emoji",
+								Description: "Team emoji to remove",
+								Required:    true,
+							},
+						},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "precedence",
+						Description: "Choose what happens when a member's own emoji is also a team emoji",
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type:        discordgo.ApplicationCommandOptionString,
+								Name:        "rule",
+								Description: "Which mapping a colliding reaction uses",
+								Required:    true,
+								Choices: []*discordgo.ApplicationCommandOptionChoice{
+									{Name: "Save with both", Value: string(store.PrecedenceBoth)},
+									{Name: "Team emoji wins", Value: string(store.PrecedenceTeam)},
+									{Name: "Personal emoji wins", Value: string(store.PrecedencePersonal)},
+								},
+							},
+						},
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommandGroup,
+				Name:        "starboard",
+				Description: "Showcase messages that enough members react to",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "set",
+						Description: "Post messages to a showcase channel once enough members react",
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type:        discordgo.ApplicationCommandOptionString,
+								Name:        "emoji",
+								Description: "Emoji members react with",
+								Required:    true,
+							},
+							{
+								Type:         discordgo.ApplicationCommandOptionChannel,
+								Name:         "channel",
+								Description:  "Showcase channel",
+								Required:     true,
+								ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText, discordgo.ChannelTypeGuildNews},
+							},
+							{
+								Type:        discordgo.ApplicationCommandOptionInteger,
+								Name:        "threshold",
+								Description: "How many different members must react",
+								Required:    true,
+								MinValue:    floatPtr(1),
+							},
+						},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "disable",
+						Description: "Turn the starboard off; existing showcase posts stay",
+					},
+				},
+			},
+		},
+	}
+}
+
+// Handle executes the command when invoked by a user.
+func (c *BookmarkAdminCommand) Handle(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return nil
+	}
+
+	if i.GuildID == "" || i.Member == nil {
+		return fmt.Errorf("this command can only be used in a server")
+	}
+
+	if i.Member.Permissions&discordgo.PermissionManageServer == 0 {
+		return respondEphemeral(s, i, "🔒 You need the Manage Server permission to change bookmark settings.")
+	}
+
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		return fmt.Errorf("a subcommand is required")
+	}
+
+	subcommand := options[0]
+	switch subcommand.Name {
+	case "show":
+		return respondEphemeral(s, i, describeGuildSettings(c.guilds.Get(i.GuildID)))
+	case "source-deleted":
+		var rawAction string
+		for _, option := range subcommand.Options {
+			if option.Name == "action" {
+				rawAction = strings.TrimSpace(option.StringValue())
+			}
+		}
+
+		action := store.SourceDeleteAction(strings.ToLower(rawAction))
+		switch action {
+		case store.SourceDeleteAnnotate, store.SourceDeletePurge:
+		default:
+			return fmt.Errorf("invalid action. choose annotate or purge")
+		}
+
+		if err := c.guilds.Update(i.GuildID, func(settings *store.GuildSettings) {
+			settings.OnSourceDelete = action
+		}); err != nil {
+			return fmt.Errorf("failed to save server settings: %w", err)
+		}
+
+		if action == store.SourceDeletePurge {
+			return respondEphemeral(s, i, "🧹 Saved bookmarks will be deleted when their source message in this server is deleted.")
+		}
+		return respondEphemeral(s, i, "📝 Saved bookmarks will be kept and marked when their source message in this server is deleted.")
+	case "privacy":
+		var rawAction string
+		for _, option := range subcommand.Options {
+			if option.Name == "action" {
+				rawAction = strings.TrimSpace(option.StringValue())
+			}
+		}
+
+		action := store.PrivacyAction(strings.ToLower(rawAction))
+		switch action {
+		case store.PrivacyRefuse, store.PrivacyRedact:
+		default:
+			return fmt.Errorf("invalid action. choose refuse or redact")
+		}
+
+		if err := c.guilds.Update(i.GuildID, func(settings *store.GuildSettings) {
+			settings.Privacy = action
+		}); err != nil {
+			return fmt.Errorf("failed to save server settings: %w", err)
+		}
+
+		if action == store.PrivacyRedact {
+			return respondEphemeral(s, i, "🔒 Bookmarks that would reach a broader audience are posted without their content.")
+		}
+		return respondEphemeral(s, i, "🔒 Bookmarks that would reach a broader audience are not posted.")
+	case "webhook":
+		var rawURL string
+		for _, option := range subcommand.Options {
+			if option.Name == "url" {
+				rawURL = strings.TrimSpace(option.StringValue())
+			}
+		}
+
+		var endpoint *store.WebhookEndpoint
+		if rawURL != "" {
+			created, err := newWebhookEndpoint(rawURL, c.webhooks)
+			if err != nil {
+				return err
+			}
+			endpoint = &created
+		}
+
+		if err := c.guilds.Update(i.GuildID, func(settings *store.GuildSettings) {
+			settings.Webhook = endpoint
+		}); err != nil {
+			return fmt.Errorf("failed to save server settings: %w", err)
+		}
+
+		if endpoint == nil {
+			return respondEphemeral(s, i, "🪝 The server webhook was removed. Webhook bookmarks now need a personal `/bookmark-webhook`.")
+		}
+		return respondEphemeral(s, i, describeNewWebhook("Webhook bookmarks from members without their own webhook now go to", *endpoint))
+	case "team-emoji":
+		return c.handleTeamEmoji(s, i, subcommand)
+	case "starboard":
+		return c.handleStarboard(s, i, subcommand)
+	}
+
+	return fmt.Errorf("unknown subcommand %q", subcommand.Name)
+}
+
+// handleTeamEmoji runs the `/bookmark-admin team-emoji` subcommands.
+func (c *BookmarkAdminCommand) handleTeamEmoji(s *discordgo.Session, i *discordgo.InteractionCreate, group *discordgo.ApplicationCommandInteractionDataOption) error {
+	if len(group.Options) == 0 {
+		return fmt.Errorf("a subcommand is required")
+	}
+
+	subcommand := group.Options[0]
+	var rawEmoji, rawMode, rawColor, rawRule string
+	var channel *discordgo.Channel
+	for _, option := range subcommand.Options {
+		switch option.Name {
+		case "emoji":
+			rawEmoji = strings.TrimSpace(option.StringValue())
+		case "channel":
+			channel = option.ChannelValue(s)
+		case "mode":
+			rawMode = strings.TrimSpace(option.StringValue())
+		case "color":
+			rawColor = strings.TrimSpace(option.StringValue())
+		case "rule":
+			rawRule = strings.TrimSpace(option.StringValue())
+		}
+	}
+
+	switch subcommand.Name {
+	case "set":
+		emoji, err := parseSingleEmoji(rawEmoji)
+		if err != nil {
+			return err
+		}
+		if channel == nil {
+			return fmt.Errorf("unable to resolve the selected channel")
+		}
+		if channel.GuildID != "" && channel.GuildID != i.GuildID {
+			return fmt.Errorf("team emojis can only file messages into a channel of this server")
+		}
+
+		target, err := buildTarget(string(store.DestinationChannel), channel.ID, rawMode, rawColor)
+		if err != nil {
+			return err
+		}
+
+		pref := store.EmojiPreference{
+			Mode:        target.Mode,
+			Color:       target.Color,
+			HasColor:    target.HasColor,
+			Destination: store.DestinationChannel,
+			ChannelID:   channel.ID,
+		}
+		if err := c.guilds.Update(i.GuildID, func(settings *store.GuildSettings) {
+			if settings.TeamEmojis == nil {
+				settings.TeamEmojis = make(map[string]store.EmojiPreference)
+			}
+			settings.TeamEmojis[emoji] = pref
+		}); err != nil {
+			return fmt.Errorf("failed to save server settings: %w", err)
+		}
+
+		return respondEphemeral(s, i, fmt.Sprintf("📚 Anyone reacting with %s in this server now files the message to <#%s> in %s mode.", formatEmojiForDisplay(emoji), channel.ID, target.Mode))
+	case "remove":
+		emoji, err := parseSingleEmoji(rawEmoji)
+		if err != nil {
+			return err
+		}
+		if _, ok := c.guilds.TeamEmoji(i.GuildID, emoji, false); !ok {
+			return respondEphemeral(s, i, fmt.Sprintf("%s is not a team emoji in this server.", formatEmojiForDisplay(emoji)))
+		}
+
+		if err := c.guilds.Update(i.GuildID, func(settings *store.GuildSettings) {
+			delete(settings.TeamEmojis, emoji)
+		}); err != nil {
+			return fmt.Errorf("failed to save server settings: %w", err)
+		}
+
+		return respondEphemeral(s, i, fmt.Sprintf("🗑️ %s no longer saves messages for the whole server.", formatEmojiForDisplay(emoji)))
+	case "precedence":
+		rule := store.EmojiPrecedence(strings.ToLower(rawRule))
+		switch rule {
+		case store.PrecedenceBoth, store.PrecedenceTeam, store.PrecedencePersonal:
+		default:
+			return fmt.Errorf("invalid rule. choose both, team, or personal")
+		}
+
+		if err := c.guilds.Update(i.GuildID, func(settings *store.GuildSettings) {
+			settings.EmojiPrecedence = rule
+		}); err != nil {
+			return fmt.Errorf("failed to save server settings: %w", err)
+		}
+
+		return respondEphemeral(s, i, fmt.Sprintf("⚖️ When a member's own emoji is also a team emoji, %s.", describePrecedence(rule)))
+	}
+
+	return fmt.Errorf("unknown subcommand %q", subcommand.Name)
+}
+
+// handleStarboard runs the `/bookmark-admin starboard` subcommands.
+func (c *BookmarkAdminCommand) handleStarboard(s *discordgo.Session, i *discordgo.InteractionCreate, group *discordgo.ApplicationCommandInteractionDataOption) error {
+	if len(group.Options) == 0 {
+		return fmt.Errorf("a subcommand is required")
+	}
+
+	subcommand := group.Options[0]
+	switch subcommand.Name {
+	case "set":
+		var rawEmoji string
+		var channel *discordgo.Channel
+		var threshold int64
+		for _, option := range subcommand.Options {
+			switch option.Name {
+			case "emoji":
+				rawEmoji = strings.TrimSpace(option.StringValue())
+			case "channel":
+				channel = option.ChannelValue(s)
+			case "threshold":
+				threshold = option.IntValue()
+			}
+		}
+
+		emoji, err := parseSingleEmoji(rawEmoji)
+		if err != nil {
+			return err
+		}
+		if channel == nil {
+			return fmt.Errorf("unable to resolve the selected channel")
+		}
+		if channel.GuildID != "" && channel.GuildID != i.GuildID {
+			return fmt.Errorf("the starboard channel must be in this server")
+		}
+		if threshold < 1 {
+			return fmt.Errorf("threshold must be at least 1")
+		}
+
+		starboard := &store.StarboardSettings{Emoji: emoji, ChannelID: channel.ID, Threshold: int(threshold)}
+		if err := c.guilds.Update(i.GuildID, func(settings *store.GuildSettings) {
+			settings.Starboard = starboard
+		}); err != nil {
+			return fmt.Errorf("failed to save server settings: %w", err)
+		}
+
+		return respondEphemeral(s, i, fmt.Sprintf("🌟 Messages that get %s from %d different members are showcased in <#%s>. Reactions from bots and the author don't count.", formatEmojiForDisplay(emoji), threshold, channel.ID))
+	case "disable":
+		if err := c.guilds.Update(i.GuildID, func(settings *store.GuildSettings) {
+			settings.Starboard = nil
+		}); err != nil {
+			return fmt.Errorf("failed to save server settings: %w", err)
+		}
+
+		return respondEphemeral(s, i, "🌟 The starboard is off. Existing showcase posts stay where they are.")
+	}
+
+	return fmt.Errorf("unknown subcommand %q", subcommand.Name)
+}
+
+// parseSingleEmoji normalizes raw into the key emoji preferences are stored under.
+func parseSingleEmoji(raw string) (string, error) {
+	tokens := splitEmojiInput(raw)
+	if len(tokens) != 1 {
+		return "", fmt.Errorf("please provide exactly one emoji")
+	}
+
+	emoji := normalizeEmoji(tokens[0])
+	if emoji == "" {
+		return "", fmt.Errorf("unable to understand the provided emoji")
+	}
+
+	return emoji, nil
+}
+
+func describePrecedence(rule store.EmojiPrecedence) string {
+	switch rule {
+	case store.PrecedenceTeam:
+		return "the reaction only files the message for the team"
+	case store.PrecedencePersonal:
+		return "the reaction only saves with the member's own settings"
+	}
+
+	return "the reaction files the message for the team and saves it with the member's own settings"
+}
+
+func describeGuildSettings(settings store.GuildSettings) string {
+	var builder strings.Builder
+	builder.WriteString("⚙️ Server bookmark settings:\n")
+
+	sourceDeleted := "annotate and keep saved copies"
+	if settings.OnSourceDelete == store.SourceDeletePurge {
+		sourceDeleted = "purge saved copies"
+	}
+	builder.WriteString(fmt.Sprintf("• 🗑️ Deleted sources: %s\n", sourceDeleted))
+
+	privacy := "refuse bookmarks that reach a broader audience"
+	if settings.Privacy == store.PrivacyRedact {
+		privacy = "redact bookmarks that reach a broader audience"
+	}
+	builder.WriteString(fmt.Sprintf("• 🔒 Privacy: %s\n", privacy))
+
+	webhookURL := "none"
+	if settings.Webhook != nil {
+		webhookURL = describeWebhookURL(settings.Webhook.URL)
+	}
+	builder.WriteString(fmt.Sprintf("• 🪝 Webhook: %s\n", webhookURL))
+
+	if len(settings.TeamEmojis) == 0 {
+		builder.WriteString("• 📚 Team emojis: none\n")
+	} else {
+		builder.WriteString("• 📚 Team emojis:\n")
+		emojis := make([]string, 0, len(settings.TeamEmojis))
+		for emoji := range settings.TeamEmojis {
+			emojis = append(emojis, emoji)
+		}
+		sort.Strings(emojis)
+		for _, emoji := range emojis {
+			pref := settings.TeamEmojis[emoji]
+			builder.WriteString(fmt.Sprintf("  • %s → <#%s> (%s)\n", formatEmojiForDisplay(emoji), pref.ChannelID, pref.Mode))
+		}
+	}
+	builder.WriteString(fmt.Sprintf("• ⚖️ Collisions with personal emojis: %s\n", describePrecedence(settings.EmojiPrecedence)))
+
+	starboard := "off"
+	if settings.Starboard != nil {
+		starboard = fmt.Sprintf("%s from %d members → <#%s>", formatEmojiForDisplay(settings.Starboard.Emoji), settings.Starboard.Threshold, settings.Starboard.ChannelID)
+	}
+	builder.WriteString(fmt.Sprintf("• 🌟 Starboard: %s\n", starboard))
+
+	return builder.String()
+}
@@ -0,0 +1,161 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/example/discord-bookmark-manager/internal/store"
+)
+
+// BookmarksCommandName identifies the slash command that browses a server's channel bookmarks.
+const BookmarksCommandName = "bookmarks"
+
+const (
+	// topLimit bounds how many bookmarks `/bookmarks top` lists.
+	topLimit = 10
+	// topTitleLength bounds each listed title, in runes.
+	topTitleLength = 80
+)
+
+// topWindow is a time window `/bookmarks top` can rank over. A zero span covers all time.
+type topWindow struct {
+	value string
+	name  string
+	label string
+	span  time.Duration
+}
+
+var topWindows = []topWindow{
+	{value: "day", name: "Last 24 hours", label: "the last 24 hours", span: 24 * time.Hour},
+	{value: "week", name: "Last 7 days", label: "the last 7 days", span: 7 * 24 * time.Hour},
+	{value: "month", name: "Last 30 days", label: "the last 30 days", span: 30 * 24 * time.Hour},
+	{value: "all", name: "All time", label: "all time"},
+}
+
+// BookmarksCommand handles the `/bookmarks` slash command lifecycle.
+type BookmarksCommand struct {
+	bookmarks *store.BookmarkStore
+}
+
+// NewBookmarksCommand constructs a new BookmarksCommand.
+func NewBookmarksCommand(bookmarks *store.BookmarkStore) *BookmarksCommand {
+	return &BookmarksCommand{bookmarks: bookmarks}
+}
+
+// Definition returns the discordgo.ApplicationCommand definition for registration.
+func (c *BookmarksCommand) Definition() *discordgo.ApplicationCommand {
+	dmPermission := false
+
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0, len(topWindows))
+	for _, window := range topWindows {
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: window.name, Value: window.value})
+	}
+
+	return &discordgo.ApplicationCommand{
+		Name:         BookmarksCommandName,
+		Description:  "Browse the bookmarks posted to this server's channels",
+		DMPermission: &dmPermission,
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "top",
+				Description: "List the highest-voted open bookmarks in a channel",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:         discordgo.ApplicationCommandOptionChannel,
+						Name:         "channel",
+						Description:  "Channel the bookmarks were posted to (defaults to this one)",
+						ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText, discordgo.ChannelTypeGuildNews, discordgo.ChannelTypeGuildPublicThread, discordgo.ChannelTypeGuildPrivateThread, discordgo.ChannelTypeGuildNewsThread, discordgo.ChannelTypeGuildForum},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "window",
+						Description: "How far back to look (defaults to the last 7 days)",
+						Choices:     choices,
+					},
+				},
+			},
+		},
+	}
+}
+
+// Handle executes the command when invoked by a user.
+func (c *BookmarksCommand) Handle(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return nil
+	}
+
+	if i.GuildID == "" {
+		return fmt.Errorf("this command only works in servers")
+	}
+
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 || options[0].Name != "top" {
+		return fmt.Errorf("a subcommand is required")
+	}
+
+	channelID := i.ChannelID
+	window := topWindows[1]
+	for _, option := range options[0].Options {
+		switch option.Name {
+		case "channel":
+			channelID = option.ChannelValue(nil).ID
+		case "window":
+			for _, candidate := range topWindows {
+				if candidate.value == option.StringValue() {
+					window = candidate
+				}
+			}
+		}
+	}
+
+	var since time.Time
+	if window.span > 0 {
+		since = time.Now().Add(-window.span)
+	}
+
+	top := c.bookmarks.Top(channelID, since, topLimit)
+	return respondEphemeral(s, i, describeTop(channelID, window, top))
+}
+
+// describeTop renders the ranked bookmarks of channelID.
+func describeTop(channelID string, window topWindow, top []store.Bookmark) string {
+	if len(top) == 0 {
+		return fmt.Sprintf("📭 No open bookmarks in <#%s> have votes from %s. Vote on channel posts with 👍 and 👎.", channelID, window.label)
+	}
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "🏆 Top bookmarks in <#%s> from %s:\n", channelID, window.label)
+	for idx, bookmark := range top {
+		up, down := bookmark.Tally()
+		fmt.Fprintf(&builder, "%d. **%+d** [%s](<%s>) · 👍 %d · 👎 %d", idx+1, up-down, topTitle(bookmark), savedLink(bookmark), up, down)
+		if bookmark.AssigneeID != "" {
+			fmt.Fprintf(&builder, " · 📖 <@%s>", bookmark.AssigneeID)
+		}
+		builder.WriteString("\n")
+	}
+
+	return strings.TrimRight(builder.String(), "\n")
+}
+
+// topTitle is the bookmark's title, shortened and safe to use as link text.
+func topTitle(bookmark store.Bookmark) string {
+	title := bookmark.Title
+	if title == "" {
+		title = fmt.Sprintf("Bookmark from #%s", bookmark.ChannelName)
+	}
+	title = strings.NewReplacer("[", "(", "]", ")", "\n", " ").Replace(title)
+
+	if runes := []rune(title); len(runes) > topTitleLength {
+		title = string(runes[:topTitleLength-1]) + "…"
+	}
+
+	return title
+}
+
+func savedLink(bookmark store.Bookmark) string {
+	return fmt.Sprintf("https://discord.com/channels/%s/%s/%s", bookmark.SavedGuildID, bookmark.SavedChannelID, bookmark.SavedMessageID)
+}
@@ -0,0 +1,31 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/example/discord-bookmark-manager/internal/store"
+)
+
+func TestDescribeTopRanksWithScores(t *testing.T) {
+	top := []store.Bookmark{
+		{
+			Title:          "Design [draft] notes",
+			SavedGuildID:   "guild",
+			SavedChannelID: "reading",
+			SavedMessageID: "post",
+			AssigneeID:     "alice",
+			Votes:          map[string]int{"alice": store.VoteUp, "bob": store.VoteUp, "carol": store.VoteDown},
+		},
+	}
+
+	got := describeTop("reading", topWindows[1], top)
+	want := "🏆 Top bookmarks in <#reading> from the last 7 days:\n1. **+1** [Design (draft) notes](<https://discord.com/channels/guild/reading/post>) · 👍 2 · 👎 1 · 📖 <@alice>"
+	if got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+
+	if empty := describeTop("reading", topWindows[3], nil); !strings.Contains(empty, "from all time") {
+		t.Fatalf("expected the empty message to name the window, got %q", empty)
+	}
+}
@@ -0,0 +1,302 @@
+package commands
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/example/discord-bookmark-manager/internal/email"
+	"github.com/example/discord-bookmark-manager/internal/store"
+)
+
+// BookmarkEmailCommandName identifies the slash command that manages a user's email address.
+const BookmarkEmailCommandName = "bookmark-email"
+
+const (
+	// emailCodeLifetime is how long a verification code stays valid.
+	emailCodeLifetime = 30 * time.Minute
+	// maxEmailCodeAttempts is how many wrong codes are accepted before a new one is needed.
+	maxEmailCodeAttempts = 5
+)
+
+// BookmarkEmailCommand handles the `/bookmark-email` slash command lifecycle.
+type BookmarkEmailCommand struct {
+	store  *store.EmojiStore
+	mailer *email.Sender
+}
+
+// NewBookmarkEmailCommand constructs a new BookmarkEmailCommand.
+func NewBookmarkEmailCommand(store *store.EmojiStore, mailer *email.Sender) *BookmarkEmailCommand {
+	return &BookmarkEmailCommand{store: store, mailer: mailer}
+}
+
+// Definition returns the discordgo.ApplicationCommand definition for registration.
+func (c *BookmarkEmailCommand) Definition() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        BookmarkEmailCommandName,
+		Description: "Manage the address email bookmarks and reminders are sent to",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "add",
+				Description: "Add or change your address; a verification code is emailed to it",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "address",
+						Description: "e.g. you@example.com",
+						Required:    true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "verify",
+				Description: "Confirm your address with the emailed code",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "code",
+						Description: "The 6-digit code from the verification email",
+						Required:    true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "reminders",
+				Description: "Send your reminders by email instead of Discord",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Name:        "enabled",
+						Description: "Whether reminders are emailed",
+						Required:    true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "remove",
+				Description: "Forget your address",
+			},
+		},
+	}
+}
+
+// Handle executes the command when invoked by a user.
+func (c *BookmarkEmailCommand) Handle(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return nil
+	}
+
+	if !c.mailer.Enabled() {
+		return fmt.Errorf("email is not enabled on this bot")
+	}
+
+	var user *discordgo.User
+	if i.Member != nil {
+		user = i.Member.User
+	}
+	if user == nil {
+		user = i.User
+	}
+	if user == nil {
+		return fmt.Errorf("unable to resolve user from interaction")
+	}
+
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		return fmt.Errorf("a subcommand is required")
+	}
+	subcommand := options[0]
+
+	switch subcommand.Name {
+	case "add":
+		var raw string
+		for _, option := range subcommand.Options {
+			if option.Name == "address" {
+				raw = option.StringValue()
+			}
+		}
+		return c.add(s, i, user.ID, raw)
+	case "verify":
+		var code string
+		for _, option := range subcommand.Options {
+			if option.Name == "code" {
+				code = strings.TrimSpace(option.StringValue())
+			}
+		}
+		return c.verify(s, i, user.ID, code)
+	case "reminders":
+		var enabled bool
+		for _, option := range subcommand.Options {
+			if option.Name == "enabled" {
+				enabled = option.BoolValue()
+			}
+		}
+		return c.setReminders(s, i, user.ID, enabled)
+	case "remove":
+		if err := c.store.UpdateEmail(user.ID, func(*store.EmailSettings) *store.EmailSettings { return nil }); err != nil {
+			return fmt.Errorf("failed to remove email: %w", err)
+		}
+
+		return respondEphemeral(s, i, "📧 Your email address was removed. Email bookmarks are skipped until you add one again.")
+	}
+
+	return fmt.Errorf("unknown subcommand %q", subcommand.Name)
+}
+
+// add stores address as unverified and emails it a code. The email is sent after the reply so
+// a slow relay can't time out the interaction; failures arrive as a follow-up.
+func (c *BookmarkEmailCommand) add(s *discordgo.Session, i *discordgo.InteractionCreate, userID, raw string) error {
+	address, err := email.ValidateAddress(raw)
+	if err != nil {
+		return err
+	}
+
+	code, err := newVerificationCode()
+	if err != nil {
+		return fmt.Errorf("failed to generate a verification code: %w", err)
+	}
+
+	err = c.store.UpdateEmail(userID, func(current *store.EmailSettings) *store.EmailSettings {
+		next := &store.EmailSettings{Address: address}
+		if current != nil {
+			next.Reminders = current.Reminders
+		}
+		next.CodeHash = hashVerificationCode(code)
+		next.CodeExpires = time.Now().Add(emailCodeLifetime)
+		return next
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save email: %w", err)
+	}
+
+	if err := respondEphemeral(s, i, fmt.Sprintf("📧 I'm sending a verification code to %s. Finish with `/bookmark-email verify` within %d minutes.", address, int(emailCodeLifetime.Minutes()))); err != nil {
+		return err
+	}
+
+	go func() {
+		err := c.mailer.Send(email.Message{
+			To:      address,
+			Subject: "Your bookmark bot verification code",
+			Text:    fmt.Sprintf("Your verification code is %s.\n\nRun /bookmark-email verify in Discord to confirm this address. If you didn't ask for this, ignore this email.", code),
+			HTML:    fmt.Sprintf("<p>Your verification code is <strong>%s</strong>.</p><p>Run <code>/bookmark-email verify</code> in Discord to confirm this address. If you didn't ask for this, ignore this email.</p>", code),
+		})
+		if err == nil {
+			return
+		}
+
+		log.Printf("failed to send verification email: %v", err)
+		_, err = s.FollowupMessageCreate(i.Interaction, false, &discordgo.WebhookParams{
+			Content: fmt.Sprintf("❌ I couldn't email %s: %v", address, err),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		})
+		if err != nil {
+			log.Printf("failed to report verification email failure: %v", err)
+		}
+	}()
+
+	return nil
+}
+
+func (c *BookmarkEmailCommand) verify(s *discordgo.Session, i *discordgo.InteractionCreate, userID, code string) error {
+	var address string
+	var verifyErr error
+	err := c.store.UpdateEmail(userID, func(current *store.EmailSettings) *store.EmailSettings {
+		switch {
+		case current == nil:
+			verifyErr = fmt.Errorf("add an address with `/bookmark-email add` first")
+		case current.Verified:
+			verifyErr = fmt.Errorf("%s is already verified", current.Address)
+		case current.CodeHash == "" || time.Now().After(current.CodeExpires):
+			verifyErr = fmt.Errorf("that code has expired; run `/bookmark-email add` again for a new one")
+		case subtle.ConstantTimeCompare([]byte(hashVerificationCode(code)), []byte(current.CodeHash)) != 1:
+			current.CodeAttempts++
+			verifyErr = fmt.Errorf("that code doesn't match")
+			if current.CodeAttempts >= maxEmailCodeAttempts {
+				current.CodeHash = ""
+				verifyErr = fmt.Errorf("too many wrong codes; run `/bookmark-email add` again for a new one")
+			}
+		default:
+			current.Verified = true
+			current.CodeHash = ""
+			current.CodeExpires = time.Time{}
+			current.CodeAttempts = 0
+			address = current.Address
+		}
+		return current
+	})
+	if verifyErr != nil {
+		return verifyErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save email: %w", err)
+	}
+
+	return respondEphemeral(s, i, fmt.Sprintf("✅ %s is verified. Choose the `Email` destination in `/set-bookmark` or `/bookmark-target add` to use it.", address))
+}
+
+func (c *BookmarkEmailCommand) setReminders(s *discordgo.Session, i *discordgo.InteractionCreate, userID string, enabled bool) error {
+	var address string
+	err := c.store.UpdateEmail(userID, func(current *store.EmailSettings) *store.EmailSettings {
+		if current != nil {
+			current.Reminders = enabled
+			address = current.Address
+		}
+		return current
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save email: %w", err)
+	}
+	if address == "" {
+		return fmt.Errorf("add an address with `/bookmark-email add` first")
+	}
+
+	if !enabled {
+		return respondEphemeral(s, i, "⏰ Reminders arrive in Discord again.")
+	}
+
+	response := fmt.Sprintf("⏰ Reminders are now emailed to %s.", address)
+	if _, ok := c.store.VerifiedEmail(userID); !ok {
+		response += " They stay in Discord until you verify the address."
+	}
+
+	return respondEphemeral(s, i, response)
+}
+
+// requireVerifiedEmail rejects the email destination until userID has verified an address.
+func requireVerifiedEmail(emojis *store.EmojiStore, mailer *email.Sender, userID string) error {
+	if !mailer.Enabled() {
+		return fmt.Errorf("email is not enabled on this bot")
+	}
+	if _, ok := emojis.VerifiedEmail(userID); !ok {
+		return fmt.Errorf("verify an address with `/bookmark-email add` first")
+	}
+
+	return nil
+}
+
+// newVerificationCode returns a random 6-digit code.
+func newVerificationCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+func hashVerificationCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,114 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/example/discord-bookmark-manager/internal/store"
+)
+
+// DMFallbackCommandName identifies the slash command used to configure closed-DM fallbacks.
+const DMFallbackCommandName = "dm-fallback"
+
+// DMFallbackCommand handles the `/dm-fallback` slash command lifecycle.
+type DMFallbackCommand struct {
+	store *store.EmojiStore
+}
+
+// NewDMFallbackCommand constructs a new DMFallbackCommand.
+func NewDMFallbackCommand(store *store.EmojiStore) *DMFallbackCommand {
+	return &DMFallbackCommand{store: store}
+}
+
+// Definition returns the discordgo.ApplicationCommand definition for registration.
+func (c *DMFallbackCommand) Definition() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        DMFallbackCommandName,
+		Description: "Choose where bookmarks and reminders go when your DMs are closed",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "mode",
+				Description: "Fall back to a private thread, or drop deliveries",
+				Required:    true,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "Private thread in the source server", Value: string(store.DMFallbackThread)},
+					{Name: "No fallback", Value: string(store.DMFallbackNone)},
+				},
+			},
+			{
+				Type:         discordgo.ApplicationCommandOptionChannel,
+				Name:         "channel",
+				Description:  "Channel in this server to create your private thread in (defaults to the source channel)",
+				Required:     false,
+				ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText},
+			},
+		},
+	}
+}
+
+// Handle executes the command when invoked by a user.
+func (c *DMFallbackCommand) Handle(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return nil
+	}
+
+	var user *discordgo.User
+	if i.Member != nil {
+		user = i.Member.User
+	}
+	if user == nil {
+		user = i.User
+	}
+	if user == nil {
+		return fmt.Errorf("unable to resolve user from interaction")
+	}
+
+	var rawMode string
+	var channel *discordgo.Channel
+	for _, option := range i.ApplicationCommandData().Options {
+		switch option.Name {
+		case "mode":
+			rawMode = strings.TrimSpace(option.StringValue())
+		case "channel":
+			channel = option.ChannelValue(s)
+			if channel == nil {
+				return fmt.Errorf("unable to resolve the selected channel")
+			}
+		}
+	}
+
+	mode := store.DMFallbackMode(strings.ToLower(rawMode))
+	switch mode {
+	case store.DMFallbackThread, store.DMFallbackNone:
+	default:
+		return fmt.Errorf("invalid mode. choose thread or none")
+	}
+
+	if channel != nil && i.GuildID == "" {
+		return fmt.Errorf("pick a fallback channel from inside the server it belongs to")
+	}
+
+	if err := c.store.UpdateDMFallback(user.ID, func(fallback *store.DMFallback) {
+		fallback.Mode = mode
+		if channel != nil {
+			fallback.Channels[i.GuildID] = channel.ID
+			// The next delivery creates a fresh thread in the new channel.
+			delete(fallback.Threads, i.GuildID)
+		}
+	}); err != nil {
+		return fmt.Errorf("failed to save fallback: %w", err)
+	}
+
+	response := "📭 When your DMs are closed, bookmarks and reminders go to a private thread in the source server."
+	if channel != nil {
+		response = fmt.Sprintf("📭 When your DMs are closed, bookmarks and reminders from this server go to a private thread in <#%s>.", channel.ID)
+	}
+	if mode == store.DMFallbackNone {
+		response = "📭 DM fallback is off. Bookmarks and reminders that can't reach your DMs are not delivered."
+	}
+
+	return respondEphemeral(s, i, response)
+}
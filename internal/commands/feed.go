@@ -0,0 +1,122 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/example/discord-bookmark-manager/internal/feed"
+	"github.com/example/discord-bookmark-manager/internal/store"
+)
+
+// BookmarkFeedCommandName identifies the slash command that manages a user's bookmark feed.
+const BookmarkFeedCommandName = "bookmark-feed"
+
+// BookmarkFeedCommand handles the `/bookmark-feed` slash command lifecycle.
+type BookmarkFeedCommand struct {
+	store *store.EmojiStore
+	feeds *feed.Server
+}
+
+// NewBookmarkFeedCommand constructs a new BookmarkFeedCommand.
+func NewBookmarkFeedCommand(store *store.EmojiStore, feeds *feed.Server) *BookmarkFeedCommand {
+	return &BookmarkFeedCommand{store: store, feeds: feeds}
+}
+
+// Definition returns the discordgo.ApplicationCommand definition for registration.
+func (c *BookmarkFeedCommand) Definition() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        BookmarkFeedCommandName,
+		Description: "Follow your bookmarks in a feed reader and your reminders in a calendar",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "show",
+				Description: "Show your private feed addresses, creating them if needed",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "rotate",
+				Description: "Replace your feed addresses; the old ones stop working",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "disable",
+				Description: "Turn your feed off",
+			},
+		},
+	}
+}
+
+// Handle executes the command when invoked by a user.
+func (c *BookmarkFeedCommand) Handle(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return nil
+	}
+
+	if !c.feeds.Enabled() {
+		return fmt.Errorf("bookmark feeds are not enabled on this bot")
+	}
+
+	var user *discordgo.User
+	if i.Member != nil {
+		user = i.Member.User
+	}
+	if user == nil {
+		user = i.User
+	}
+	if user == nil {
+		return fmt.Errorf("unable to resolve user from interaction")
+	}
+
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		return fmt.Errorf("a subcommand is required")
+	}
+
+	switch options[0].Name {
+	case "show":
+		token := c.store.FeedToken(user.ID)
+		if token == "" {
+			created, err := c.rotate(user.ID)
+			if err != nil {
+				return err
+			}
+			token = created
+		}
+
+		return respondEphemeral(s, i, c.describeFeed("📰 Your bookmark feed:", token))
+	case "rotate":
+		token, err := c.rotate(user.ID)
+		if err != nil {
+			return err
+		}
+
+		return respondEphemeral(s, i, c.describeFeed("🔄 Your feed has new addresses; the old ones no longer work:", token))
+	case "disable":
+		if err := c.store.SetFeedToken(user.ID, ""); err != nil {
+			return fmt.Errorf("failed to save feed: %w", err)
+		}
+
+		return respondEphemeral(s, i, "📰 Your feed is off. `/bookmark-feed show` creates new addresses.")
+	}
+
+	return fmt.Errorf("unknown subcommand %q", options[0].Name)
+}
+
+func (c *BookmarkFeedCommand) rotate(userID string) (string, error) {
+	token, err := feed.NewToken()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate a feed token: %w", err)
+	}
+	if err := c.store.SetFeedToken(userID, token); err != nil {
+		return "", fmt.Errorf("failed to save feed: %w", err)
+	}
+
+	return token, nil
+}
+
+func (c *BookmarkFeedCommand) describeFeed(prefix, token string) string {
+	return fmt.Sprintf("%s\n• Atom: <%s>\n• RSS: <%s>\n• Reminders calendar: <%s>\nAdd `?status=open` or `?status=done`, and `?emoji=🔖` (or a custom emoji name, comma-separated for several) to filter the bookmark feeds. Anyone with these links can read your bookmarks; use `/bookmark-feed rotate` if one leaks.",
+		prefix, c.feeds.URL(token, feed.FormatAtom), c.feeds.URL(token, feed.FormatRSS), c.feeds.URL(token, feed.FormatCalendar))
+}
@@ -31,16 +31,27 @@ func (c *HelpCommand) Handle(s *discordgo.Session, i *discordgo.InteractionCreat
 		"**Basic usage:**\n" +
 		"• `/set-bookmark` — Set up an emoji with a bookmark mode\n" +
 		"  - Choose emoji, mode (Lightweight/Balanced/Complete), and optional color\n" +
+		"  - Type the emoji or its shortcode, such as `:bookmark:`\n" +
 		"  - Example: Select mode \"👀 Lightweight\" and enter color `#FFD700`\n\n" +
 		"**With reminders:**\n" +
 		"• Add `reminder` option with time like `8:00` or duration like `30m`\n" +
 		"• Use `keep-reminder-on-complete` if you want reminders to persist after marking Done\n\n" +
 		"**Send to channel:**\n" +
-		"• Set `destination` to \"# Channel\" and select a `destination-channel`\n\n" +
+		"• Set `destination` to \"# Channel\" and select a `destination-channel`\n" +
+		"• `/bookmark-target add` sends the same emoji to more destinations, each with its own mode and color\n\n" +
+		"**Per-server settings:**\n" +
+		"• Add `scope:server` (or `scope:dm`) to `/set-bookmark` to give an emoji different settings in this server (or in DMs); they win over your global settings there\n\n" +
 		"**Other commands:**\n" +
 		"• `/list-bookmarks` — View all your configured emojis\n" +
-		"• `/remove-bookmark` — Delete an emoji configuration\n\n" +
-		"React with a saved emoji to bookmark messages. Reminders always arrive in your DMs."
+		"• `/remove-bookmark` — Delete an emoji configuration\n" +
+		"• `/dm-fallback` — Choose where bookmarks go when your DMs are closed\n" +
+		"• `/bookmark-webhook` — Send `webhook` bookmarks to your own HTTP endpoint\n" +
+		"• `/bookmark-vault` — Choose how `vault` bookmarks are named as Markdown notes\n" +
+		"• `/bookmark-email` — Verify the address `email` bookmarks and reminders go to\n" +
+		"• `/bookmark-feed` — Get private Atom/RSS addresses for your bookmarks and a reminders calendar, or rotate them\n" +
+		"• `/bookmarks top` — List the highest-voted open bookmarks in a channel\n" +
+		"• `/bookmark-admin` — Server-wide settings, team emojis and the starboard for admins (Manage Server)\n\n" +
+		"React with a saved emoji to bookmark messages. Reminders arrive in your DMs unless you turn on `/bookmark-email reminders`."
 
 	return respondEphemeral(s, i, helpText)
 }
@@ -7,6 +7,7 @@ import (
 
 	"github.com/bwmarrin/discordgo"
 
+	"github.com/example/discord-bookmark-manager/internal/emojikey"
 	"github.com/example/discord-bookmark-manager/internal/reminders"
 	"github.com/example/discord-bookmark-manager/internal/store"
 )
@@ -14,14 +15,21 @@ import (
 // ListBookmarksCommandName identifies the slash command that shows saved bookmark preferences.
 const ListBookmarksCommandName = "list-bookmarks"
 
+// emojiSection is one scope of emoji preferences `/list-bookmarks` shows.
+type emojiSection struct {
+	title string
+	scope store.EmojiScope
+}
+
 // ListBookmarksCommand handles the `/list-bookmarks` slash command lifecycle.
 type ListBookmarksCommand struct {
-	store *store.EmojiStore
+	store  *store.EmojiStore
+	guilds *store.GuildStore
 }
 
 // NewListBookmarksCommand constructs a new ListBookmarksCommand.
-func NewListBookmarksCommand(store *store.EmojiStore) *ListBookmarksCommand {
-	return &ListBookmarksCommand{store: store}
+func NewListBookmarksCommand(store *store.EmojiStore, guilds *store.GuildStore) *ListBookmarksCommand {
+	return &ListBookmarksCommand{store: store, guilds: guilds}
 }
 
 // Definition returns the discordgo.ApplicationCommand definition for registration.
@@ -46,49 +54,169 @@ func (c *ListBookmarksCommand) Handle(s *discordgo.Session, i *discordgo.Interac
 		return fmt.Errorf("unable to resolve user from interaction")
 	}
 
-	prefs, ok := c.store.Get(user.ID)
-	if !ok || len(prefs.Emojis) == 0 {
-		return respondEphemeral(s, i, "📭 No bookmark emojis saved yet. Use `/set-bookmark` to create one!")
+	prefs, _ := c.store.Get(user.ID)
+	teamSection := c.describeTeamEmojis(i.GuildID, prefs)
+
+	sections := []emojiSection{{title: "⭐ Saved bookmark shortcuts:", scope: store.ScopeGlobal}}
+	if i.GuildID != "" {
+		sections = append(sections, emojiSection{title: "🏠 Only in this server:", scope: store.GuildScope(i.GuildID)})
 	}
+	sections = append(sections, emojiSection{title: "💬 Only in DMs:", scope: store.ScopeDM})
 
-	emojis := make([]string, 0, len(prefs.Emojis))
-	for emoji := range prefs.Emojis {
-		emojis = append(emojis, emoji)
+	// Scopes of other servers stay private to those servers.
+	otherServers := 0
+	for scope := range prefs.ScopedEmojis {
+		if guildID := scope.GuildID(); guildID != "" && guildID != i.GuildID {
+			otherServers++
+		}
 	}
-	sort.Strings(emojis)
 
 	var builder strings.Builder
-	builder.WriteString("⭐ Saved bookmark shortcuts:\n")
-	for _, emoji := range emojis {
-		pref := prefs.Emojis[emoji]
-		display := formatEmojiForDisplay(emoji)
-		mode := string(pref.Mode)
-		colorDescription := "default"
-		if pref.HasColor {
-			colorDescription = fmt.Sprintf("#%06X", pref.Color)
+	here := prefs.EmojisIn(store.ScopeOf(i.GuildID))
+	for _, section := range sections {
+		emojis := prefs.EmojisIn(section.scope)
+		if len(emojis) == 0 {
+			continue
 		}
-		builder.WriteString(fmt.Sprintf("• %s — %s mode (color: %s)\n", display, mode, colorDescription))
-		destinationLine := "  ↳ 📬 Destination: DMs"
-		if pref.Destination == store.DestinationChannel && pref.ChannelID != "" {
-			destinationLine = fmt.Sprintf("  ↳ 📬 Destination: <#%s>", pref.ChannelID)
+
+		if builder.Len() > 0 {
+			builder.WriteString("\n")
 		}
-		builder.WriteString(destinationLine + "\n")
-		reminderLine := fmt.Sprintf("  ↳ ⏰ Reminder: %s", reminders.Describe(pref.Reminder))
-		if pref.Reminder != nil {
-			if pref.Reminder.RemoveOnComplete {
-				reminderLine += " / ✅ clears on Done"
-			} else {
-				reminderLine += " / 🔁 stays after Done"
+		builder.WriteString(section.title + "\n")
+		for _, emoji := range sortedEmojis(emojis) {
+			note := ""
+			if _, overridden := here[emoji]; overridden && section.scope == store.ScopeGlobal {
+				note = " — overridden here"
 			}
+			writeEmojiPreference(&builder, emoji, emojis[emoji], note)
+		}
+	}
+
+	otherLine := ""
+	if otherServers > 0 {
+		otherLine = fmt.Sprintf("🌐 You have shortcuts for %d other server(s); run `/list-bookmarks` there to see them.\n", otherServers)
+	}
+
+	if builder.Len() == 0 {
+		return respondEphemeral(s, i, strings.TrimRight("📭 No bookmark emojis saved yet. Use `/set-bookmark` to create one!\n"+otherLine+teamSection, "\n"))
+	}
+	builder.WriteString(otherLine)
+
+	fallbackLine := "\n📭 Closed DMs: private thread in the source server"
+	if prefs.DMFallback.Mode == store.DMFallbackNone {
+		fallbackLine = "\n📭 Closed DMs: no fallback"
+	}
+	builder.WriteString(fallbackLine + "\n")
+	if prefs.VaultTemplate != "" {
+		builder.WriteString(fmt.Sprintf("🗃️ Vault note names: `%s`\n", prefs.VaultTemplate))
+	}
+	if prefs.Webhook != nil {
+		builder.WriteString(fmt.Sprintf("🪝 Webhook: %s\n", describeWebhookURL(prefs.Webhook.URL)))
+	}
+	if prefs.Email != nil {
+		emailLine := fmt.Sprintf("📧 Email: %s", prefs.Email.Address)
+		if !prefs.Email.Verified {
+			emailLine += " (not verified)"
 		}
-		builder.WriteString(reminderLine + "\n")
+		if prefs.Email.Reminders {
+			emailLine += " / ⏰ reminders by email"
+		}
+		builder.WriteString(emailLine + "\n")
+	}
+	if prefs.FeedToken != "" {
+		builder.WriteString("📰 Feed: on (`/bookmark-feed show` for the addresses)\n")
 	}
 
-	builder.WriteString("\nUse `/set-bookmark` to tweak settings or `/remove-bookmark` to delete one.")
+	builder.WriteString(teamSection)
+	builder.WriteString("\nUse `/set-bookmark` to tweak settings or `/remove-bookmark` to delete one. Add `scope:server` or `scope:dm` to change the settings that only apply there.")
 
 	return respondEphemeral(s, i, builder.String())
 }
 
+// writeEmojiPreference describes the preference for emoji, with note after its first line.
+func writeEmojiPreference(builder *strings.Builder, emoji string, pref store.EmojiPreference, note string) {
+	display := formatEmojiForDisplay(emoji)
+	mode := string(pref.Mode)
+	colorDescription := "default"
+	if pref.HasColor {
+		colorDescription = fmt.Sprintf("#%06X", pref.Color)
+	}
+	builder.WriteString(fmt.Sprintf("• %s — %s mode (color: %s)%s\n", display, mode, colorDescription, note))
+	primary := pref.AllTargets()[0]
+	builder.WriteString(fmt.Sprintf("  ↳ 📬 Destination: %s\n", describeTargetDestination(primary)))
+	for idx, target := range pref.Targets {
+		targetColor := "default"
+		if target.HasColor {
+			targetColor = fmt.Sprintf("#%06X", target.Color)
+		}
+		builder.WriteString(fmt.Sprintf("  ↳ 📣 Target %d: %s, %s mode (color: %s)\n", idx+2, describeTargetDestination(target), target.Mode, targetColor))
+	}
+	switch pref.Capture {
+	case store.CaptureThread:
+		builder.WriteString("  ↳ 🧵 Capture: whole thread\n")
+	case store.CaptureRange:
+		builder.WriteString(fmt.Sprintf("  ↳ 📑 Capture: range until %s\n", formatEmojiForDisplay(pref.RangeEndEmoji)))
+	}
+	if pref.Archive {
+		builder.WriteString("  ↳ 🗄️ Attachments: archived\n")
+	}
+	if pref.TrackEdits {
+		builder.WriteString("  ↳ ✏️ Tracks source edits\n")
+	}
+	if pref.Silent {
+		builder.WriteString("  ↳ 🤫 Silent: reaction removed after saving\n")
+	}
+	reminderLine := fmt.Sprintf("  ↳ ⏰ Reminder: %s", reminders.Describe(pref.Reminder))
+	if pref.Reminder != nil {
+		if pref.Reminder.RemoveOnComplete {
+			reminderLine += " / ✅ clears on Done"
+		} else {
+			reminderLine += " / 🔁 stays after Done"
+		}
+	}
+	builder.WriteString(reminderLine + "\n")
+}
+
+// describeTeamEmojis lists the server's team emojis and how each one combines with the
+// member's own settings. It is empty outside servers and in servers without team emojis.
+func (c *ListBookmarksCommand) describeTeamEmojis(guildID string, prefs store.UserPreferences) string {
+	if guildID == "" || c.guilds == nil {
+		return ""
+	}
+
+	settings := c.guilds.Get(guildID)
+	if len(settings.TeamEmojis) == 0 {
+		return ""
+	}
+
+	emojis := make([]string, 0, len(settings.TeamEmojis))
+	for emoji := range settings.TeamEmojis {
+		emojis = append(emojis, emoji)
+	}
+	sort.Strings(emojis)
+
+	personal := prefs.Effective(guildID)
+	var builder strings.Builder
+	builder.WriteString("\n📚 Team emojis in this server:\n")
+	for _, emoji := range emojis {
+		pref := settings.TeamEmojis[emoji]
+		line := fmt.Sprintf("• %s → <#%s> (%s mode)", formatEmojiForDisplay(emoji), pref.ChannelID, pref.Mode)
+		if _, collides := personal[emoji]; collides {
+			switch settings.EmojiPrecedence {
+			case store.PrecedenceTeam:
+				line += " — overrides your own settings here"
+			case store.PrecedencePersonal:
+				line += " — your own settings win here"
+			default:
+				line += " — also saved with your own settings"
+			}
+		}
+		builder.WriteString(line + "\n")
+	}
+
+	return builder.String()
+}
+
 func formatEmojiForDisplay(value string) string {
 	trimmed := strings.TrimSpace(value)
 	if trimmed == "" {
@@ -105,5 +233,5 @@ func formatEmojiForDisplay(value string) string {
 		}
 	}
 
-	return trimmed
+	return emojikey.Display(trimmed)
 }
@@ -7,8 +7,11 @@ import (
 
 	"github.com/bwmarrin/discordgo"
 
+	"github.com/example/discord-bookmark-manager/internal/email"
+	"github.com/example/discord-bookmark-manager/internal/emojikey"
 	"github.com/example/discord-bookmark-manager/internal/reminders"
 	"github.com/example/discord-bookmark-manager/internal/store"
+	"github.com/example/discord-bookmark-manager/internal/vault"
 )
 
 // SetBookmarkCommandName identifies the slash command for selecting the bookmark reaction emoji and mode.
@@ -16,12 +19,14 @@ const SetBookmarkCommandName = "set-bookmark"
 
 // SetBookmarkCommand handles the `/set-bookmark` slash command lifecycle.
 type SetBookmarkCommand struct {
-	store *store.EmojiStore
+	store  *store.EmojiStore
+	vault  *vault.Vault
+	mailer *email.Sender
 }
 
 // NewSetBookmarkCommand constructs a new SetBookmarkCommand.
-func NewSetBookmarkCommand(store *store.EmojiStore) *SetBookmarkCommand {
-	return &SetBookmarkCommand{store: store}
+func NewSetBookmarkCommand(store *store.EmojiStore, notes *vault.Vault, mailer *email.Sender) *SetBookmarkCommand {
+	return &SetBookmarkCommand{store: store, vault: notes, mailer: mailer}
 }
 
 // Definition returns the discordgo.ApplicationCommand definition for registration.
@@ -50,11 +55,14 @@ func (c *SetBookmarkCommand) Definition() *discordgo.ApplicationCommand {
 			{
 				Type:        discordgo.ApplicationCommandOptionString,
 				Name:        "destination",
-				Description: "Where to send saved bookmarks: dm or channel",
+				Description: "Where to send saved bookmarks: dm, channel, webhook, vault or email",
 				Required:    false,
 				Choices: []*discordgo.ApplicationCommandOptionChoice{
 					{Name: "Direct Message", Value: string(store.DestinationDM)},
 					{Name: "Channel", Value: string(store.DestinationChannel)},
+					{Name: "Webhook", Value: string(store.DestinationWebhook)},
+					{Name: "Markdown vault", Value: string(store.DestinationVault)},
+					{Name: "Email", Value: string(store.DestinationEmail)},
 				},
 			},
 			{
@@ -62,7 +70,42 @@ func (c *SetBookmarkCommand) Definition() *discordgo.ApplicationCommand {
 				Name:         "destination-channel",
 				Description:  "Channel to send bookmarks to when destination is channel",
 				Required:     false,
-				ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText, discordgo.ChannelTypeGuildNews, discordgo.ChannelTypeGuildPublicThread, discordgo.ChannelTypeGuildPrivateThread, discordgo.ChannelTypeGuildNewsThread},
+				ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText, discordgo.ChannelTypeGuildNews, discordgo.ChannelTypeGuildPublicThread, discordgo.ChannelTypeGuildPrivateThread, discordgo.ChannelTypeGuildNewsThread, discordgo.ChannelTypeGuildForum},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "capture",
+				Description: "What to save: the message or the whole thread it started",
+				Required:    false,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "Message", Value: string(store.CaptureMessage)},
+					{Name: "Whole thread / forum post", Value: string(store.CaptureThread)},
+					{Name: "Range start marker (pair with range-end)", Value: string(store.CaptureRange)},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "range-end",
+				Description: "End marker emoji that closes a range capture (e.g. ⏹️)",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Name:        "archive-attachments",
+				Description: "Upload copies of attachments to the bookmark so they survive the source",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Name:        "track-edits",
+				Description: "Refresh the saved bookmark when the author edits the source message",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Name:        "silent",
+				Description: "Remove your reaction after saving so others can't see what you bookmarked",
+				Required:    false,
 			},
 			{
 				Type:        discordgo.ApplicationCommandOptionString,
@@ -82,6 +125,7 @@ func (c *SetBookmarkCommand) Definition() *discordgo.ApplicationCommand {
 				Description: "Keep reminder when pressing the complete button",
 				Required:    false,
 			},
+			scopeOption("Where the emoji works: everywhere, only in this server, or only in DMs"),
 		},
 	}
 }
@@ -107,6 +151,15 @@ func (c *SetBookmarkCommand) Handle(s *discordgo.Session, i *discordgo.Interacti
 	var rawDestination string
 	var destinationChannelID string
 	var destinationChannelProvided bool
+	var rawCapture string
+	var rawRangeEnd string
+	var archive bool
+	var archiveProvided bool
+	var trackEdits bool
+	var trackEditsProvided bool
+	var silent bool
+	var silentProvided bool
+	var rawScope string
 
 	for _, option := range options {
 		switch option.Name {
@@ -123,6 +176,19 @@ func (c *SetBookmarkCommand) Handle(s *discordgo.Session, i *discordgo.Interacti
 			}
 			destinationChannelID = channel.ID
 			destinationChannelProvided = true
+		case "capture":
+			rawCapture = strings.TrimSpace(option.StringValue())
+		case "range-end":
+			rawRangeEnd = strings.TrimSpace(option.StringValue())
+		case "archive-attachments":
+			archive = option.BoolValue()
+			archiveProvided = true
+		case "track-edits":
+			trackEdits = option.BoolValue()
+			trackEditsProvided = true
+		case "silent":
+			silent = option.BoolValue()
+			silentProvided = true
 		case "color":
 			rawColor = strings.TrimSpace(option.StringValue())
 		case "reminder":
@@ -131,6 +197,8 @@ func (c *SetBookmarkCommand) Handle(s *discordgo.Session, i *discordgo.Interacti
 		case "keep-reminder-on-complete":
 			keepReminder = option.BoolValue()
 			keepProvided = true
+		case "scope":
+			rawScope = option.StringValue()
 		}
 	}
 
@@ -171,7 +239,12 @@ func (c *SetBookmarkCommand) Handle(s *discordgo.Session, i *discordgo.Interacti
 		return fmt.Errorf("unable to resolve user from interaction")
 	}
 
-	existingPref, hasExisting := c.store.GetEmoji(user.ID, normalized)
+	scope, err := parseScope(rawScope, i.GuildID)
+	if err != nil {
+		return err
+	}
+
+	existingPref, hasExisting := c.store.GetEmoji(user.ID, scope, normalized)
 
 	color, hasColor, err := resolveColor(rawColor, existingPref, hasExisting)
 	if err != nil {
@@ -203,14 +276,53 @@ func (c *SetBookmarkCommand) Handle(s *discordgo.Session, i *discordgo.Interacti
 	}
 
 	switch destination {
-	case store.DestinationDM:
+	case store.DestinationDM, store.DestinationWebhook:
+		channelID = ""
+	case store.DestinationVault:
+		if !c.vault.Enabled() {
+			return fmt.Errorf("the Markdown vault is not enabled on this bot")
+		}
+		channelID = ""
+	case store.DestinationEmail:
+		if err := requireVerifiedEmail(c.store, c.mailer, user.ID); err != nil {
+			return err
+		}
 		channelID = ""
 	case store.DestinationChannel:
 		if channelID == "" {
 			return fmt.Errorf("please choose a destination-channel when sending bookmarks to a channel")
 		}
 	default:
-		return fmt.Errorf("invalid destination. choose dm or channel")
+		return fmt.Errorf("invalid destination. choose dm, channel, webhook, vault or email")
+	}
+
+	capture := existingPref.Capture
+	if rawCapture != "" {
+		capture = store.CaptureMode(strings.ToLower(rawCapture))
+	}
+	if capture == "" {
+		capture = store.CaptureMessage
+	}
+
+	switch capture {
+	case store.CaptureMessage, store.CaptureThread, store.CaptureRange:
+	default:
+		return fmt.Errorf("invalid capture. choose message, thread, or range")
+	}
+
+	rangeEnd, err := c.resolveRangeEnd(user.ID, scope, normalized, capture, rawRangeEnd, existingPref)
+	if err != nil {
+		return err
+	}
+
+	if !archiveProvided {
+		archive = existingPref.Archive
+	}
+	if !trackEditsProvided {
+		trackEdits = existingPref.TrackEdits
+	}
+	if !silentProvided {
+		silent = existingPref.Silent
 	}
 
 	if reminderProvided {
@@ -242,27 +354,63 @@ func (c *SetBookmarkCommand) Handle(s *discordgo.Session, i *discordgo.Interacti
 	}
 
 	prefToSave := store.EmojiPreference{
-		Mode:        mode,
-		Color:       color,
-		HasColor:    hasColor,
-		Destination: destination,
-		ChannelID:   channelID,
+		Mode:          mode,
+		Color:         color,
+		HasColor:      hasColor,
+		Destination:   destination,
+		ChannelID:     channelID,
+		Capture:       capture,
+		RangeEndEmoji: rangeEnd,
+		Archive:       archive,
+		TrackEdits:    trackEdits,
+		Silent:        silent,
+		Targets:       existingPref.Targets,
 	}
 	if reminderPref != nil {
 		copied := *reminderPref
 		prefToSave.Reminder = &copied
 	}
 
-	if err := c.store.SetEmoji(user.ID, normalized, prefToSave); err != nil {
+	if err := c.store.SetEmoji(user.ID, scope, normalized, prefToSave); err != nil {
 		return fmt.Errorf("failed to save emoji preference: %w", err)
 	}
 
 	destinationLabel := "your DMs"
-	if destination == store.DestinationChannel {
+	switch destination {
+	case store.DestinationChannel:
 		destinationLabel = fmt.Sprintf("<#%s>", channelID)
+	case store.DestinationWebhook:
+		destinationLabel = "your webhook"
+	case store.DestinationVault:
+		destinationLabel = "your Markdown vault"
+	case store.DestinationEmail:
+		destinationLabel = "your email"
+	}
+
+	response := fmt.Sprintf("Saved %s in %s mode. React with it to save messages to %s!", formatEmojiForDisplay(normalized), string(mode), destinationLabel)
+	if scope != store.ScopeGlobal {
+		response += fmt.Sprintf(" 📍 It only works %s and takes precedence over your global %s there.", describeScope(scope), formatEmojiForDisplay(normalized))
+	}
+	switch capture {
+	case store.CaptureThread:
+		response += " 🧵 Reacting to a thread starter saves the whole thread as a transcript."
+	case store.CaptureRange:
+		response += fmt.Sprintf(" 📑 React with it on the first message and %s on the last one to save everything in between.", formatEmojiForDisplay(rangeEnd))
+	}
+	if destination == store.DestinationWebhook {
+		if _, ok := c.store.Webhook(user.ID); !ok {
+			response += " 🪝 Set your endpoint with `/bookmark-webhook set`, or ask a server admin to configure one."
+		}
+	}
+	if archive {
+		response += " 🗄️ Attachments are archived onto the bookmark when they fit."
+	}
+	if trackEdits {
+		response += " ✏️ Saved copies refresh when the source message is edited."
+	}
+	if silent {
+		response += " 🤫 Your reaction is removed after saving (needs Manage Messages for the bot)."
 	}
-
-	response := fmt.Sprintf("Saved %s in %s mode. React with it to save messages to %s!", emojiTokens[0], string(mode), destinationLabel)
 	if hasColor {
 		response += fmt.Sprintf(" Embed color set to #%s.", strings.ToUpper(fmt.Sprintf("%06x", color)))
 	}
@@ -291,6 +439,49 @@ func (c *SetBookmarkCommand) Handle(s *discordgo.Session, i *discordgo.Interacti
 	})
 }
 
+// resolveRangeEnd validates the end marker for range captures and makes sure neither marker
+// shadows another emoji configured where scope applies.
+func (c *SetBookmarkCommand) resolveRangeEnd(userID string, scope store.EmojiScope, emoji string, capture store.CaptureMode, rawRangeEnd string, existing store.EmojiPreference) (string, error) {
+	stored, _ := c.store.Get(userID)
+	prefs := scopeView(stored, scope)
+	for key, pref := range prefs {
+		if key != emoji && pref.Capture == store.CaptureRange && pref.RangeEndEmoji == emoji {
+			return "", fmt.Errorf("%s is already the range-end marker for %s", formatEmojiForDisplay(emoji), formatEmojiForDisplay(key))
+		}
+	}
+
+	if capture != store.CaptureRange {
+		if rawRangeEnd != "" {
+			return "", fmt.Errorf("range-end can only be used with capture:range")
+		}
+		return "", nil
+	}
+
+	rangeEnd := existing.RangeEndEmoji
+	if rawRangeEnd != "" {
+		tokens := splitEmojiInput(rawRangeEnd)
+		if len(tokens) != 1 {
+			return "", fmt.Errorf("please provide exactly one range-end emoji")
+		}
+		rangeEnd = normalizeEmoji(tokens[0])
+		if rangeEnd == "" {
+			return "", fmt.Errorf("unable to understand the provided range-end emoji")
+		}
+	}
+
+	if rangeEnd == "" {
+		return "", fmt.Errorf("please choose a range-end emoji when using capture:range")
+	}
+	if rangeEnd == emoji {
+		return "", fmt.Errorf("the range-end emoji must differ from the start emoji")
+	}
+	if _, taken := prefs[rangeEnd]; taken {
+		return "", fmt.Errorf("%s already has its own bookmark settings. Remove it first or pick another range-end emoji", formatEmojiForDisplay(rangeEnd))
+	}
+
+	return rangeEnd, nil
+}
+
 func normalizeEmoji(value string) string {
 	trimmed := strings.TrimSpace(value)
 	if trimmed == "" {
@@ -301,18 +492,21 @@ func normalizeEmoji(value string) string {
 		inner := strings.Trim(trimmed[1:len(trimmed)-1], ":")
 		parts := strings.Split(inner, ":")
 
+		// Animated emoji drop their "a" prefix: reactions name them like static ones.
 		switch len(parts) {
 		case 2:
 			return strings.Join(parts, ":")
 		case 3:
-			if parts[0] == "a" {
-				return strings.Join(parts, ":")
-			}
 			return strings.Join(parts[1:], ":")
 		}
 	}
 
-	return trimmed
+	if emojikey.IsShortcode(trimmed) {
+		emoji, _ := emojikey.FromShortcode(trimmed)
+		return emoji
+	}
+
+	return emojikey.Normalize(trimmed)
 }
 
 func splitEmojiInput(raw string) []string {
@@ -331,7 +525,6 @@ func splitEmojiInput(raw string) []string {
 	return result
 }
 
-
 func parseColor(value string) (int, bool, error) {
 	if value == "" {
 		return 0, false, nil
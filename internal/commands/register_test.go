@@ -54,3 +54,20 @@ func TestResolveColorInvalidInput(t *testing.T) {
 		t.Fatalf("expected an error for invalid color input")
 	}
 }
+
+func TestNormalizeEmojiAcceptsShortcodes(t *testing.T) {
+	cases := map[string]string{
+		"❤️":                      "❤",
+		":bookmark:":              "🔖",
+		":thumbsup::skin-tone-2:": "👍🏼",
+		"<:later:123>":            "later:123",
+		"<a:party:456>":           "party:456",
+		":not_an_emoji:":          "",
+	}
+
+	for input, want := range cases {
+		if got := normalizeEmoji(input); got != want {
+			t.Errorf("normalizeEmoji(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
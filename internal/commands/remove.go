@@ -34,6 +34,7 @@ func (c *RemoveBookmarkCommand) Definition() *discordgo.ApplicationCommand {
 				Description: "Emoji to remove from your saved shortcuts",
 				Required:    true,
 			},
+			scopeOption("Which of your settings for the emoji to remove (defaults to everywhere)"),
 		},
 	}
 }
@@ -44,12 +45,16 @@ func (c *RemoveBookmarkCommand) Handle(s *discordgo.Session, i *discordgo.Intera
 		return nil
 	}
 
-	options := i.ApplicationCommandData().Options
-	if len(options) == 0 {
-		return fmt.Errorf("emoji option is required")
+	var rawEmoji, rawScope string
+	for _, option := range i.ApplicationCommandData().Options {
+		switch option.Name {
+		case "emoji":
+			rawEmoji = strings.TrimSpace(option.StringValue())
+		case "scope":
+			rawScope = option.StringValue()
+		}
 	}
 
-	rawEmoji := strings.TrimSpace(options[0].StringValue())
 	if rawEmoji == "" {
 		return fmt.Errorf("emoji option is required")
 	}
@@ -75,16 +80,26 @@ func (c *RemoveBookmarkCommand) Handle(s *discordgo.Session, i *discordgo.Intera
 		return fmt.Errorf("unable to resolve user from interaction")
 	}
 
-	removed, err := c.store.DeleteEmoji(user.ID, normalized)
+	scope, err := parseScope(rawScope, i.GuildID)
+	if err != nil {
+		return err
+	}
+
+	removed, err := c.store.DeleteEmoji(user.ID, scope, normalized)
 	if err != nil {
 		return fmt.Errorf("failed to remove emoji preference: %w", err)
 	}
 
 	var content string
-	if !removed {
+	switch {
+	case !removed && scope == store.ScopeGlobal:
 		content = "⚠️ That emoji isn't saved yet. Use `/set-bookmark` to add it first."
-	} else {
-		content = fmt.Sprintf("🧹 Removed %s from your shortcuts.", formatEmojiForDisplay(emojiTokens[0]))
+	case !removed:
+		content = fmt.Sprintf("⚠️ That emoji has no settings %s. Check `/list-bookmarks` for where it is saved.", describeScope(scope))
+	case scope == store.ScopeGlobal:
+		content = fmt.Sprintf("🧹 Removed %s from your shortcuts.", formatEmojiForDisplay(normalized))
+	default:
+		content = fmt.Sprintf("🧹 Removed your %s settings for %s.", describeScope(scope), formatEmojiForDisplay(normalized))
 	}
 
 	return respondEphemeral(s, i, content)
@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/example/discord-bookmark-manager/internal/store"
+)
+
+// Values of the scope option.
+const (
+	scopeGlobal = "global"
+	scopeServer = "server"
+	scopeDM     = "dm"
+)
+
+// scopeOption is the optional scope argument of the commands that manage emoji preferences.
+func scopeOption(description string) *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        "scope",
+		Description: description,
+		Required:    false,
+		Choices: []*discordgo.ApplicationCommandOptionChoice{
+			{Name: "Everywhere (default)", Value: scopeGlobal},
+			{Name: "This server only", Value: scopeServer},
+			{Name: "Direct messages only", Value: scopeDM},
+		},
+	}
+}
+
+// parseScope resolves the scope option of a command run in guildID, which is empty in DMs.
+func parseScope(raw, guildID string) (store.EmojiScope, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "", scopeGlobal:
+		return store.ScopeGlobal, nil
+	case scopeServer:
+		if guildID == "" {
+			return "", fmt.Errorf("scope:server only works when you run the command in that server")
+		}
+		return store.GuildScope(guildID), nil
+	case scopeDM:
+		return store.ScopeDM, nil
+	}
+
+	return "", fmt.Errorf("invalid scope. choose global, server or dm")
+}
+
+// describeScope names scope in responses, as seen from the server the command runs in.
+func describeScope(scope store.EmojiScope) string {
+	switch {
+	case scope == store.ScopeDM:
+		return "in DMs"
+	case scope.GuildID() != "":
+		return "in this server"
+	}
+
+	return "everywhere"
+}
+
+// scopeView returns the preferences that reactions covered by scope see: the global ones,
+// overridden by those saved for scope.
+func scopeView(prefs store.UserPreferences, scope store.EmojiScope) map[string]store.EmojiPreference {
+	if scope == store.ScopeGlobal {
+		return prefs.Emojis
+	}
+
+	return prefs.Effective(scope.GuildID())
+}
+
+func sortedEmojis(prefs map[string]store.EmojiPreference) []string {
+	emojis := make([]string, 0, len(prefs))
+	for emoji := range prefs {
+		emojis = append(emojis, emoji)
+	}
+	sort.Strings(emojis)
+
+	return emojis
+}
@@ -0,0 +1,29 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/example/discord-bookmark-manager/internal/store"
+)
+
+func TestParseScope(t *testing.T) {
+	cases := []struct {
+		raw, guildID string
+		want         store.EmojiScope
+	}{
+		{"", "guild", store.ScopeGlobal},
+		{"global", "", store.ScopeGlobal},
+		{"server", "guild", store.GuildScope("guild")},
+		{"dm", "guild", store.ScopeDM},
+	}
+	for _, tc := range cases {
+		got, err := parseScope(tc.raw, tc.guildID)
+		if err != nil || got != tc.want {
+			t.Fatalf("parseScope(%q, %q) = %q, %v; want %q", tc.raw, tc.guildID, got, err, tc.want)
+		}
+	}
+
+	if _, err := parseScope("server", ""); err == nil {
+		t.Fatalf("expected scope:server to be rejected outside servers")
+	}
+}
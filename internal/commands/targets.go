@@ -0,0 +1,284 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/example/discord-bookmark-manager/internal/email"
+	"github.com/example/discord-bookmark-manager/internal/store"
+	"github.com/example/discord-bookmark-manager/internal/vault"
+)
+
+// BookmarkTargetCommandName identifies the slash command that manages extra destinations.
+const BookmarkTargetCommandName = "bookmark-target"
+
+// maxExtraTargets caps how many destinations one emoji fans out to besides the primary one.
+const maxExtraTargets = 4
+
+// BookmarkTargetCommand handles the `/bookmark-target` slash command lifecycle.
+type BookmarkTargetCommand struct {
+	store  *store.EmojiStore
+	vault  *vault.Vault
+	mailer *email.Sender
+}
+
+// NewBookmarkTargetCommand constructs a new BookmarkTargetCommand.
+func NewBookmarkTargetCommand(store *store.EmojiStore, notes *vault.Vault, mailer *email.Sender) *BookmarkTargetCommand {
+	return &BookmarkTargetCommand{store: store, vault: notes, mailer: mailer}
+}
+
+// Definition returns the discordgo.ApplicationCommand definition for registration.
+func (c *BookmarkTargetCommand) Definition() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        BookmarkTargetCommandName,
+		Description: "Send one bookmark emoji to several destinations",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "add",
+				Description: "Add another destination for an emoji",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "emoji",
+						Description: "Emoji configured with /set-bookmark",
+						Required:    true,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "destination",
+						Description: "Where this copy of the bookmark goes",
+						Required:    true,
+						Choices: []*discordgo.ApplicationCommandOptionChoice{
+							{Name: "Direct Message", Value: string(store.DestinationDM)},
+							{Name: "Channel", Value: string(store.DestinationChannel)},
+							{Name: "Webhook", Value: string(store.DestinationWebhook)},
+							{Name: "Markdown vault", Value: string(store.DestinationVault)},
+							{Name: "Email", Value: string(store.DestinationEmail)},
+						},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "mode",
+						Description: "Layout for this copy",
+						Required:    true,
+						Choices: []*discordgo.ApplicationCommandOptionChoice{
+							{Name: "Lightweight", Value: string(store.ModeLightweight)},
+							{Name: "Balanced", Value: string(store.ModeBalanced)},
+							{Name: "Complete", Value: string(store.ModeComplete)},
+						},
+					},
+					{
+						Type:         discordgo.ApplicationCommandOptionChannel,
+						Name:         "destination-channel",
+						Description:  "Channel to send this copy to when destination is channel",
+						Required:     false,
+						ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText, discordgo.ChannelTypeGuildNews, discordgo.ChannelTypeGuildPublicThread, discordgo.ChannelTypeGuildPrivateThread, discordgo.ChannelTypeGuildNewsThread, discordgo.ChannelTypeGuildForum},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "color",
+						Description: "Embed color for this copy (hex)",
+						Required:    false,
+					},
+					scopeOption("Which of your settings for the emoji to change (defaults to everywhere)"),
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "remove",
+				Description: "Remove an extra destination from an emoji",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "emoji",
+						Description: "Emoji configured with /set-bookmark",
+						Required:    true,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "target",
+						Description: "Target number as shown by /list-bookmarks (the primary destination is 1)",
+						Required:    true,
+						MinValue:    floatPtr(2),
+					},
+					scopeOption("Which of your settings for the emoji to change (defaults to everywhere)"),
+				},
+			},
+		},
+	}
+}
+
+// Handle executes the command when invoked by a user.
+func (c *BookmarkTargetCommand) Handle(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return nil
+	}
+
+	var user *discordgo.User
+	if i.Member != nil {
+		user = i.Member.User
+	}
+	if user == nil {
+		user = i.User
+	}
+	if user == nil {
+		return fmt.Errorf("unable to resolve user from interaction")
+	}
+
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		return fmt.Errorf("a subcommand is required")
+	}
+	subcommand := options[0]
+
+	var rawEmoji, rawDestination, rawMode, rawColor, rawScope, channelID string
+	var targetNumber int64
+	for _, option := range subcommand.Options {
+		switch option.Name {
+		case "emoji":
+			rawEmoji = strings.TrimSpace(option.StringValue())
+		case "destination":
+			rawDestination = strings.TrimSpace(option.StringValue())
+		case "mode":
+			rawMode = strings.TrimSpace(option.StringValue())
+		case "color":
+			rawColor = strings.TrimSpace(option.StringValue())
+		case "destination-channel":
+			channel := option.ChannelValue(s)
+			if channel == nil {
+				return fmt.Errorf("unable to resolve the selected channel")
+			}
+			channelID = channel.ID
+		case "target":
+			targetNumber = option.IntValue()
+		case "scope":
+			rawScope = option.StringValue()
+		}
+	}
+
+	emojiTokens := splitEmojiInput(rawEmoji)
+	if len(emojiTokens) != 1 {
+		return fmt.Errorf("please provide exactly one emoji")
+	}
+	emoji := normalizeEmoji(emojiTokens[0])
+	if emoji == "" {
+		return fmt.Errorf("unable to understand the provided emoji")
+	}
+
+	scope, err := parseScope(rawScope, i.GuildID)
+	if err != nil {
+		return err
+	}
+
+	pref, ok := c.store.GetEmoji(user.ID, scope, emoji)
+	if !ok && scope != store.ScopeGlobal {
+		return fmt.Errorf("%s is not configured %s yet. Set it up with /set-bookmark scope:%s first", formatEmojiForDisplay(emoji), describeScope(scope), rawScope)
+	}
+	if !ok {
+		return fmt.Errorf("%s is not configured yet. Set it up with /set-bookmark first", formatEmojiForDisplay(emoji))
+	}
+
+	switch subcommand.Name {
+	case "add":
+		target, err := buildTarget(rawDestination, channelID, rawMode, rawColor)
+		if err != nil {
+			return err
+		}
+		if target.Destination == store.DestinationVault && !c.vault.Enabled() {
+			return fmt.Errorf("the Markdown vault is not enabled on this bot")
+		}
+		if target.Destination == store.DestinationEmail {
+			if err := requireVerifiedEmail(c.store, c.mailer, user.ID); err != nil {
+				return err
+			}
+		}
+
+		if len(pref.Targets) >= maxExtraTargets {
+			return fmt.Errorf("an emoji can have at most %d extra destinations", maxExtraTargets)
+		}
+		for _, existing := range pref.AllTargets() {
+			if existing.Destination == target.Destination && existing.ChannelID == target.ChannelID {
+				return fmt.Errorf("%s already delivers to %s", formatEmojiForDisplay(emoji), describeTargetDestination(target))
+			}
+		}
+
+		pref.Targets = append(append([]store.DestinationTarget(nil), pref.Targets...), target)
+		if err := c.store.SetEmoji(user.ID, scope, emoji, pref); err != nil {
+			return fmt.Errorf("failed to save destination: %w", err)
+		}
+
+		return respondEphemeral(s, i, fmt.Sprintf("📣 %s now also saves to %s in %s mode.", formatEmojiForDisplay(emoji), describeTargetDestination(target), target.Mode))
+	case "remove":
+		idx := int(targetNumber) - 2
+		if idx < 0 || idx >= len(pref.Targets) {
+			return fmt.Errorf("%s has no target %d. Check /list-bookmarks for the numbers", formatEmojiForDisplay(emoji), targetNumber)
+		}
+
+		removed := pref.Targets[idx]
+		targets := make([]store.DestinationTarget, 0, len(pref.Targets)-1)
+		targets = append(targets, pref.Targets[:idx]...)
+		pref.Targets = append(targets, pref.Targets[idx+1:]...)
+		if err := c.store.SetEmoji(user.ID, scope, emoji, pref); err != nil {
+			return fmt.Errorf("failed to save destination: %w", err)
+		}
+
+		return respondEphemeral(s, i, fmt.Sprintf("🗑️ %s no longer saves to %s.", formatEmojiForDisplay(emoji), describeTargetDestination(removed)))
+	}
+
+	return fmt.Errorf("unknown subcommand %q", subcommand.Name)
+}
+
+func buildTarget(rawDestination, channelID, rawMode, rawColor string) (store.DestinationTarget, error) {
+	target := store.DestinationTarget{
+		Destination: store.DestinationType(strings.ToLower(rawDestination)),
+		Mode:        store.BookmarkMode(strings.ToLower(rawMode)),
+	}
+
+	switch target.Mode {
+	case store.ModeLightweight, store.ModeBalanced, store.ModeComplete:
+	default:
+		return store.DestinationTarget{}, fmt.Errorf("invalid mode. choose lightweight, balanced, or complete")
+	}
+
+	switch target.Destination {
+	case store.DestinationDM, store.DestinationWebhook, store.DestinationVault, store.DestinationEmail:
+	case store.DestinationChannel:
+		if channelID == "" {
+			return store.DestinationTarget{}, fmt.Errorf("please choose a destination-channel when sending bookmarks to a channel")
+		}
+		target.ChannelID = channelID
+	default:
+		return store.DestinationTarget{}, fmt.Errorf("invalid destination. choose dm, channel, webhook, vault or email")
+	}
+
+	color, hasColor, err := parseColor(rawColor)
+	if err != nil {
+		return store.DestinationTarget{}, err
+	}
+	target.Color = color
+	target.HasColor = hasColor
+
+	return target, nil
+}
+
+func describeTargetDestination(target store.DestinationTarget) string {
+	switch {
+	case target.Destination == store.DestinationChannel && target.ChannelID != "":
+		return fmt.Sprintf("<#%s>", target.ChannelID)
+	case target.Destination == store.DestinationWebhook:
+		return "your webhook"
+	case target.Destination == store.DestinationVault:
+		return "your Markdown vault"
+	case target.Destination == store.DestinationEmail:
+		return "your email"
+	}
+
+	return "DMs"
+}
+
+func floatPtr(value float64) *float64 {
+	return &value
+}
@@ -0,0 +1,38 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/example/discord-bookmark-manager/internal/store"
+)
+
+func TestBuildTargetRequiresChannelForChannelDestination(t *testing.T) {
+	if _, err := buildTarget("channel", "", "balanced", ""); err == nil {
+		t.Fatalf("expected an error without destination-channel")
+	}
+}
+
+func TestBuildTargetParsesColorAndMode(t *testing.T) {
+	target, err := buildTarget("Channel", "123", "Complete", "#ff0000")
+	if err != nil {
+		t.Fatalf("buildTarget returned error: %v", err)
+	}
+
+	if target.Destination != store.DestinationChannel || target.ChannelID != "123" || target.Mode != store.ModeComplete {
+		t.Fatalf("unexpected target %+v", target)
+	}
+	if !target.HasColor || target.Color != 0xFF0000 {
+		t.Fatalf("expected color to be parsed, got %+v", target)
+	}
+}
+
+func TestBuildTargetDropsChannelForDM(t *testing.T) {
+	target, err := buildTarget("dm", "123", "lightweight", "")
+	if err != nil {
+		t.Fatalf("buildTarget returned error: %v", err)
+	}
+
+	if target.ChannelID != "" || target.HasColor {
+		t.Fatalf("expected a plain DM target, got %+v", target)
+	}
+}
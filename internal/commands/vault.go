@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/example/discord-bookmark-manager/internal/store"
+	"github.com/example/discord-bookmark-manager/internal/vault"
+)
+
+// BookmarkVaultCommandName identifies the slash command that configures vault note names.
+const BookmarkVaultCommandName = "bookmark-vault"
+
+// maxVaultTemplateLength keeps templates well under file name limits.
+const maxVaultTemplateLength = 200
+
+// BookmarkVaultCommand handles the `/bookmark-vault` slash command lifecycle.
+type BookmarkVaultCommand struct {
+	store *store.EmojiStore
+	vault *vault.Vault
+}
+
+// NewBookmarkVaultCommand constructs a new BookmarkVaultCommand.
+func NewBookmarkVaultCommand(store *store.EmojiStore, notes *vault.Vault) *BookmarkVaultCommand {
+	return &BookmarkVaultCommand{store: store, vault: notes}
+}
+
+// Definition returns the discordgo.ApplicationCommand definition for registration.
+func (c *BookmarkVaultCommand) Definition() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        BookmarkVaultCommandName,
+		Description: "Choose how your Markdown vault notes are named",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "template",
+				Description: "e.g. {date} {title} or {channel}/{date}-{id}. Omit to restore the default",
+				Required:    false,
+			},
+		},
+	}
+}
+
+// Handle executes the command when invoked by a user.
+func (c *BookmarkVaultCommand) Handle(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return nil
+	}
+
+	if !c.vault.Enabled() {
+		return fmt.Errorf("the Markdown vault is not enabled on this bot")
+	}
+
+	var user *discordgo.User
+	if i.Member != nil {
+		user = i.Member.User
+	}
+	if user == nil {
+		user = i.User
+	}
+	if user == nil {
+		return fmt.Errorf("unable to resolve user from interaction")
+	}
+
+	var template string
+	for _, option := range i.ApplicationCommandData().Options {
+		if option.Name == "template" {
+			template = strings.TrimSpace(option.StringValue())
+		}
+	}
+
+	if len(template) > maxVaultTemplateLength {
+		return fmt.Errorf("templates can be at most %d characters", maxVaultTemplateLength)
+	}
+
+	if err := c.store.SetVaultTemplate(user.ID, template); err != nil {
+		return fmt.Errorf("failed to save vault template: %w", err)
+	}
+
+	example := c.vault.Name(template, vault.Note{
+		ID:      "3f9a1c2b7d4e5a60",
+		Title:   "Release plan for v2",
+		Channel: "general",
+		Author:  user.Username,
+		Emoji:   "🔖",
+		SavedAt: time.Now(),
+	})
+
+	response := fmt.Sprintf("🗃️ Vault notes are now named like `%s.md`.", example)
+	if template == "" {
+		response = fmt.Sprintf("🗃️ Vault notes use the default name again, like `%s.md`.", example)
+	}
+
+	return respondEphemeral(s, i, response)
+}
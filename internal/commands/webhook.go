@@ -0,0 +1,177 @@
+package commands
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/example/discord-bookmark-manager/internal/store"
+	"github.com/example/discord-bookmark-manager/internal/webhook"
+)
+
+// BookmarkWebhookCommandName identifies the slash command that manages a user's webhook.
+const BookmarkWebhookCommandName = "bookmark-webhook"
+
+// BookmarkWebhookCommand handles the `/bookmark-webhook` slash command lifecycle.
+type BookmarkWebhookCommand struct {
+	store    *store.EmojiStore
+	webhooks *webhook.Client
+}
+
+// NewBookmarkWebhookCommand constructs a new BookmarkWebhookCommand. URLs are vetted against
+// the addresses webhooks may reach.
+func NewBookmarkWebhookCommand(store *store.EmojiStore, webhooks *webhook.Client) *BookmarkWebhookCommand {
+	return &BookmarkWebhookCommand{store: store, webhooks: webhooks}
+}
+
+// Definition returns the discordgo.ApplicationCommand definition for registration.
+func (c *BookmarkWebhookCommand) Definition() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        BookmarkWebhookCommandName,
+		Description: "Send webhook bookmarks to your own HTTP endpoint",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "set",
+				Description: "Set your endpoint and get a new signing secret",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "url",
+						Description: "HTTPS URL that receives bookmark documents",
+						Required:    true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "clear",
+				Description: "Remove your endpoint and fall back to the server's webhook",
+			},
+		},
+	}
+}
+
+// Handle executes the command when invoked by a user.
+func (c *BookmarkWebhookCommand) Handle(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return nil
+	}
+
+	var user *discordgo.User
+	if i.Member != nil {
+		user = i.Member.User
+	}
+	if user == nil {
+		user = i.User
+	}
+	if user == nil {
+		return fmt.Errorf("unable to resolve user from interaction")
+	}
+
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		return fmt.Errorf("a subcommand is required")
+	}
+	subcommand := options[0]
+
+	switch subcommand.Name {
+	case "set":
+		var rawURL string
+		for _, option := range subcommand.Options {
+			if option.Name == "url" {
+				rawURL = strings.TrimSpace(option.StringValue())
+			}
+		}
+
+		endpoint, err := newWebhookEndpoint(rawURL, c.webhooks)
+		if err != nil {
+			return err
+		}
+		if err := c.store.SetWebhook(user.ID, &endpoint); err != nil {
+			return fmt.Errorf("failed to save webhook: %w", err)
+		}
+
+		return respondEphemeral(s, i, describeNewWebhook("Webhook bookmarks now go to", endpoint))
+	case "clear":
+		if err := c.store.SetWebhook(user.ID, nil); err != nil {
+			return fmt.Errorf("failed to save webhook: %w", err)
+		}
+
+		return respondEphemeral(s, i, "🪝 Your webhook was removed. Webhook bookmarks go to the server's webhook, if it has one.")
+	}
+
+	return fmt.Errorf("unknown subcommand %q", subcommand.Name)
+}
+
+// newWebhookEndpoint validates rawURL and pairs it with a fresh signing secret.
+func newWebhookEndpoint(rawURL string, webhooks *webhook.Client) (store.WebhookEndpoint, error) {
+	normalized, err := validateWebhookURL(rawURL, webhooks)
+	if err != nil {
+		return store.WebhookEndpoint{}, err
+	}
+
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		return store.WebhookEndpoint{}, fmt.Errorf("failed to generate a signing secret: %w", err)
+	}
+
+	return store.WebhookEndpoint{URL: normalized, Secret: secret}, nil
+}
+
+// validateWebhookURL accepts absolute HTTPS URLs on hosts webhooks may reach. Plain HTTP is
+// only allowed for loopback hosts, when the operator allows those, so bookmark content never
+// crosses the network unencrypted.
+func validateWebhookURL(rawURL string, webhooks *webhook.Client) (string, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return "", fmt.Errorf("please provide a full URL such as https://example.com/bookmarks")
+	}
+
+	switch strings.ToLower(parsed.Scheme) {
+	case "https":
+	case "http":
+		if !isLoopbackHost(parsed.Hostname()) {
+			return "", fmt.Errorf("webhook URLs must use https")
+		}
+	default:
+		return "", fmt.Errorf("webhook URLs must use https")
+	}
+
+	if parsed.User != nil {
+		return "", fmt.Errorf("webhook URLs can't contain credentials; use the signing secret instead")
+	}
+
+	if err := webhooks.CheckHost(parsed.Hostname()); err != nil {
+		return "", fmt.Errorf("webhook URLs can't point at local, private or link-local addresses")
+	}
+
+	return parsed.String(), nil
+}
+
+func isLoopbackHost(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// describeWebhookURL shows only the scheme and host, since paths often embed tokens.
+func describeWebhookURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return "configured"
+	}
+
+	return fmt.Sprintf("%s://%s/…", parsed.Scheme, parsed.Host)
+}
+
+func describeNewWebhook(prefix string, endpoint store.WebhookEndpoint) string {
+	return fmt.Sprintf("🪝 %s %s\n🔑 Signing secret (shown once): `%s`\nEach request carries `%s: sha256=<hex>`, an HMAC-SHA256 of `<%s>.<body>` with this secret.",
+		prefix, describeWebhookURL(endpoint.URL), endpoint.Secret, webhook.SignatureHeader, webhook.TimestampHeader)
+}
@@ -2,7 +2,9 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"os"
+	"strconv"
 )
 
 // Config holds runtime configuration values loaded from environment variables.
@@ -12,6 +14,34 @@ type Config struct {
 	GuildID           string
 	StorePath         string
 	ReminderStorePath string
+	BookmarkIndexPath string
+	GuildStorePath    string
+	OutboxStorePath   string
+	// StarboardStorePath persists the showcase posts of every server's starboard.
+	StarboardStorePath string
+	// VaultRoot is the directory vault bookmarks are written to. Empty disables the vault
+	// destination.
+	VaultRoot string
+	// VaultNameTemplate names vault notes for users without their own template.
+	VaultNameTemplate string
+	// SMTPHost is the relay used for email bookmarks and reminders. Empty disables email.
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+	// SMTPStartTLS requires the relay to offer STARTTLS.
+	SMTPStartTLS bool
+	// WebhookAllowLoopback lets webhooks reach endpoints on this machine, for local development.
+	WebhookAllowLoopback bool
+	// FeedAddr is the address the feed server listens on, such as ":8080". Empty disables
+	// bookmark feeds.
+	FeedAddr string
+	// FeedBaseURL is the public URL the feed server is reachable at.
+	FeedBaseURL string
+	// FoldSkinTones makes an emoji configured without a skin tone, or in another one, match
+	// reactions in any tone.
+	FoldSkinTones bool
 }
 
 // Load reads configuration from environment variables and validates that the required
@@ -39,11 +69,101 @@ func Load() (*Config, error) {
 		reminderStorePath = "reminders.json"
 	}
 
+	bookmarkIndexPath := os.Getenv("BOOKMARK_INDEX_PATH")
+	if bookmarkIndexPath == "" {
+		bookmarkIndexPath = "saved-bookmarks.json"
+	}
+
+	guildStorePath := os.Getenv("GUILD_STORE_PATH")
+	if guildStorePath == "" {
+		guildStorePath = "guilds.json"
+	}
+
+	outboxStorePath := os.Getenv("OUTBOX_STORE_PATH")
+	if outboxStorePath == "" {
+		outboxStorePath = "outbox.json"
+	}
+
+	starboardStorePath := os.Getenv("STARBOARD_STORE_PATH")
+	if starboardStorePath == "" {
+		starboardStorePath = "starboard.json"
+	}
+
+	vaultRoot := os.Getenv("VAULT_ROOT")
+	vaultNameTemplate := os.Getenv("VAULT_NAME_TEMPLATE")
+
+	smtpHost := os.Getenv("SMTP_HOST")
+	smtpFrom := os.Getenv("SMTP_FROM")
+	if smtpHost != "" && smtpFrom == "" {
+		return nil, fmt.Errorf("SMTP_FROM is required when SMTP_HOST is set")
+	}
+
+	smtpPort := 587
+	if raw := os.Getenv("SMTP_PORT"); raw != "" {
+		port, err := strconv.Atoi(raw)
+		if err != nil || port <= 0 || port > 65535 {
+			return nil, fmt.Errorf("SMTP_PORT must be a valid port number")
+		}
+		smtpPort = port
+	}
+
+	smtpStartTLS := true
+	if raw := os.Getenv("SMTP_STARTTLS"); raw != "" {
+		enabled, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("SMTP_STARTTLS must be true or false")
+		}
+		smtpStartTLS = enabled
+	}
+
+	foldSkinTones := true
+	if raw := os.Getenv("FOLD_SKIN_TONES"); raw != "" {
+		enabled, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("FOLD_SKIN_TONES must be true or false")
+		}
+		foldSkinTones = enabled
+	}
+
+	webhookAllowLoopback := false
+	if raw := os.Getenv("WEBHOOK_ALLOW_LOOPBACK"); raw != "" {
+		enabled, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("WEBHOOK_ALLOW_LOOPBACK must be true or false")
+		}
+		webhookAllowLoopback = enabled
+	}
+
+	feedAddr := os.Getenv("FEED_ADDR")
+	feedBaseURL := os.Getenv("FEED_BASE_URL")
+	if feedAddr != "" && feedBaseURL == "" {
+		feedBaseURL = "http://localhost" + feedAddr
+		if host, port, err := net.SplitHostPort(feedAddr); err == nil && host != "" {
+			feedBaseURL = "http://" + net.JoinHostPort(host, port)
+		}
+	}
+
 	return &Config{
-		BotToken:          token,
-		AppID:             appID,
-		GuildID:           guildID,
-		StorePath:         storePath,
-		ReminderStorePath: reminderStorePath,
+		BotToken:             token,
+		AppID:                appID,
+		GuildID:              guildID,
+		StorePath:            storePath,
+		ReminderStorePath:    reminderStorePath,
+		BookmarkIndexPath:    bookmarkIndexPath,
+		GuildStorePath:       guildStorePath,
+		OutboxStorePath:      outboxStorePath,
+		StarboardStorePath:   starboardStorePath,
+		VaultRoot:            vaultRoot,
+		VaultNameTemplate:    vaultNameTemplate,
+		SMTPHost:             smtpHost,
+		SMTPPort:             smtpPort,
+		SMTPUsername:         os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:         os.Getenv("SMTP_PASSWORD"),
+		SMTPFrom:             smtpFrom,
+		SMTPStartTLS:         smtpStartTLS,
+		WebhookAllowLoopback: webhookAllowLoopback,
+		FeedAddr:             feedAddr,
+		FeedBaseURL:          feedBaseURL,
+		FoldSkinTones:        foldSkinTones,
 	}, nil
 }
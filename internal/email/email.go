@@ -0,0 +1,274 @@
+// Package email sends multipart text/HTML messages through an SMTP relay.
+package email
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultTimeout     = 30 * time.Second
+	defaultMaxAttempts = 3
+	defaultBackoff     = 5 * time.Second
+)
+
+// Config describes the SMTP relay.
+type Config struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	// From is the sender address, optionally with a display name.
+	From string
+	// StartTLS requires the relay to offer STARTTLS before anything else is sent. Turn it off
+	// only for local relays on a trusted network.
+	StartTLS bool
+}
+
+// Message is one email with plain-text and HTML alternatives.
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Sender delivers messages to the configured relay, retrying temporary failures.
+type Sender struct {
+	config      Config
+	tlsConfig   *tls.Config
+	timeout     time.Duration
+	maxAttempts int
+	backoff     time.Duration
+	now         func() time.Time
+}
+
+// NewSender constructs a Sender for config. A config without a host yields a disabled sender.
+func NewSender(config Config) *Sender {
+	if config.Port == 0 {
+		config.Port = 587
+	}
+
+	return &Sender{
+		config:      config,
+		tlsConfig:   &tls.Config{ServerName: config.Host, MinVersion: tls.VersionTLS12},
+		timeout:     defaultTimeout,
+		maxAttempts: defaultMaxAttempts,
+		backoff:     defaultBackoff,
+		now:         time.Now,
+	}
+}
+
+// Enabled reports whether a relay is configured.
+func (s *Sender) Enabled() bool {
+	return s != nil && s.config.Host != ""
+}
+
+// ValidateAddress returns the bare address of raw, rejecting anything that is not a single
+// mailbox.
+func ValidateAddress(raw string) (string, error) {
+	parsed, err := mail.ParseAddress(strings.TrimSpace(raw))
+	if err != nil || parsed.Name != "" {
+		return "", fmt.Errorf("%q is not a valid email address", raw)
+	}
+
+	return parsed.Address, nil
+}
+
+// Send delivers msg. Connection errors and 4xx replies are retried with exponential backoff;
+// 5xx replies fail immediately.
+func (s *Sender) Send(msg Message) error {
+	from, to, data, err := s.prepare(msg)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= s.maxAttempts; attempt++ {
+		lastErr = s.deliver(from, to, data)
+		if lastErr == nil || !temporary(lastErr) {
+			return lastErr
+		}
+		if attempt < s.maxAttempts {
+			time.Sleep(s.backoff << (attempt - 1))
+		}
+	}
+
+	return lastErr
+}
+
+// Attempt delivers msg once, for callers that schedule retries themselves. It reports whether
+// the failure is worth retrying.
+func (s *Sender) Attempt(msg Message) (bool, error) {
+	from, to, data, err := s.prepare(msg)
+	if err != nil {
+		return false, err
+	}
+
+	err = s.deliver(from, to, data)
+	return err != nil && temporary(err), err
+}
+
+// prepare validates the addresses of msg and renders it.
+func (s *Sender) prepare(msg Message) (from, to string, data []byte, err error) {
+	if !s.Enabled() {
+		return "", "", nil, errors.New("email is not configured")
+	}
+
+	to, err = ValidateAddress(msg.To)
+	if err != nil {
+		return "", "", nil, err
+	}
+	sender, err := mail.ParseAddress(s.config.From)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("invalid sender address: %w", err)
+	}
+
+	data, err = Build(s.config.From, msg, s.now())
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	return sender.Address, to, data, nil
+}
+
+func (s *Sender) deliver(from, to string, data []byte) error {
+	addr := net.JoinHostPort(s.config.Host, strconv.Itoa(s.config.Port))
+	conn, err := net.DialTimeout("tcp", addr, s.timeout)
+	if err != nil {
+		return fmt.Errorf("connect to %s: %w", addr, err)
+	}
+	conn.SetDeadline(time.Now().Add(s.timeout))
+
+	client, err := smtp.NewClient(conn, s.config.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("smtp greeting: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(s.tlsConfig); err != nil {
+			return fmt.Errorf("starttls: %w", err)
+		}
+	} else if s.config.StartTLS {
+		return errors.New("smtp relay does not offer STARTTLS")
+	}
+
+	if s.config.Username != "" {
+		auth := smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Host)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("smtp auth: %w", err)
+		}
+	}
+
+	if err := client.Mail(from); err != nil {
+		return fmt.Errorf("smtp MAIL FROM: %w", err)
+	}
+	if err := client.Rcpt(to); err != nil {
+		return fmt.Errorf("smtp RCPT TO: %w", err)
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp DATA: %w", err)
+	}
+	if _, err := writer.Write(data); err != nil {
+		writer.Close()
+		return fmt.Errorf("smtp DATA: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("smtp DATA: %w", err)
+	}
+
+	return client.Quit()
+}
+
+// temporary reports whether err is worth retrying: anything but a permanent 5xx reply.
+func temporary(err error) bool {
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		return protoErr.Code >= 400 && protoErr.Code < 500
+	}
+
+	return true
+}
+
+// Build renders msg as a multipart/alternative RFC 5322 message.
+func Build(from string, msg Message, now time.Time) ([]byte, error) {
+	boundary, err := randomToken(12)
+	if err != nil {
+		return nil, err
+	}
+	messageID, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+
+	domain := "localhost"
+	if parsed, err := mail.ParseAddress(from); err == nil {
+		if at := strings.LastIndex(parsed.Address, "@"); at >= 0 {
+			domain = parsed.Address[at+1:]
+		}
+	}
+
+	var buf bytes.Buffer
+	writeHeader(&buf, "From", from)
+	writeHeader(&buf, "To", msg.To)
+	writeHeader(&buf, "Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	writeHeader(&buf, "Date", now.Format(time.RFC1123Z))
+	writeHeader(&buf, "Message-ID", fmt.Sprintf("<%s@%s>", messageID, domain))
+	writeHeader(&buf, "MIME-Version", "1.0")
+	writeHeader(&buf, "Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", boundary))
+	buf.WriteString("\r\n")
+
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	} {
+		buf.WriteString("--" + boundary + "\r\n")
+		writeHeader(&buf, "Content-Type", part.contentType)
+		writeHeader(&buf, "Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+
+		writer := quotedprintable.NewWriter(&buf)
+		if _, err := writer.Write([]byte(strings.ReplaceAll(part.body, "\n", "\r\n"))); err != nil {
+			return nil, err
+		}
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+		buf.WriteString("\r\n")
+	}
+	buf.WriteString("--" + boundary + "--\r\n")
+
+	return buf.Bytes(), nil
+}
+
+func writeHeader(buf *bytes.Buffer, name, value string) {
+	// Header values never span lines; drop anything that could inject another header.
+	value = strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+	buf.WriteString(name + ": " + value + "\r\n")
+}
+
+func randomToken(size int) (string, error) {
+	raw := make([]byte, size)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(raw), nil
+}
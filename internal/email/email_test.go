@@ -0,0 +1,343 @@
+package email
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSMTP is a minimal SMTP stand-in that records the envelope and data of each message.
+type fakeSMTP struct {
+	listener net.Listener
+	tls      *tls.Config
+	// failRcpt makes RCPT TO answer with this code while it is positive.
+	failRcpt int
+
+	mu       sync.Mutex
+	messages []receivedMessage
+	attempts int
+}
+
+type receivedMessage struct {
+	from, to string
+	data     string
+	tls      bool
+}
+
+func newFakeSMTP(t *testing.T, tlsConfig *tls.Config) *fakeSMTP {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	server := &fakeSMTP{listener: listener, tls: tlsConfig}
+	go server.serve()
+	t.Cleanup(func() { listener.Close() })
+
+	return server
+}
+
+func (f *fakeSMTP) port() int {
+	return f.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (f *fakeSMTP) serve() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		go f.handle(conn)
+	}
+}
+
+func (f *fakeSMTP) handle(conn net.Conn) {
+	defer conn.Close()
+
+	f.mu.Lock()
+	f.attempts++
+	f.mu.Unlock()
+
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+	reply("220 fake ESMTP")
+
+	var current receivedMessage
+	secure := false
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(strings.TrimSpace(line))
+
+		switch {
+		case strings.HasPrefix(command, "EHLO"):
+			if f.tls != nil && !secure {
+				reply("250-fake")
+				reply("250 STARTTLS")
+			} else {
+				reply("250 fake")
+			}
+		case command == "STARTTLS":
+			reply("220 ready")
+			tlsConn := tls.Server(conn, f.tls)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+			reader = bufio.NewReader(conn)
+			secure = true
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			current = receivedMessage{from: strings.Trim(strings.TrimSpace(line)[10:], "<>"), tls: secure}
+			reply("250 ok")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			f.mu.Lock()
+			code := f.failRcpt
+			if code > 0 && code < 500 {
+				f.failRcpt = 0
+			}
+			f.mu.Unlock()
+			if code > 0 {
+				reply(strconv.Itoa(code) + " try later")
+				continue
+			}
+			current.to = strings.Trim(strings.TrimSpace(line)[8:], "<>")
+			reply("250 ok")
+		case command == "DATA":
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				dataLine, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(dataLine)
+			}
+			current.data = data.String()
+			f.mu.Lock()
+			f.messages = append(f.messages, current)
+			f.mu.Unlock()
+			reply("250 queued")
+		case command == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
+
+func (f *fakeSMTP) received() []receivedMessage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]receivedMessage(nil), f.messages...)
+}
+
+func (f *fakeSMTP) attemptCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.attempts
+}
+
+func selfSignedTLS(t *testing.T) (*tls.Config, *x509.CertPool) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parse certificate: %v", err)
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+
+	return &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}, pool
+}
+
+func testSender(port int, startTLS bool) *Sender {
+	sender := NewSender(Config{Host: "127.0.0.1", Port: port, From: "Bookmarks <bot@example.com>", StartTLS: startTLS})
+	sender.backoff = time.Millisecond
+	sender.timeout = 5 * time.Second
+	return sender
+}
+
+func sampleMessage() Message {
+	return Message{
+		To:      "reader@example.com",
+		Subject: "🔖 Bookmark from #général",
+		Text:    "Hello = world\nSecond line",
+		HTML:    "<p>Hello = world</p>",
+	}
+}
+
+func TestSendUsesStartTLS(t *testing.T) {
+	serverTLS, pool := selfSignedTLS(t)
+	server := newFakeSMTP(t, serverTLS)
+
+	sender := testSender(server.port(), true)
+	sender.tlsConfig = &tls.Config{ServerName: "127.0.0.1", RootCAs: pool}
+
+	if err := sender.Send(sampleMessage()); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+
+	received := server.received()
+	if len(received) != 1 {
+		t.Fatalf("expected one message, got %d", len(received))
+	}
+	if !received[0].tls {
+		t.Fatalf("expected the message to be sent after STARTTLS")
+	}
+	if received[0].from != "bot@example.com" || received[0].to != "reader@example.com" {
+		t.Fatalf("unexpected envelope %q -> %q", received[0].from, received[0].to)
+	}
+}
+
+func TestSendRequiresStartTLS(t *testing.T) {
+	server := newFakeSMTP(t, nil)
+
+	if err := testSender(server.port(), true).Send(sampleMessage()); err == nil {
+		t.Fatalf("expected an error when the relay does not offer STARTTLS")
+	}
+	if len(server.received()) != 0 {
+		t.Fatalf("no message should be sent without STARTTLS")
+	}
+}
+
+func TestSendRetriesTemporaryFailures(t *testing.T) {
+	server := newFakeSMTP(t, nil)
+	server.failRcpt = 451
+
+	if err := testSender(server.port(), false).Send(sampleMessage()); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if len(server.received()) != 1 || server.attemptCount() != 2 {
+		t.Fatalf("expected delivery on the second attempt, got %d messages after %d attempts", len(server.received()), server.attemptCount())
+	}
+}
+
+func TestSendDoesNotRetryPermanentFailures(t *testing.T) {
+	server := newFakeSMTP(t, nil)
+	server.failRcpt = 550
+
+	if err := testSender(server.port(), false).Send(sampleMessage()); err == nil {
+		t.Fatalf("expected a permanent failure")
+	}
+	if server.attemptCount() != 1 {
+		t.Fatalf("expected a single attempt, got %d", server.attemptCount())
+	}
+}
+
+func TestAttemptReportsTemporaryFailures(t *testing.T) {
+	server := newFakeSMTP(t, nil)
+	server.failRcpt = 451
+	sender := testSender(server.port(), false)
+
+	if retryable, err := sender.Attempt(sampleMessage()); err == nil || !retryable {
+		t.Fatalf("expected a retryable failure, got %v, %v", retryable, err)
+	}
+	if server.attemptCount() != 1 {
+		t.Fatalf("expected a single attempt, got %d", server.attemptCount())
+	}
+
+	message := sampleMessage()
+	message.To = "not an address"
+	if retryable, err := sender.Attempt(message); err == nil || retryable {
+		t.Fatalf("expected an invalid address to be permanent, got %v, %v", retryable, err)
+	}
+}
+
+func TestBuildProducesMultipartAlternative(t *testing.T) {
+	data, err := Build("Bookmarks <bot@example.com>", sampleMessage(), time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Build returned error: %v", err)
+	}
+
+	parsed, err := mail.ReadMessage(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatalf("invalid message: %v", err)
+	}
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil || subject != "🔖 Bookmark from #général" {
+		t.Fatalf("unexpected subject %q (%v)", subject, err)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("unexpected content type %q", parsed.Header.Get("Content-Type"))
+	}
+
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	var types, bodies []string
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("read part: %v", err)
+		}
+		body, _ := io.ReadAll(part)
+		types = append(types, part.Header.Get("Content-Type"))
+		bodies = append(bodies, string(body))
+	}
+
+	if len(types) != 2 || !strings.HasPrefix(types[0], "text/plain") || !strings.HasPrefix(types[1], "text/html") {
+		t.Fatalf("unexpected parts %v", types)
+	}
+	if bodies[0] != "Hello = world\r\nSecond line" || bodies[1] != "<p>Hello = world</p>" {
+		t.Fatalf("unexpected bodies %q", bodies)
+	}
+}
+
+func TestValidateAddress(t *testing.T) {
+	if address, err := ValidateAddress(" reader@example.com "); err != nil || address != "reader@example.com" {
+		t.Fatalf("unexpected result %q, %v", address, err)
+	}
+	for _, raw := range []string{"", "not-an-address", "Reader <reader@example.com>", "a@example.com, b@example.com"} {
+		if _, err := ValidateAddress(raw); err == nil {
+			t.Errorf("expected %q to be rejected", raw)
+		}
+	}
+}
@@ -0,0 +1,518 @@
+package emojikey
+
+import "strings"
+
+// emojiPresentationBelow1F000 lists the emoji below U+1F000 that show in color by default;
+// every other emoji there needs the presentation selector. From the Emoji_Presentation
+// property of Unicode's emoji-data.txt.
+var emojiPresentationBelow1F000 = [][2]rune{
+	{0x231A, 0x231B}, {0x23E9, 0x23EC}, {0x23F0, 0x23F0}, {0x23F3, 0x23F3},
+	{0x25FD, 0x25FE}, {0x2614, 0x2615}, {0x2648, 0x2653}, {0x267F, 0x267F},
+	{0x2693, 0x2693}, {0x26A1, 0x26A1}, {0x26AA, 0x26AB}, {0x26BD, 0x26BE},
+	{0x26C4, 0x26C5}, {0x26CE, 0x26CE}, {0x26D4, 0x26D4}, {0x26EA, 0x26EA},
+	{0x26F2, 0x26F3}, {0x26F5, 0x26F5}, {0x26FA, 0x26FA}, {0x26FD, 0x26FD},
+	{0x2705, 0x2705}, {0x270A, 0x270B}, {0x2728, 0x2728}, {0x274C, 0x274C},
+	{0x274E, 0x274E}, {0x2753, 0x2755}, {0x2757, 0x2757}, {0x2795, 0x2797},
+	{0x27B0, 0x27B0}, {0x27BF, 0x27BF}, {0x2B1B, 0x2B1C}, {0x2B50, 0x2B50},
+	{0x2B55, 0x2B55},
+}
+
+// textPresentationAbove1F000 lists the emoji from U+1F000 up that show as plain text by
+// default, from the same data.
+var textPresentationAbove1F000 = [][2]rune{
+	{0x1F170, 0x1F171}, {0x1F17E, 0x1F17F}, {0x1F202, 0x1F202}, {0x1F237, 0x1F237},
+	{0x1F321, 0x1F321}, {0x1F324, 0x1F32C}, {0x1F336, 0x1F336}, {0x1F37D, 0x1F37D},
+	{0x1F396, 0x1F397}, {0x1F399, 0x1F39B}, {0x1F39E, 0x1F39F}, {0x1F3CB, 0x1F3CE},
+	{0x1F3D4, 0x1F3DF}, {0x1F3F3, 0x1F3F3}, {0x1F3F5, 0x1F3F5}, {0x1F3F7, 0x1F3F7},
+	{0x1F43F, 0x1F43F}, {0x1F441, 0x1F441}, {0x1F4FD, 0x1F4FD}, {0x1F549, 0x1F54A},
+	{0x1F56F, 0x1F570}, {0x1F573, 0x1F579}, {0x1F587, 0x1F587}, {0x1F58A, 0x1F58D},
+	{0x1F590, 0x1F590}, {0x1F5A5, 0x1F5A5}, {0x1F5A8, 0x1F5A8}, {0x1F5B1, 0x1F5B2},
+	{0x1F5BC, 0x1F5BC}, {0x1F5C2, 0x1F5C4}, {0x1F5D1, 0x1F5D3}, {0x1F5DC, 0x1F5DE},
+	{0x1F5E1, 0x1F5E1}, {0x1F5E3, 0x1F5E3}, {0x1F5E8, 0x1F5E8}, {0x1F5EF, 0x1F5EF},
+	{0x1F5F3, 0x1F5F3}, {0x1F5FA, 0x1F5FA}, {0x1F6CB, 0x1F6CB}, {0x1F6CD, 0x1F6CF},
+	{0x1F6E0, 0x1F6E5}, {0x1F6E9, 0x1F6E9}, {0x1F6F0, 0x1F6F0}, {0x1F6F3, 0x1F6F3},
+}
+
+// skinTones maps Discord's tone suffixes, as in :thumbsup::skin-tone-3:, to modifiers.
+var skinTones = map[string]string{
+	"skin-tone-1": "\U0001F3FB",
+	"skin-tone-2": "\U0001F3FC",
+	"skin-tone-3": "\U0001F3FD",
+	"skin-tone-4": "\U0001F3FE",
+	"skin-tone-5": "\U0001F3FF",
+}
+
+// shortcodes maps the names Discord and GitHub accept between colons to emoji, without
+// variation selectors.
+var shortcodes = map[string]string{
+	// Bookmarks, reading and work.
+	"bookmark":                 "🔖",
+	"bookmark_tabs":            "📑",
+	"books":                    "📚",
+	"book":                     "📖",
+	"open_book":                "📖",
+	"closed_book":              "📕",
+	"green_book":               "📗",
+	"blue_book":                "📘",
+	"orange_book":              "📙",
+	"notebook":                 "📓",
+	"ledger":                   "📒",
+	"page_facing_up":           "📄",
+	"page_with_curl":           "📃",
+	"scroll":                   "📜",
+	"newspaper":                "📰",
+	"memo":                     "📝",
+	"pencil":                   "📝",
+	"pencil2":                  "✏",
+	"pushpin":                  "📌",
+	"round_pushpin":            "📍",
+	"paperclip":                "📎",
+	"link":                     "🔗",
+	"label":                    "🏷",
+	"card_index":               "📇",
+	"file_folder":              "📁",
+	"open_file_folder":         "📂",
+	"card_box":                 "🗃",
+	"file_cabinet":             "🗄",
+	"wastebasket":              "🗑",
+	"inbox_tray":               "📥",
+	"outbox_tray":              "📤",
+	"package":                  "📦",
+	"envelope":                 "✉",
+	"email":                    "📧",
+	"e-mail":                   "📧",
+	"incoming_envelope":        "📨",
+	"mailbox":                  "📫",
+	"mailbox_with_mail":        "📬",
+	"calendar":                 "📆",
+	"date":                     "📅",
+	"spiral_calendar_pad":      "🗓",
+	"alarm_clock":              "⏰",
+	"hourglass":                "⌛",
+	"stopwatch":                "⏱",
+	"timer":                    "⏲",
+	"clock":                    "🕰",
+	"bell":                     "🔔",
+	"mega":                     "📣",
+	"loudspeaker":              "📢",
+	"mag":                      "🔍",
+	"mag_right":                "🔎",
+	"bulb":                     "💡",
+	"key":                      "🔑",
+	"lock":                     "🔒",
+	"unlock":                   "🔓",
+	"gear":                     "⚙",
+	"hammer_and_wrench":        "🛠",
+	"wrench":                   "🔧",
+	"toolbox":                  "🧰",
+	"computer":                 "💻",
+	"keyboard":                 "⌨",
+	"desktop":                  "🖥",
+	"bar_chart":                "📊",
+	"chart_with_upwards_trend": "📈",
+	"clipboard":                "📋",
+	"triangular_flag_on_post":  "🚩",
+	"checkered_flag":           "🏁",
+	"white_flag":               "🏳",
+	"rainbow_flag":             "🏳‍🌈",
+	"pirate_flag":              "🏴‍☠",
+	"trophy":                   "🏆",
+	"medal":                    "🏅",
+	"military_medal":           "🎖",
+	"dart":                     "🎯",
+	"rocket":                   "🚀",
+	"construction":             "🚧",
+	"warning":                  "⚠",
+	"no_entry":                 "⛔",
+	"rotating_light":           "🚨",
+	"sos":                      "🆘",
+	"recycle":                  "♻",
+	"infinity":                 "♾",
+
+	// Marks and symbols.
+	"white_check_mark":            "✅",
+	"heavy_check_mark":            "✔",
+	"ballot_box_with_check":       "☑",
+	"x":                           "❌",
+	"negative_squared_cross_mark": "❎",
+	"heavy_plus_sign":             "➕",
+	"heavy_minus_sign":            "➖",
+	"question":                    "❓",
+	"grey_question":               "❔",
+	"exclamation":                 "❗",
+	"grey_exclamation":            "❕",
+	"bangbang":                    "‼",
+	"interrobang":                 "⁉",
+	"100":                         "💯",
+	"star":                        "⭐",
+	"star2":                       "🌟",
+	"stars":                       "🌠",
+	"sparkles":                    "✨",
+	"sparkle":                     "❇",
+	"dizzy":                       "💫",
+	"boom":                        "💥",
+	"collision":                   "💥",
+	"zap":                         "⚡",
+	"fire":                        "🔥",
+	"gem":                         "💎",
+	"new":                         "🆕",
+	"free":                        "🆓",
+	"up":                          "🆙",
+	"cool":                        "🆒",
+	"ok":                          "🆗",
+	"information_source":          "ℹ",
+	"copyright":                   "©",
+	"registered":                  "®",
+	"tm":                          "™",
+	"hash":                        "#⃣",
+	"asterisk":                    "*⃣",
+	"zero":                        "0⃣",
+	"one":                         "1⃣",
+	"two":                         "2⃣",
+	"three":                       "3⃣",
+	"four":                        "4⃣",
+	"five":                        "5⃣",
+	"six":                         "6⃣",
+	"seven":                       "7⃣",
+	"eight":                       "8⃣",
+	"nine":                        "9⃣",
+	"keycap_ten":                  "🔟",
+	"arrow_forward":               "▶",
+	"arrow_backward":              "◀",
+	"play_pause":                  "⏯",
+	"pause_button":                "⏸",
+	"stop_button":                 "⏹",
+	"record_button":               "⏺",
+	"fast_forward":                "⏩",
+	"rewind":                      "⏪",
+	"track_next":                  "⏭",
+	"track_previous":              "⏮",
+	"arrow_right":                 "➡",
+	"arrow_left":                  "⬅",
+	"arrow_up":                    "⬆",
+	"arrow_down":                  "⬇",
+	"arrows_counterclockwise":     "🔄",
+	"repeat":                      "🔁",
+	"red_circle":                  "🔴",
+	"orange_circle":               "🟠",
+	"yellow_circle":               "🟡",
+	"green_circle":                "🟢",
+	"blue_circle":                 "🔵",
+	"purple_circle":               "🟣",
+	"white_circle":                "⚪",
+	"black_circle":                "⚫",
+	"red_square":                  "🟥",
+	"green_square":                "🟩",
+	"blue_square":                 "🟦",
+	"yellow_square":               "🟨",
+	"large_blue_diamond":          "🔷",
+	"large_orange_diamond":        "🔶",
+
+	// Hearts.
+	"heart":             "❤",
+	"red_heart":         "❤",
+	"orange_heart":      "🧡",
+	"yellow_heart":      "💛",
+	"green_heart":       "💚",
+	"blue_heart":        "💙",
+	"purple_heart":      "💜",
+	"black_heart":       "🖤",
+	"white_heart":       "🤍",
+	"brown_heart":       "🤎",
+	"broken_heart":      "💔",
+	"heart_exclamation": "❣",
+	"two_hearts":        "💕",
+	"revolving_hearts":  "💞",
+	"heartbeat":         "💓",
+	"heartpulse":        "💗",
+	"sparkling_heart":   "💖",
+	"cupid":             "💘",
+	"gift_heart":        "💝",
+	"heart_on_fire":     "❤‍🔥",
+	"mending_heart":     "❤‍🩹",
+
+	// Hands, which take skin tones.
+	"thumbsup":                         "👍",
+	"+1":                               "👍",
+	"thumbup":                          "👍",
+	"thumbsdown":                       "👎",
+	"-1":                               "👎",
+	"thumbdown":                        "👎",
+	"ok_hand":                          "👌",
+	"pinching_hand":                    "🤏",
+	"v":                                "✌",
+	"crossed_fingers":                  "🤞",
+	"love_you_gesture":                 "🤟",
+	"metal":                            "🤘",
+	"call_me":                          "🤙",
+	"point_left":                       "👈",
+	"point_right":                      "👉",
+	"point_up":                         "☝",
+	"point_up_2":                       "👆",
+	"point_down":                       "👇",
+	"middle_finger":                    "🖕",
+	"raised_hand":                      "✋",
+	"hand":                             "✋",
+	"raised_back_of_hand":              "🤚",
+	"hand_splayed":                     "🖐",
+	"raised_hand_with_fingers_splayed": "🖐",
+	"vulcan":                           "🖖",
+	"wave":                             "👋",
+	"clap":                             "👏",
+	"raised_hands":                     "🙌",
+	"open_hands":                       "👐",
+	"palms_up_together":                "🤲",
+	"handshake":                        "🤝",
+	"pray":                             "🙏",
+	"writing_hand":                     "✍",
+	"muscle":                           "💪",
+	"punch":                            "👊",
+	"fist":                             "✊",
+	"left_facing_fist":                 "🤛",
+	"right_facing_fist":                "🤜",
+	"nail_care":                        "💅",
+	"selfie":                           "🤳",
+
+	// People and faces.
+	"eyes":                      "👀",
+	"eye":                       "👁",
+	"brain":                     "🧠",
+	"speech_balloon":            "💬",
+	"thought_balloon":           "💭",
+	"speaking_head":             "🗣",
+	"bust_in_silhouette":        "👤",
+	"busts_in_silhouette":       "👥",
+	"man_technologist":          "👨‍💻",
+	"woman_technologist":        "👩‍💻",
+	"technologist":              "🧑‍💻",
+	"grinning":                  "😀",
+	"smiley":                    "😃",
+	"smile":                     "😄",
+	"grin":                      "😁",
+	"laughing":                  "😆",
+	"sweat_smile":               "😅",
+	"joy":                       "😂",
+	"rofl":                      "🤣",
+	"slight_smile":              "🙂",
+	"upside_down":               "🙃",
+	"wink":                      "😉",
+	"blush":                     "😊",
+	"innocent":                  "😇",
+	"heart_eyes":                "😍",
+	"star_struck":               "🤩",
+	"kissing_heart":             "😘",
+	"yum":                       "😋",
+	"stuck_out_tongue":          "😛",
+	"zany_face":                 "🤪",
+	"money_mouth":               "🤑",
+	"hugging":                   "🤗",
+	"thinking":                  "🤔",
+	"zipper_mouth":              "🤐",
+	"raised_eyebrow":            "🤨",
+	"neutral_face":              "😐",
+	"expressionless":            "😑",
+	"no_mouth":                  "😶",
+	"smirk":                     "😏",
+	"unamused":                  "😒",
+	"rolling_eyes":              "🙄",
+	"grimacing":                 "😬",
+	"relieved":                  "😌",
+	"pensive":                   "😔",
+	"sleepy":                    "😪",
+	"sleeping":                  "😴",
+	"mask":                      "😷",
+	"nerd":                      "🤓",
+	"sunglasses":                "😎",
+	"monocle_face":              "🧐",
+	"confused":                  "😕",
+	"worried":                   "😟",
+	"slight_frown":              "🙁",
+	"open_mouth":                "😮",
+	"astonished":                "😲",
+	"flushed":                   "😳",
+	"pleading_face":             "🥺",
+	"cry":                       "😢",
+	"sob":                       "😭",
+	"scream":                    "😱",
+	"confounded":                "😖",
+	"weary":                     "😩",
+	"tired_face":                "😫",
+	"yawning_face":              "🥱",
+	"triumph":                   "😤",
+	"rage":                      "😡",
+	"angry":                     "😠",
+	"exploding_head":            "🤯",
+	"partying_face":             "🥳",
+	"saluting_face":             "🫡",
+	"shushing_face":             "🤫",
+	"face_with_hand_over_mouth": "🤭",
+	"skull":                     "💀",
+	"ghost":                     "👻",
+	"alien":                     "👽",
+	"robot":                     "🤖",
+	"poop":                      "💩",
+	"clown":                     "🤡",
+	"see_no_evil":               "🙈",
+	"hear_no_evil":              "🙉",
+	"speak_no_evil":             "🙊",
+
+	// Nature, food and things.
+	"sunny":                  "☀",
+	"cloud":                  "☁",
+	"umbrella":               "☔",
+	"snowflake":              "❄",
+	"rainbow":                "🌈",
+	"ocean":                  "🌊",
+	"seedling":               "🌱",
+	"herb":                   "🌿",
+	"four_leaf_clover":       "🍀",
+	"cactus":                 "🌵",
+	"evergreen_tree":         "🌲",
+	"sunflower":              "🌻",
+	"rose":                   "🌹",
+	"cherry_blossom":         "🌸",
+	"earth_americas":         "🌎",
+	"globe_with_meridians":   "🌐",
+	"crescent_moon":          "🌙",
+	"cat":                    "🐱",
+	"dog":                    "🐶",
+	"fox":                    "🦊",
+	"owl":                    "🦉",
+	"bee":                    "🐝",
+	"bug":                    "🐛",
+	"turtle":                 "🐢",
+	"snake":                  "🐍",
+	"unicorn":                "🦄",
+	"penguin":                "🐧",
+	"crab":                   "🦀",
+	"apple":                  "🍎",
+	"lemon":                  "🍋",
+	"avocado":                "🥑",
+	"hot_pepper":             "🌶",
+	"pizza":                  "🍕",
+	"hamburger":              "🍔",
+	"popcorn":                "🍿",
+	"cake":                   "🍰",
+	"cookie":                 "🍪",
+	"coffee":                 "☕",
+	"tea":                    "🍵",
+	"beer":                   "🍺",
+	"beers":                  "🍻",
+	"wine_glass":             "🍷",
+	"tada":                   "🎉",
+	"confetti_ball":          "🎊",
+	"balloon":                "🎈",
+	"gift":                   "🎁",
+	"ribbon":                 "🎀",
+	"art":                    "🎨",
+	"musical_note":           "🎵",
+	"notes":                  "🎶",
+	"headphones":             "🎧",
+	"microphone":             "🎤",
+	"movie_camera":           "🎥",
+	"film_frames":            "🎞",
+	"camera":                 "📷",
+	"video_game":             "🎮",
+	"game_die":               "🎲",
+	"jigsaw":                 "🧩",
+	"soccer":                 "⚽",
+	"basketball":             "🏀",
+	"moneybag":               "💰",
+	"dollar":                 "💵",
+	"credit_card":            "💳",
+	"shopping_cart":          "🛒",
+	"house":                  "🏠",
+	"office":                 "🏢",
+	"school":                 "🏫",
+	"hospital":               "🏥",
+	"airplane":               "✈",
+	"car":                    "🚗",
+	"bike":                   "🚲",
+	"ship":                   "🚢",
+	"world_map":              "🗺",
+	"compass":                "🧭",
+	"crystal_ball":           "🔮",
+	"test_tube":              "🧪",
+	"dna":                    "🧬",
+	"microscope":             "🔬",
+	"telescope":              "🔭",
+	"satellite":              "📡",
+	"battery":                "🔋",
+	"electric_plug":          "🔌",
+	"floppy_disk":            "💾",
+	"cd":                     "💿",
+	"dvd":                    "📀",
+	"iphone":                 "📱",
+	"telephone":              "☎",
+	"tv":                     "📺",
+	"radio":                  "📻",
+	"candle":                 "🕯",
+	"money_with_wings":       "💸",
+	"hourglass_flowing_sand": "⏳",
+	"zzz":                    "💤",
+	"speech_left":            "🗨",
+	"anger_right":            "🗯",
+	"ballot_box":             "🗳",
+	"scales":                 "⚖",
+	"pen_ballpoint":          "🖊",
+	"fountain_pen":           "🖋",
+	"crayon":                 "🖍",
+	"paintbrush":             "🖌",
+	"straight_ruler":         "📏",
+	"triangular_ruler":       "📐",
+	"scissors":               "✂",
+	"pick":                   "⛏",
+	"shield":                 "🛡",
+	"crossed_swords":         "⚔",
+	"magnet":                 "🧲",
+	"ladder":                 "🪜",
+	"bricks":                 "🧱",
+	"thread":                 "🧵",
+	"yarn":                   "🧶",
+	"knot":                   "🪢",
+	"abacus":                 "🧮",
+	"receipt":                "🧾",
+	"teddy_bear":             "🧸",
+	"nazar_amulet":           "🧿",
+	"identification_card":    "🪪",
+}
+
+// FromShortcode returns the emoji a :shortcode: names, such as :bookmark: or
+// :thumbsup::skin-tone-3:. The colons are optional.
+func FromShortcode(code string) (string, bool) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	name, tone, hasTone := strings.Cut(strings.Trim(code, ":"), "::")
+
+	value, ok := shortcodes[name]
+	if !ok {
+		return "", false
+	}
+	if !hasTone {
+		return value, true
+	}
+
+	modifier, ok := skinTones[tone]
+	if !ok {
+		return "", false
+	}
+
+	// The modifier follows the first emoji of the sequence.
+	first, rest := splitFirstRune(value)
+	return first + modifier + rest, true
+}
+
+// IsShortcode reports whether value is written as a :shortcode:.
+func IsShortcode(value string) bool {
+	value = strings.TrimSpace(value)
+	return len(value) > 2 && strings.HasPrefix(value, ":") && strings.HasSuffix(value, ":")
+}
+
+func splitFirstRune(value string) (string, string) {
+	for idx := range value {
+		if idx > 0 {
+			return value[:idx], value[idx:]
+		}
+	}
+
+	return value, ""
+}
@@ -0,0 +1,157 @@
+// Package emojikey turns the many ways one emoji can be typed or reacted with into a single
+// key, so that settings saved for ❤ match a ❤️ reaction and, optionally, 👍 matches 👍🏽.
+package emojikey
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// zeroWidthJoiner glues emoji into sequences such as 🏳️‍🌈.
+	zeroWidthJoiner = 0x200D
+	// keycap turns #, * and digits into keycap emoji.
+	keycap = 0x20E3
+	// presentationSelector asks for the colorful emoji presentation of a character.
+	presentationSelector = 0xFE0F
+)
+
+// Normalize returns the canonical key of value: variation selectors are removed, because
+// clients send ❤ and ❤️ interchangeably. Custom emoji are keyed "name:id" whether animated or
+// not, matching the name reactions arrive with, so "a:name:id" loses its prefix.
+func Normalize(value string) string {
+	value = strings.TrimSpace(value)
+	if prefix, rest, ok := strings.Cut(value, ":"); ok && prefix == "a" && strings.Count(rest, ":") == 1 {
+		return rest
+	}
+	if !strings.ContainsFunc(value, isVariationSelector) {
+		return value
+	}
+
+	return strings.Map(func(r rune) rune {
+		if isVariationSelector(r) {
+			return -1
+		}
+		return r
+	}, value)
+}
+
+// FoldSkinTones returns the normalized value without skin-tone modifiers, so every tone of an
+// emoji shares one key.
+func FoldSkinTones(value string) string {
+	return strings.Map(func(r rune) rune {
+		if isSkinTone(r) || isVariationSelector(r) {
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(value))
+}
+
+// Same reports whether a and b are the same emoji, ignoring variation selectors and, with
+// foldSkinTones, skin tones.
+func Same(a, b string, foldSkinTones bool) bool {
+	if Normalize(a) == Normalize(b) {
+		return true
+	}
+
+	return foldSkinTones && FoldSkinTones(a) == FoldSkinTones(b)
+}
+
+// Lookup finds the entry of m, whose keys are normalized, for the emoji key. An exact match
+// wins; with foldSkinTones, an entry for the same emoji in another tone, or without one, is
+// used otherwise. It returns the matching key.
+func Lookup[V any](m map[string]V, key string, foldSkinTones bool) (string, V, bool) {
+	key = Normalize(key)
+	if value, ok := m[key]; ok {
+		return key, value, true
+	}
+
+	var zero V
+	if !foldSkinTones {
+		return "", zero, false
+	}
+
+	folded := FoldSkinTones(key)
+	if value, ok := m[folded]; ok {
+		return folded, value, true
+	}
+
+	// Fall back to any other tone, picking the same one every time.
+	var candidates []string
+	for candidate := range m {
+		if FoldSkinTones(candidate) == folded {
+			candidates = append(candidates, candidate)
+		}
+	}
+	if len(candidates) == 0 {
+		return "", zero, false
+	}
+	sort.Strings(candidates)
+
+	return candidates[0], m[candidates[0]], true
+}
+
+// Display renders a normalized key for messages, restoring the presentation selector after
+// characters that would otherwise show as plain text. Custom emoji keys pass through.
+func Display(key string) string {
+	if strings.Contains(key, ":") {
+		return key
+	}
+
+	var builder strings.Builder
+	for idx, r := range key {
+		builder.WriteRune(r)
+
+		next, _ := utf8.DecodeRuneInString(key[idx+utf8.RuneLen(r):])
+		if isVariationSelector(r) || next == presentationSelector || isSkinTone(next) {
+			continue
+		}
+
+		switch {
+		case r < 0x80:
+			// #, * and digits are only emoji as keycaps.
+			if next == keycap {
+				builder.WriteRune(presentationSelector)
+			}
+		case defaultsToText(r):
+			builder.WriteRune(presentationSelector)
+		}
+	}
+
+	return builder.String()
+}
+
+func isVariationSelector(r rune) bool {
+	return r >= 0xFE00 && r <= 0xFE0F
+}
+
+func isSkinTone(r rune) bool {
+	return r >= 0x1F3FB && r <= 0x1F3FF
+}
+
+// defaultsToText reports whether the emoji r shows as plain text unless followed by the
+// presentation selector.
+func defaultsToText(r rune) bool {
+	switch {
+	case r == 0xA9 || r == 0xAE:
+		// © and ®, the only emoji before the general punctuation block.
+		return true
+	case r < 0x203C || r == zeroWidthJoiner || r == keycap:
+		return false
+	case r < 0x1F000:
+		return !inRanges(r, emojiPresentationBelow1F000)
+	}
+
+	return inRanges(r, textPresentationAbove1F000)
+}
+
+func inRanges(r rune, ranges [][2]rune) bool {
+	for _, bounds := range ranges {
+		if r >= bounds[0] && r <= bounds[1] {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,105 @@
+package emojikey
+
+import "testing"
+
+func TestNormalizeStripsVariationSelectors(t *testing.T) {
+	cases := map[string]string{
+		"❤️":          "❤",
+		"❤":           "❤",
+		" 🔖 ":         "🔖",
+		"🏳️‍🌈":        "🏳‍🌈",
+		"1️⃣":         "1⃣",
+		"blob:1234":   "blob:1234",
+		"a:party:456": "party:456",
+	}
+
+	for input, want := range cases {
+		if got := Normalize(input); got != want {
+			t.Errorf("Normalize(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestFoldSkinTones(t *testing.T) {
+	if got := FoldSkinTones("👍🏽"); got != "👍" {
+		t.Errorf("FoldSkinTones(👍🏽) = %q, want 👍", got)
+	}
+	if got := FoldSkinTones("🧑🏿‍💻"); got != "🧑‍💻" {
+		t.Errorf("FoldSkinTones(🧑🏿‍💻) = %q, want 🧑‍💻", got)
+	}
+	if !Same("✌️", "✌🏻", true) {
+		t.Error("expected ✌️ and ✌🏻 to match with folding")
+	}
+	if Same("👍", "👍🏽", false) {
+		t.Error("expected tones to differ without folding")
+	}
+}
+
+func TestLookupPrefersExactTone(t *testing.T) {
+	prefs := map[string]string{"👍": "any", "👍🏿": "dark"}
+
+	if key, got, ok := Lookup(prefs, "👍🏿", true); !ok || got != "dark" || key != "👍🏿" {
+		t.Errorf("exact tone = %q, %q, %v; want dark", key, got, ok)
+	}
+	if _, got, ok := Lookup(prefs, "👍🏻", true); !ok || got != "any" {
+		t.Errorf("folded tone = %q, %v; want any", got, ok)
+	}
+	if _, _, ok := Lookup(prefs, "👍🏻", false); ok {
+		t.Error("expected no match without folding")
+	}
+	if _, got, ok := Lookup(map[string]string{"👋🏽": "wave"}, "👋", true); !ok || got != "wave" {
+		t.Errorf("toned entry = %q, %v; want wave", got, ok)
+	}
+	if _, got, ok := Lookup(map[string]string{"❤": "heart"}, "❤️", false); !ok || got != "heart" {
+		t.Errorf("variation selector = %q, %v; want heart", got, ok)
+	}
+}
+
+func TestFromShortcode(t *testing.T) {
+	cases := map[string]string{
+		":bookmark:":                  "🔖",
+		"BOOKMARK":                    "🔖",
+		":+1:":                        "👍",
+		":thumbsup::skin-tone-3:":     "👍🏽",
+		":technologist::skin-tone-5:": "🧑🏿‍💻",
+	}
+
+	for input, want := range cases {
+		got, ok := FromShortcode(input)
+		if !ok || got != want {
+			t.Errorf("FromShortcode(%q) = %q, %v; want %q", input, got, ok, want)
+		}
+	}
+
+	for _, input := range []string{":not_an_emoji:", ":thumbsup::skin-tone-9:"} {
+		if _, ok := FromShortcode(input); ok {
+			t.Errorf("expected %q to be unknown", input)
+		}
+	}
+}
+
+func TestShortcodesAreNormalized(t *testing.T) {
+	for name, value := range shortcodes {
+		if Normalize(value) != value {
+			t.Errorf("shortcode %q has a variation selector", name)
+		}
+	}
+}
+
+func TestDisplayRestoresPresentation(t *testing.T) {
+	cases := map[string]string{
+		"❤":         "❤️",
+		"🔖":         "🔖",
+		"✅":         "✅",
+		"🏳‍🌈":       "🏳️‍🌈",
+		"1⃣":        "1️⃣",
+		"✌🏻":        "✌🏻",
+		"blob:1234": "blob:1234",
+	}
+
+	for input, want := range cases {
+		if got := Display(input); got != want {
+			t.Errorf("Display(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
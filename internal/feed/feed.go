@@ -0,0 +1,241 @@
+// Package feed serves each user's saved bookmarks as Atom and RSS feeds, and their scheduled
+// reminders as an iCalendar feed, over HTTP.
+package feed
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+)
+
+// Status values an item can be filtered by.
+const (
+	StatusOpen = "open"
+	StatusDone = "done"
+)
+
+// Feed is one user's list of bookmarks.
+type Feed struct {
+	ID      string
+	Title   string
+	SelfURL string
+	Updated time.Time
+	Items   []Item
+}
+
+// Item is one saved bookmark.
+type Item struct {
+	ID          string
+	Title       string
+	Link        string
+	SourceURL   string
+	Content     string
+	Author      string
+	Channel     string
+	Emoji       string
+	Status      string
+	Saved       time.Time
+	Attachments []string
+}
+
+// NewToken returns a random URL-safe token for a feed address.
+func NewToken() (string, error) {
+	raw := make([]byte, 24)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// itemHTML renders the body shared by Atom and RSS entries.
+func itemHTML(item Item) string {
+	var body strings.Builder
+	if item.Content != "" {
+		fmt.Fprintf(&body, "<p>%s</p>\n", strings.ReplaceAll(html.EscapeString(item.Content), "\n", "<br>"))
+	}
+	for _, attachment := range item.Attachments {
+		fmt.Fprintf(&body, "<p>📎 <a href=\"%s\">%s</a></p>\n", html.EscapeString(attachment), html.EscapeString(attachmentName(attachment)))
+	}
+
+	var meta []string
+	if item.Channel != "" {
+		meta = append(meta, "#"+html.EscapeString(item.Channel))
+	}
+	if item.Author != "" {
+		meta = append(meta, "by "+html.EscapeString(item.Author))
+	}
+	if item.Emoji != "" {
+		meta = append(meta, html.EscapeString(item.Emoji))
+	}
+	if item.Status == StatusDone {
+		meta = append(meta, "✅ done")
+	}
+	if item.SourceURL != "" {
+		meta = append(meta, fmt.Sprintf("<a href=\"%s\">🔗 Source message</a>", html.EscapeString(item.SourceURL)))
+	}
+	if len(meta) > 0 {
+		fmt.Fprintf(&body, "<p>%s</p>", strings.Join(meta, " · "))
+	}
+
+	return body.String()
+}
+
+func attachmentName(rawURL string) string {
+	name := rawURL
+	if idx := strings.IndexAny(name, "?#"); idx >= 0 {
+		name = name[:idx]
+	}
+	if idx := strings.LastIndex(name, "/"); idx >= 0 && idx < len(name)-1 {
+		name = name[idx+1:]
+	}
+
+	return name
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Updated    string         `xml:"updated"`
+	Published  string         `xml:"published"`
+	Links      []atomLink     `xml:"link"`
+	Author     *atomAuthor    `xml:"author,omitempty"`
+	Categories []atomCategory `xml:"category"`
+	Content    atomContent    `xml:"content"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// Atom renders f as an Atom 1.0 document.
+func Atom(f Feed) ([]byte, error) {
+	doc := atomFeed{
+		ID:      f.ID,
+		Title:   f.Title,
+		Updated: f.Updated.UTC().Format(time.RFC3339),
+		Links:   []atomLink{{Rel: "self", Href: f.SelfURL}},
+	}
+
+	for _, item := range f.Items {
+		entry := atomEntry{
+			ID:        f.ID + ":" + item.ID,
+			Title:     item.Title,
+			Updated:   item.Saved.UTC().Format(time.RFC3339),
+			Published: item.Saved.UTC().Format(time.RFC3339),
+			Content:   atomContent{Type: "html", Body: itemHTML(item)},
+		}
+		if item.Link != "" {
+			entry.Links = append(entry.Links, atomLink{Rel: "alternate", Href: item.Link})
+		}
+		if item.SourceURL != "" && item.SourceURL != item.Link {
+			entry.Links = append(entry.Links, atomLink{Rel: "related", Href: item.SourceURL})
+		}
+		if item.Author != "" {
+			entry.Author = &atomAuthor{Name: item.Author}
+		}
+		for _, term := range []string{item.Emoji, item.Status} {
+			if term != "" {
+				entry.Categories = append(entry.Categories, atomCategory{Term: term})
+			}
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+
+	return marshal(doc)
+}
+
+type rssDocument struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link,omitempty"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Categories  []string `xml:"category"`
+	Description string   `xml:"description"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// RSS renders f as an RSS 2.0 document.
+func RSS(f Feed) ([]byte, error) {
+	doc := rssDocument{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:         f.Title,
+			Link:          f.SelfURL,
+			Description:   f.Title,
+			LastBuildDate: f.Updated.UTC().Format(time.RFC1123Z),
+		},
+	}
+
+	for _, item := range f.Items {
+		entry := rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			GUID:        rssGUID{Value: f.ID + ":" + item.ID},
+			PubDate:     item.Saved.UTC().Format(time.RFC1123Z),
+			Description: itemHTML(item),
+		}
+		for _, term := range []string{item.Emoji, item.Status} {
+			if term != "" {
+				entry.Categories = append(entry.Categories, term)
+			}
+		}
+		doc.Channel.Items = append(doc.Channel.Items, entry)
+	}
+
+	return marshal(doc)
+}
+
+func marshal(doc any) ([]byte, error) {
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), data...), nil
+}
@@ -0,0 +1,197 @@
+package feed
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/example/discord-bookmark-manager/internal/reminders"
+	"github.com/example/discord-bookmark-manager/internal/store"
+)
+
+func testServer(t *testing.T) (*Server, *store.EmojiStore) {
+	t.Helper()
+
+	users, err := store.NewEmojiStore("")
+	if err != nil {
+		t.Fatalf("NewEmojiStore returned error: %v", err)
+	}
+	bookmarks, err := store.NewBookmarkStore("")
+	if err != nil {
+		t.Fatalf("NewBookmarkStore returned error: %v", err)
+	}
+
+	saved := time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC)
+	for _, bookmark := range []store.Bookmark{
+		{ID: "a", UserID: "user", Emoji: "🔖", Title: "Release <plan>", Content: "Ship it & relax", ChannelName: "general", SourceGuildID: "1", SourceChannelID: "2", SourceMessageID: "3", SavedChannelID: "9", SavedMessageID: "10", SavedAt: saved},
+		{ID: "b", UserID: "user", Emoji: "later:123", Title: "Read later", Completed: true, SavedAt: saved.Add(time.Hour)},
+		{ID: "c", UserID: "other", Emoji: "🔖", Title: "Someone else's", SavedAt: saved},
+	} {
+		if err := bookmarks.Add(bookmark); err != nil {
+			t.Fatalf("Add returned error: %v", err)
+		}
+	}
+
+	if err := users.SetFeedToken("user", "secret-token"); err != nil {
+		t.Fatalf("SetFeedToken returned error: %v", err)
+	}
+
+	return NewServer(":0", "https://feeds.example.com/", users, bookmarks), users
+}
+
+func get(t *testing.T, server *Server, path string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+	return recorder
+}
+
+func atomTitles(t *testing.T, body string) []string {
+	t.Helper()
+
+	var doc atomFeed
+	if err := xml.Unmarshal([]byte(body), &doc); err != nil {
+		t.Fatalf("invalid Atom document: %v\n%s", err, body)
+	}
+
+	var titles []string
+	for _, entry := range doc.Entries {
+		titles = append(titles, entry.Title)
+	}
+	return titles
+}
+
+func TestServeAtomFeed(t *testing.T) {
+	server, _ := testServer(t)
+
+	response := get(t, server, "/feeds/secret-token/atom.xml")
+	if response.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d", response.Code)
+	}
+	if !strings.HasPrefix(response.Header().Get("Content-Type"), "application/atom+xml") {
+		t.Fatalf("unexpected content type %q", response.Header().Get("Content-Type"))
+	}
+
+	body := response.Body.String()
+	if titles := atomTitles(t, body); strings.Join(titles, "|") != "Read later|Release <plan>" {
+		t.Fatalf("unexpected entries %q", titles)
+	}
+	for _, want := range []string{
+		`href="https://feeds.example.com/feeds/secret-token/atom.xml"`,
+		`href="https://discord.com/channels/@me/9/10"`,
+		`href="https://discord.com/channels/1/2/3"`,
+		"Ship it &amp;amp; relax",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("feed is missing %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestServeRSSFeed(t *testing.T) {
+	server, _ := testServer(t)
+
+	response := get(t, server, "/feeds/secret-token/rss.xml")
+	if response.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d", response.Code)
+	}
+
+	var doc rssDocument
+	if err := xml.Unmarshal(response.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid RSS document: %v", err)
+	}
+	if len(doc.Channel.Items) != 2 || doc.Channel.Items[1].Link != "https://discord.com/channels/@me/9/10" {
+		t.Fatalf("unexpected items %+v", doc.Channel.Items)
+	}
+}
+
+func TestServeFeedFilters(t *testing.T) {
+	server, _ := testServer(t)
+
+	cases := map[string]string{
+		"?status=open":              "Release <plan>",
+		"?status=done":              "Read later",
+		"?emoji=later":              "Read later",
+		"?emoji=%F0%9F%94%96":       "Release <plan>",
+		"?emoji=later,%F0%9F%94%96": "Read later|Release <plan>",
+		"?emoji=:bookmark:":         "Release <plan>",
+	}
+	for query, want := range cases {
+		response := get(t, server, "/feeds/secret-token/atom.xml"+query)
+		if got := strings.Join(atomTitles(t, response.Body.String()), "|"); got != want {
+			t.Errorf("%s: got %q, want %q", query, got, want)
+		}
+	}
+
+	if response := get(t, server, "/feeds/secret-token/atom.xml?status=maybe"); response.Code != http.StatusBadRequest {
+		t.Fatalf("expected an invalid status to be rejected, got %d", response.Code)
+	}
+}
+
+func TestServeFeedRequiresCurrentToken(t *testing.T) {
+	server, users := testServer(t)
+
+	if response := get(t, server, "/feeds/wrong/atom.xml"); response.Code != http.StatusNotFound {
+		t.Fatalf("expected unknown tokens to 404, got %d", response.Code)
+	}
+
+	if err := users.SetFeedToken("user", "rotated"); err != nil {
+		t.Fatalf("SetFeedToken returned error: %v", err)
+	}
+	if response := get(t, server, "/feeds/secret-token/atom.xml"); response.Code != http.StatusNotFound {
+		t.Fatalf("expected the old token to stop working, got %d", response.Code)
+	}
+	if response := get(t, server, "/feeds/rotated/atom.xml"); response.Code != http.StatusOK {
+		t.Fatalf("expected the new token to work, got %d", response.Code)
+	}
+}
+
+func TestServeReminderCalendar(t *testing.T) {
+	server, _ := testServer(t)
+
+	service, err := reminders.NewService(nil, "")
+	if err != nil {
+		t.Fatalf("NewService returned error: %v", err)
+	}
+	t.Cleanup(service.Close)
+	server.SetReminders(service)
+
+	when := time.Now().Add(time.Hour)
+	service.Schedule("daily-reminder", when, reminders.Payload{UserID: "user", ChannelName: "general", Daily: true}, false)
+	service.Schedule("once-reminder", when, reminders.Payload{UserID: "user", ChannelName: "random"}, false)
+	service.Schedule("other-reminder", when, reminders.Payload{UserID: "other", ChannelName: "secret"}, false)
+
+	response := get(t, server, "/feeds/secret-token/reminders.ics")
+	if response.Code != http.StatusOK || !strings.HasPrefix(response.Header().Get("Content-Type"), "text/calendar") {
+		t.Fatalf("unexpected response %d %q", response.Code, response.Header().Get("Content-Type"))
+	}
+
+	body := response.Body.String()
+	if strings.Count(body, "BEGIN:VEVENT") != 2 || strings.Contains(body, "secret") {
+		t.Fatalf("expected only the user's two reminders:\n%s", body)
+	}
+	if !strings.Contains(body, "UID:daily-reminder@discord-bookmark-manager\r\nDTSTAMP") || strings.Count(body, "RRULE:FREQ=DAILY") != 1 {
+		t.Fatalf("expected the daily reminder to repeat:\n%s", body)
+	}
+}
+
+func TestNewTokenIsURLSafe(t *testing.T) {
+	token, err := NewToken()
+	if err != nil {
+		t.Fatalf("NewToken returned error: %v", err)
+	}
+	if len(token) < 32 || strings.ContainsAny(token, "+/=") {
+		t.Fatalf("unexpected token %q", token)
+	}
+}
+
+func TestDeletedSourcesShowNoContent(t *testing.T) {
+	item := toItem(store.Bookmark{ID: "a", Title: "Plans", Content: "secret", AttachmentURLs: []string{"https://cdn.example/a.png"}, SourceDeleted: true})
+	if item.Content != "" || len(item.Attachments) != 0 {
+		t.Fatalf("expected a deleted source to show no content, got %+v", item)
+	}
+}
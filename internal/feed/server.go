@@ -0,0 +1,303 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/example/discord-bookmark-manager/internal/emojikey"
+	"github.com/example/discord-bookmark-manager/internal/ics"
+	"github.com/example/discord-bookmark-manager/internal/reminders"
+	"github.com/example/discord-bookmark-manager/internal/store"
+)
+
+const (
+	// maxItems bounds one feed to the most recent bookmarks.
+	maxItems = 100
+	// FormatAtom and FormatRSS name the feed documents under a user's token.
+	FormatAtom = "atom.xml"
+	FormatRSS  = "rss.xml"
+	// FormatCalendar names the iCalendar feed of the user's scheduled reminders.
+	FormatCalendar = "reminders.ics"
+)
+
+// Server serves feeds at /feeds/<token>/atom.xml and /feeds/<token>/rss.xml, and the user's
+// reminders at /feeds/<token>/reminders.ics. Only someone who knows a user's token can read
+// their feeds; rotating the token revokes old addresses.
+type Server struct {
+	addr      string
+	baseURL   string
+	users     *store.EmojiStore
+	bookmarks *store.BookmarkStore
+	reminders *reminders.Service
+	mux       *http.ServeMux
+	server    *http.Server
+	now       func() time.Time
+}
+
+// NewServer constructs a Server listening on addr. Links handed to users start with baseURL.
+// An empty addr yields a disabled server.
+func NewServer(addr, baseURL string, users *store.EmojiStore, bookmarks *store.BookmarkStore) *Server {
+	s := &Server{
+		addr:      addr,
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		users:     users,
+		bookmarks: bookmarks,
+		mux:       http.NewServeMux(),
+		now:       time.Now,
+	}
+	s.mux.HandleFunc("GET /feeds/{token}/{format}", s.serveFeed)
+
+	return s
+}
+
+// SetReminders sets the service whose scheduled reminders the calendar feed lists.
+func (s *Server) SetReminders(service *reminders.Service) {
+	s.reminders = service
+}
+
+// Enabled reports whether the server is configured to listen.
+func (s *Server) Enabled() bool {
+	return s != nil && s.addr != ""
+}
+
+// URL returns the public address of the document format for token.
+func (s *Server) URL(token, format string) string {
+	return fmt.Sprintf("%s/feeds/%s/%s", s.baseURL, token, format)
+}
+
+// Handler returns the HTTP handler serving every feed.
+func (s *Server) Handler() http.Handler {
+	return s.mux
+}
+
+// Start begins listening in the background.
+func (s *Server) Start() error {
+	if !s.Enabled() {
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("feed server: %w", err)
+	}
+
+	s.server = &http.Server{
+		Handler:           s.mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       time.Minute,
+	}
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("feed server stopped: %v", err)
+		}
+	}()
+
+	log.Printf("serving bookmark feeds on %s", s.addr)
+	return nil
+}
+
+// Close stops the server, giving in-flight requests a few seconds to finish.
+func (s *Server) Close() error {
+	if s == nil || s.server == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return s.server.Shutdown(ctx)
+}
+
+func (s *Server) serveFeed(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.users.UserByFeedToken(r.PathValue("token"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	var render func(Feed) ([]byte, error)
+	var contentType string
+	switch r.PathValue("format") {
+	case FormatAtom:
+		render, contentType = Atom, "application/atom+xml; charset=utf-8"
+	case FormatRSS:
+		render, contentType = RSS, "application/rss+xml; charset=utf-8"
+	case FormatCalendar:
+		s.serveCalendar(w, r, userID)
+		return
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	filter, err := parseFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data, err := render(s.build(userID, r.PathValue("token"), r.PathValue("format"), filter))
+	if err != nil {
+		log.Printf("failed to render feed: %v", err)
+		http.Error(w, "failed to render feed", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	w.Write(data)
+}
+
+// serveCalendar lists userID's scheduled reminders. Reminders set for a time of day repeat
+// daily.
+func (s *Server) serveCalendar(w http.ResponseWriter, r *http.Request, userID string) {
+	if s.reminders == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	var events []ics.Event
+	for _, pending := range s.reminders.ForUser(userID) {
+		events = append(events, pending.Payload.CalendarEvent(pending.ID, pending.When))
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	w.Write(ics.Calendar("Bookmark reminders", events, s.now()))
+}
+
+// filter narrows a feed by the emoji a bookmark was saved with and its status.
+type filter struct {
+	emojis []string
+	status string
+}
+
+func parseFilter(r *http.Request) (filter, error) {
+	query := r.URL.Query()
+	f := filter{status: strings.ToLower(query.Get("status"))}
+
+	switch f.status {
+	case "", "all":
+		f.status = ""
+	case StatusOpen, StatusDone:
+	default:
+		return filter{}, fmt.Errorf("status must be open, done or all")
+	}
+
+	for _, raw := range query["emoji"] {
+		for _, emoji := range strings.Split(raw, ",") {
+			if emoji = strings.TrimSpace(emoji); emoji != "" {
+				f.emojis = append(f.emojis, emoji)
+			}
+		}
+	}
+
+	return f, nil
+}
+
+// matches reports whether bookmark passes f. Custom emoji match by their stored key or name;
+// Unicode emoji match in any skin tone and by :shortcode:.
+func (f filter) matches(bookmark store.Bookmark) bool {
+	if f.status != "" && bookmarkStatus(bookmark) != f.status {
+		return false
+	}
+	if len(f.emojis) == 0 {
+		return true
+	}
+
+	name, _, _ := strings.Cut(bookmark.Emoji, ":")
+	for _, emoji := range f.emojis {
+		if emojikey.Same(emoji, bookmark.Emoji, true) || strings.Trim(emoji, ":") == name {
+			return true
+		}
+		if unicode, ok := emojikey.FromShortcode(emoji); ok && emojikey.Same(unicode, bookmark.Emoji, true) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Server) build(userID, token, format string, f filter) Feed {
+	feed := Feed{
+		ID:      "urn:discord-bookmarks:user:" + userID,
+		Title:   "Saved bookmarks",
+		SelfURL: s.URL(token, format),
+		Updated: s.now(),
+	}
+
+	for _, bookmark := range s.bookmarks.ByUser(userID) {
+		if !f.matches(bookmark) {
+			continue
+		}
+		if len(feed.Items) == 0 {
+			feed.Updated = bookmark.SavedAt
+		}
+
+		feed.Items = append(feed.Items, toItem(bookmark))
+		if len(feed.Items) == maxItems {
+			break
+		}
+	}
+
+	return feed
+}
+
+func toItem(bookmark store.Bookmark) Item {
+	title := bookmark.Title
+	if title == "" {
+		title = fmt.Sprintf("Bookmark from #%s", bookmark.ChannelName)
+	}
+
+	item := Item{
+		ID:          bookmark.ID,
+		Title:       title,
+		SourceURL:   jumpURL(bookmark.SourceGuildID, bookmark.SourceChannelID, bookmark.SourceMessageID),
+		Content:     bookmark.Content,
+		Author:      bookmark.AuthorName,
+		Channel:     bookmark.ChannelName,
+		Emoji:       bookmark.Emoji,
+		Status:      bookmarkStatus(bookmark),
+		Saved:       bookmark.SavedAt,
+		Attachments: bookmark.AttachmentURLs,
+	}
+
+	// Bookmarks whose source was deleted before their content was cleared keep none of it.
+	if bookmark.SourceDeleted {
+		item.Content = ""
+		item.Attachments = nil
+	}
+
+	item.Link = item.SourceURL
+	if bookmark.SavedMessageID != "" {
+		item.Link = jumpURL(bookmark.SavedGuildID, bookmark.SavedChannelID, bookmark.SavedMessageID)
+	}
+
+	return item
+}
+
+func bookmarkStatus(bookmark store.Bookmark) string {
+	if bookmark.Completed {
+		return StatusDone
+	}
+
+	return StatusOpen
+}
+
+func jumpURL(guildID, channelID, messageID string) string {
+	if channelID == "" || messageID == "" {
+		return ""
+	}
+	if guildID == "" {
+		guildID = "@me"
+	}
+
+	return fmt.Sprintf("https://discord.com/channels/%s/%s/%s", guildID, channelID, messageID)
+}
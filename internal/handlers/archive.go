@@ -0,0 +1,311 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/example/discord-bookmark-manager/internal/outbox"
+)
+
+const (
+	// maxMessageFiles is the number of files Discord accepts on a single message.
+	maxMessageFiles = 10
+	// maxArchiveBytes keeps a bookmark's uploads under Discord's upload limit for servers
+	// without boosts. Uploads that still exceed a channel's limit fall back to links.
+	maxArchiveBytes = 10 << 20
+	// archiveDownloadTimeout bounds each attachment download.
+	archiveDownloadTimeout = 30 * time.Second
+	// maxFieldValue is Discord's limit for an embed field value; longer values make Discord
+	// reject the whole message.
+	maxFieldValue = 1024
+	// maxReasonLength bounds each reason listed under "⚠️ Not archived".
+	maxReasonLength = 40
+
+	archivedFieldName    = "🗄️ Archived"
+	notArchivedFieldName = "⚠️ Not archived"
+)
+
+var archiveClient = &http.Client{Timeout: archiveDownloadTimeout}
+
+// attachmentDownloads keeps the attachments downloaded for one save, keyed by URL, so every
+// target of the save archives the same bytes without downloading them again. A nil value
+// downloads every time.
+type attachmentDownloads map[string]downloadResult
+
+type downloadResult struct {
+	data []byte
+	err  error
+}
+
+// fetch returns the attachment at url, downloading it up to maxArchiveBytes the first time.
+func (d attachmentDownloads) fetch(url string) ([]byte, error) {
+	if result, ok := d[url]; ok {
+		return result.data, result.err
+	}
+
+	data, err := downloadAttachment(url, maxArchiveBytes)
+	if d != nil {
+		d[url] = downloadResult{data: data, err: err}
+	}
+
+	return data, err
+}
+
+type skippedAttachment struct {
+	attachment *discordgo.MessageAttachment
+	reason     string
+}
+
+// archivedFile is a source attachment uploaded onto the bookmark under name.
+type archivedFile struct {
+	attachment *discordgo.MessageAttachment
+	name       string
+}
+
+// archiveResult lists the attachments archiveAttachments uploaded and those it left as links.
+type archiveResult struct {
+	archived []archivedFile
+	skipped  []skippedAttachment
+}
+
+// archiveAttachments re-uploads the source attachments, taken from downloads, as files on
+// messageSend. Attachments that exceed the size or count limits stay as links and are
+// listed with the reason on the first embed. Files already on messageSend count towards
+// both limits.
+func archiveAttachments(messageSend *discordgo.MessageSend, attachments []*discordgo.MessageAttachment, downloads attachmentDownloads) archiveResult {
+	var result archiveResult
+	if len(attachments) == 0 {
+		return result
+	}
+
+	slots := maxMessageFiles - len(messageSend.Files)
+	budget := maxArchiveBytes
+	usedNames := make(map[string]bool, len(messageSend.Files))
+	for _, file := range messageSend.Files {
+		usedNames[file.Name] = true
+		budget -= fileSize(file)
+	}
+
+	for _, attachment := range attachments {
+		if attachment == nil {
+			continue
+		}
+
+		if slots <= 0 {
+			result.skipped = append(result.skipped, skippedAttachment{attachment, "file limit reached"})
+			continue
+		}
+		if attachment.Size > budget {
+			result.skipped = append(result.skipped, skippedAttachment{attachment, "too large"})
+			continue
+		}
+
+		data, err := downloads.fetch(attachment.URL)
+		if err == nil && len(data) > budget {
+			err = fmt.Errorf("too large")
+		}
+		if err != nil {
+			result.skipped = append(result.skipped, skippedAttachment{attachment, err.Error()})
+			continue
+		}
+
+		name := uniqueFileName(attachmentFileName(attachment), usedNames)
+		usedNames[name] = true
+
+		messageSend.Files = append(messageSend.Files, &discordgo.File{
+			Name:        name,
+			ContentType: attachment.ContentType,
+			Reader:      bytes.NewReader(data),
+		})
+
+		slots--
+		budget -= len(data)
+		result.archived = append(result.archived, archivedFile{attachment, name})
+	}
+
+	if len(messageSend.Embeds) > 0 && messageSend.Embeds[0] != nil {
+		noteArchive(messageSend.Embeds[0], result)
+	}
+
+	return result
+}
+
+// noteArchive lists result on embed and points its image at the uploaded copy, so the
+// preview survives the source.
+func noteArchive(embed *discordgo.MessageEmbed, result archiveResult) {
+	if embed.Image != nil {
+		for _, file := range result.archived {
+			if embed.Image.URL == file.attachment.URL {
+				embed.Image.URL = "attachment://" + file.name
+				break
+			}
+		}
+	}
+
+	if len(result.archived) > 0 {
+		names := make([]string, 0, len(result.archived))
+		for _, file := range result.archived {
+			names = append(names, file.name)
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  archivedFieldName,
+			Value: fieldList(fmt.Sprintf("%d file(s) attached to this bookmark: ", len(names)), names, ", "),
+		})
+	}
+
+	if len(result.skipped) > 0 {
+		entries := make([]string, 0, len(result.skipped))
+		for _, entry := range result.skipped {
+			entries = append(entries, fmt.Sprintf("[%s](%s) — %s", attachmentFileName(entry.attachment), entry.attachment.URL, shortReason(entry.reason)))
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  notArchivedFieldName,
+			Value: fieldList("These stay as links and may stop working if the source is deleted:\n", entries, "\n"),
+		})
+	}
+}
+
+// withoutArchive returns message without the files archived in result, for when Discord
+// refuses the upload as too large. They are listed under "⚠️ Not archived" instead.
+func withoutArchive(message outbox.Message, result archiveResult) outbox.Message {
+	archived := make(map[string]bool, len(result.archived))
+	for _, file := range result.archived {
+		archived[file.name] = true
+	}
+
+	fallback := message
+	fallback.Files = nil
+	for _, file := range message.Files {
+		if !archived[file.Name] {
+			fallback.Files = append(fallback.Files, file)
+		}
+	}
+
+	if len(message.Embeds) == 0 || message.Embeds[0] == nil {
+		return fallback
+	}
+
+	// The embeds are shared with message, so the first one is copied before it changes.
+	embed := *message.Embeds[0]
+	if embed.Image != nil {
+		image := *embed.Image
+		for _, file := range result.archived {
+			if image.URL == "attachment://"+file.name {
+				image.URL = file.attachment.URL
+			}
+		}
+		embed.Image = &image
+	}
+
+	embed.Fields = nil
+	for _, field := range message.Embeds[0].Fields {
+		if field.Name != archivedFieldName && field.Name != notArchivedFieldName {
+			embed.Fields = append(embed.Fields, field)
+		}
+	}
+
+	skipped := make([]skippedAttachment, 0, len(result.archived)+len(result.skipped))
+	for _, file := range result.archived {
+		skipped = append(skipped, skippedAttachment{file.attachment, "upload too large"})
+	}
+	noteArchive(&embed, archiveResult{skipped: append(skipped, result.skipped...)})
+
+	fallback.Embeds = append([]*discordgo.MessageEmbed{&embed}, message.Embeds[1:]...)
+	return fallback
+}
+
+// fieldList renders header followed by entries joined with separator, stopping with a
+// "+N more" tail before the value would exceed maxFieldValue. Lengths are counted in bytes,
+// which is never less than the UTF-16 length Discord counts.
+func fieldList(header string, entries []string, separator string) string {
+	value := header
+	for idx, entry := range entries {
+		if idx > 0 {
+			entry = separator + entry
+		}
+
+		tail := ""
+		if rest := len(entries) - idx - 1; rest > 0 {
+			tail = fmt.Sprintf("%s+%d more", separator, rest)
+		}
+		if len(value)+len(entry)+len(tail) > maxFieldValue {
+			more := fmt.Sprintf("+%d more", len(entries)-idx)
+			if idx > 0 {
+				more = separator + more
+			}
+			return value + more
+		}
+
+		value += entry
+	}
+
+	return value
+}
+
+// shortReason cuts reason to maxReasonLength characters.
+func shortReason(reason string) string {
+	runes := []rune(reason)
+	if len(runes) <= maxReasonLength {
+		return reason
+	}
+
+	return string(runes[:maxReasonLength-1]) + "…"
+}
+
+func downloadAttachment(url string, limit int) ([]byte, error) {
+	resp, err := archiveClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("download failed")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download failed (HTTP %d)", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, int64(limit)+1))
+	if err != nil {
+		return nil, fmt.Errorf("download failed")
+	}
+	if len(data) > limit {
+		return nil, fmt.Errorf("too large")
+	}
+
+	return data, nil
+}
+
+// fileSize returns the size of file's contents. Readers that don't report their length count
+// as empty.
+func fileSize(file *discordgo.File) int {
+	if sized, ok := file.Reader.(interface{ Len() int }); ok {
+		return sized.Len()
+	}
+
+	return 0
+}
+
+func attachmentFileName(attachment *discordgo.MessageAttachment) string {
+	if attachment.Filename != "" {
+		return attachment.Filename
+	}
+
+	return attachment.ID
+}
+
+func uniqueFileName(name string, used map[string]bool) string {
+	if !used[name] {
+		return name
+	}
+
+	for idx := 2; ; idx++ {
+		candidate := fmt.Sprintf("%d-%s", idx, name)
+		if !used[candidate] {
+			return candidate
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/example/discord-bookmark-manager/internal/outbox"
+)
+
+func TestArchiveAttachmentsUploadsAndListsFiles(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, "data")
+	}))
+	t.Cleanup(server.Close)
+
+	messageSend := &discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{{Image: &discordgo.MessageEmbedImage{URL: server.URL + "/a.png"}}}}
+	archiveAttachments(messageSend, []*discordgo.MessageAttachment{
+		{Filename: "a.png", URL: server.URL + "/a.png", Size: 4},
+		{Filename: "notes.txt", URL: server.URL + "/notes.txt", Size: 4},
+		{Filename: "gone.pdf", URL: server.URL + "/missing", Size: 4},
+	}, nil)
+
+	if len(messageSend.Files) != 2 {
+		t.Fatalf("expected two uploaded files, got %d", len(messageSend.Files))
+	}
+	embed := messageSend.Embeds[0]
+	if embed.Image.URL != "attachment://a.png" {
+		t.Fatalf("expected the image to point at the upload, got %q", embed.Image.URL)
+	}
+	if len(embed.Fields) != 2 {
+		t.Fatalf("expected archived and not archived fields, got %+v", embed.Fields)
+	}
+	if got := embed.Fields[0].Value; got != "2 file(s) attached to this bookmark: a.png, notes.txt" {
+		t.Fatalf("unexpected archived field %q", got)
+	}
+	if got := embed.Fields[1].Value; !strings.HasSuffix(got, "[gone.pdf]("+server.URL+"/missing) — download failed (HTTP 404)") {
+		t.Fatalf("unexpected not archived field %q", got)
+	}
+}
+
+func TestArchiveAttachmentsCountsAttachedFiles(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "data")
+	}))
+	t.Cleanup(server.Close)
+
+	transcript := &discordgo.File{Name: "thread.md", Reader: bytes.NewReader(make([]byte, maxArchiveBytes-2))}
+	messageSend := &discordgo.MessageSend{Files: []*discordgo.File{transcript}, Embeds: []*discordgo.MessageEmbed{{}}}
+	result := archiveAttachments(messageSend, []*discordgo.MessageAttachment{{Filename: "a.png", URL: server.URL + "/a.png", Size: 4}}, nil)
+
+	if len(result.archived) != 0 || len(messageSend.Files) != 1 {
+		t.Fatalf("expected the attachment to exceed what the transcript left, got %d files", len(messageSend.Files))
+	}
+	if got := messageSend.Embeds[0].Fields[0].Value; !strings.HasSuffix(got, " — too large") {
+		t.Fatalf("unexpected not archived field %q", got)
+	}
+}
+
+func TestWithoutArchiveListsFilesAsLinks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "data")
+	}))
+	t.Cleanup(server.Close)
+
+	messageSend := &discordgo.MessageSend{
+		Files:  []*discordgo.File{{Name: "thread.md", Reader: strings.NewReader("transcript")}},
+		Embeds: []*discordgo.MessageEmbed{{Image: &discordgo.MessageEmbedImage{URL: server.URL + "/a.png"}}},
+	}
+	result := archiveAttachments(messageSend, []*discordgo.MessageAttachment{
+		{Filename: "a.png", URL: server.URL + "/a.png", Size: 4},
+		{Filename: "big.zip", URL: server.URL + "/big.zip", Size: maxArchiveBytes + 1},
+	}, nil)
+	message, err := outbox.NewMessage(messageSend)
+	if err != nil {
+		t.Fatalf("NewMessage returned error: %v", err)
+	}
+
+	fallback := withoutArchive(message, result)
+
+	if len(fallback.Files) != 1 || fallback.Files[0].Name != "thread.md" {
+		t.Fatalf("expected only the transcript to stay attached, got %+v", fallback.Files)
+	}
+	embed := fallback.Embeds[0]
+	if embed.Image.URL != server.URL+"/a.png" {
+		t.Fatalf("expected the image to point at the source again, got %q", embed.Image.URL)
+	}
+	if len(embed.Fields) != 1 || embed.Fields[0].Name != notArchivedFieldName {
+		t.Fatalf("expected a single not archived field, got %+v", embed.Fields)
+	}
+	if got := embed.Fields[0].Value; !strings.Contains(got, "[a.png]("+server.URL+"/a.png) — upload too large") || !strings.Contains(got, "[big.zip]") {
+		t.Fatalf("unexpected not archived field %q", got)
+	}
+	if message.Embeds[0].Image.URL != "attachment://a.png" || len(message.Embeds[0].Fields) != 2 {
+		t.Fatalf("expected the original message to keep its archive, got %+v", message.Embeds[0])
+	}
+}
+
+func TestArchiveAttachmentsDownloadsOncePerSave(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		fmt.Fprint(w, "data")
+	}))
+	t.Cleanup(server.Close)
+
+	attachments := []*discordgo.MessageAttachment{{Filename: "a.png", URL: server.URL + "/a.png", Size: 4}}
+	downloads := make(attachmentDownloads)
+	for target := 0; target < 3; target++ {
+		messageSend := &discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{{}}}
+		archiveAttachments(messageSend, attachments, downloads)
+		if len(messageSend.Files) != 1 {
+			t.Fatalf("target %d: expected the attachment to be uploaded, got %d files", target, len(messageSend.Files))
+		}
+		if data, _ := io.ReadAll(messageSend.Files[0].Reader); string(data) != "data" {
+			t.Fatalf("target %d: expected the downloaded bytes, got %q", target, data)
+		}
+	}
+
+	if got := requests.Load(); got != 1 {
+		t.Fatalf("expected one download for the save, got %d", got)
+	}
+}
+
+func TestArchiveAttachmentsKeepsFieldsWithinLimit(t *testing.T) {
+	files := make([]*discordgo.File, maxMessageFiles)
+	for idx := range files {
+		files[idx] = &discordgo.File{Name: fmt.Sprintf("transcript-%d.md", idx)}
+	}
+	messageSend := &discordgo.MessageSend{Files: files, Embeds: []*discordgo.MessageEmbed{{}}}
+
+	var attachments []*discordgo.MessageAttachment
+	for idx := 0; idx < 12; idx++ {
+		attachments = append(attachments, &discordgo.MessageAttachment{
+			Filename: fmt.Sprintf("file-%d.png", idx),
+			URL:      fmt.Sprintf("https://cdn.discordapp.com/attachments/1/%d/file.png?ex=%s", idx, strings.Repeat("a", 200)),
+		})
+	}
+	archiveAttachments(messageSend, attachments, nil)
+
+	value := messageSend.Embeds[0].Fields[0].Value
+	if len(value) > maxFieldValue {
+		t.Fatalf("field value is %d bytes, over the %d limit", len(value), maxFieldValue)
+	}
+	if !strings.Contains(value, "[file-0.png]") || !strings.HasSuffix(value, " more") {
+		t.Fatalf("expected the first entries and a +N more tail, got %q", value)
+	}
+}
+
+func TestFieldListCountsOmittedEntries(t *testing.T) {
+	entries := []string{strings.Repeat("a", 600), strings.Repeat("b", 600), "c"}
+	if got := fieldList("Files: ", entries, ", "); got != "Files: "+strings.Repeat("a", 600)+", +2 more" {
+		t.Fatalf("unexpected list %q", got)
+	}
+	if got := fieldList("Files: ", []string{"a", "b"}, ", "); got != "Files: a, b" {
+		t.Fatalf("unexpected list %q", got)
+	}
+	if got := shortReason(strings.Repeat("x", 100)); len([]rune(got)) != maxReasonLength || !strings.HasSuffix(got, "…") {
+		t.Fatalf("expected a reason of %d characters, got %q", maxReasonLength, got)
+	}
+}
@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/example/discord-bookmark-manager/internal/store"
+)
+
+// assigneeFieldName names the embed field showing who reads a channel post for the team.
+const assigneeFieldName = "📖 Assignee"
+
+// withTeamButtons returns components with a row of vote, claim and assign buttons for
+// bookmarkID, replacing the row when it is already there.
+func withTeamButtons(components []discordgo.MessageComponent, bookmarkID string) []discordgo.MessageComponent {
+	result := make([]discordgo.MessageComponent, 0, len(components)+1)
+	for _, component := range components {
+		var row []discordgo.MessageComponent
+		switch typed := component.(type) {
+		case *discordgo.ActionsRow:
+			row = typed.Components
+		case discordgo.ActionsRow:
+			row = typed.Components
+		}
+
+		team := false
+		for _, child := range row {
+			team = team || isActionButton(child, ActionClaim) || isActionButton(child, ActionVote)
+		}
+		if !team {
+			result = append(result, component)
+		}
+	}
+
+	return append(result, discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+		discordgo.Button{
+			Style:    discordgo.SecondaryButton,
+			CustomID: NewCustomID(ActionVote, bookmarkID, voteUpArg).Encode(),
+			Emoji:    discordgo.ComponentEmoji{Name: "👍"},
+		},
+		discordgo.Button{
+			Style:    discordgo.SecondaryButton,
+			CustomID: NewCustomID(ActionVote, bookmarkID, voteDownArg).Encode(),
+			Emoji:    discordgo.ComponentEmoji{Name: "👎"},
+		},
+		discordgo.Button{
+			Label:    "I'll read it",
+			Style:    discordgo.PrimaryButton,
+			CustomID: NewCustomID(ActionClaim, bookmarkID).Encode(),
+			Emoji:    discordgo.ComponentEmoji{Name: "📖"},
+		},
+		discordgo.Button{
+			Label:    "Assign…",
+			Style:    discordgo.SecondaryButton,
+			CustomID: NewCustomID(ActionAssign, bookmarkID).Encode(),
+			Emoji:    discordgo.ComponentEmoji{Name: "👥"},
+		},
+	}})
+}
+
+func describeAssignee(assigneeID string) string {
+	if assigneeID == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("<@%s>", assigneeID)
+}
+
+// claim assigns the bookmark to the member pressing the button. Pressing it again while
+// assigned hands the bookmark back.
+func (h *ComponentHandler) claim(s *discordgo.Session, i *discordgo.InteractionCreate, id CustomID) {
+	bookmark, ok := h.lookup(i, id)
+	userID := interactionUserID(i)
+	if !ok || userID == "" {
+		if err := respondEphemeral(s, i, "⚠️ I can't find this bookmark anymore, so it can't be claimed."); err != nil {
+			log.Printf("failed to reject claim: %v", err)
+		}
+		return
+	}
+
+	if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{Type: discordgo.InteractionResponseDeferredMessageUpdate}); err != nil {
+		log.Printf("failed to acknowledge claim interaction: %v", err)
+		return
+	}
+
+	assigneeID := userID
+	if bookmark.AssigneeID == userID {
+		assigneeID = ""
+	}
+
+	if err := h.assign(s, bookmark, assigneeID, i.Message); err != nil {
+		log.Printf("failed to claim bookmark %s: %v", bookmark.ID, err)
+	}
+}
+
+// openAssign shows the member pressing Assign… a picker for who should read the bookmark.
+func (h *ComponentHandler) openAssign(s *discordgo.Session, i *discordgo.InteractionCreate, id CustomID) {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: "👥 Who should read this bookmark? They get its reminder instead of the saver.",
+			Flags:   discordgo.MessageFlagsEphemeral,
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+					discordgo.SelectMenu{
+						MenuType:    discordgo.UserSelectMenu,
+						CustomID:    NewCustomID(ActionAssignTo, id.BookmarkID).Encode(),
+						Placeholder: "Pick a member",
+						MaxValues:   1,
+					},
+				}},
+			},
+		},
+	})
+	if err != nil {
+		log.Printf("failed to open assign picker: %v", err)
+	}
+}
+
+// assignTo assigns the bookmark to the member picked in the picker opened by openAssign.
+func (h *ComponentHandler) assignTo(s *discordgo.Session, i *discordgo.InteractionCreate, id CustomID) {
+	data := i.MessageComponentData()
+	if len(data.Values) == 0 {
+		return
+	}
+	assigneeID := data.Values[0]
+
+	content := ""
+	bookmark, ok := h.lookup(i, id)
+	switch {
+	case !ok:
+		content = "⚠️ I can't find this bookmark anymore."
+	case bookmark.Completed:
+		content = "✅ This bookmark is already done."
+	default:
+		if user := data.Resolved.Users[assigneeID]; user != nil && user.Bot {
+			content = "🤖 Bots can't read bookmarks; pick a member."
+			break
+		}
+
+		saved, err := s.ChannelMessage(bookmark.SavedChannelID, bookmark.SavedMessageID)
+		if err == nil {
+			err = h.assign(s, bookmark, assigneeID, saved)
+		}
+		if err != nil {
+			log.Printf("failed to assign bookmark %s: %v", bookmark.ID, err)
+			content = "⚠️ I couldn't assign this bookmark. Please try again."
+			break
+		}
+		content = fmt.Sprintf("📖 Assigned to <@%s>.", assigneeID)
+	}
+
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Content:         content,
+			Components:      []discordgo.MessageComponent{},
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	})
+	if err != nil {
+		log.Printf("failed to confirm assignment: %v", err)
+	}
+}
+
+// assign records assigneeID on bookmark, shows them on saved, the bookmark's post, and sends
+// them the post's reminder. An empty assigneeID hands the bookmark and its reminder back to
+// the saver.
+func (h *ComponentHandler) assign(s *discordgo.Session, bookmark store.Bookmark, assigneeID string, saved *discordgo.Message) error {
+	if err := h.bookmarks.Update(bookmark.ID, func(b *store.Bookmark) { b.AssigneeID = assigneeID }); err != nil {
+		return err
+	}
+
+	if _, err := s.ChannelMessageEditComplex(&discordgo.MessageEdit{
+		Channel:    saved.ChannelID,
+		ID:         saved.ID,
+		Embeds:     withEmbedField(saved.Embeds, assigneeFieldName, describeAssignee(assigneeID)),
+		Components: saved.Components,
+	}); err != nil {
+		return err
+	}
+
+	if h.reminders == nil {
+		return nil
+	}
+
+	// The saver's own reminder needs no rerouting.
+	if assigneeID == "" || assigneeID == bookmark.UserID {
+		h.reminders.Assign(bookmark.SavedMessageID, "", "")
+		return nil
+	}
+
+	dmChannel, err := s.UserChannelCreate(assigneeID)
+	if err != nil {
+		return fmt.Errorf("failed to create DM channel for reminder: %w", err)
+	}
+	h.reminders.Assign(bookmark.SavedMessageID, assigneeID, dmChannel.ID)
+
+	return nil
+}
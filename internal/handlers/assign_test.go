@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestWithTeamButtonsAddsOnce(t *testing.T) {
+	components := []discordgo.MessageComponent{
+		&discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			&discordgo.Button{Style: discordgo.SuccessButton, CustomID: NewCustomID(ActionComplete, "id").Encode()},
+		}},
+	}
+
+	once := withTeamButtons(components, "id")
+	twice := withTeamButtons(once, "id")
+
+	if len(twice) != 2 {
+		t.Fatalf("expected the bookmark row and one team row, got %+v", twice)
+	}
+	row := twice[1].(discordgo.ActionsRow)
+	if len(row.Components) != 4 || !isActionButton(row.Components[0], ActionVote) || !isActionButton(row.Components[2], ActionClaim) || !isActionButton(row.Components[3], ActionAssign) {
+		t.Fatalf("expected vote, claim and assign buttons, got %+v", row.Components)
+	}
+}
+
+func TestWithEmbedFieldSetsAndRemoves(t *testing.T) {
+	embeds := []*discordgo.MessageEmbed{{
+		Title:  "🔖 Smart Save",
+		Fields: []*discordgo.MessageEmbedField{{Name: "📍 Channel", Value: "#general"}},
+	}}
+
+	assigned := withEmbedField(embeds, assigneeFieldName, describeAssignee("alice"))
+	reassigned := withEmbedField(assigned, assigneeFieldName, describeAssignee("bob"))
+	unassigned := withEmbedField(reassigned, assigneeFieldName, describeAssignee(""))
+
+	if len(embeds[0].Fields) != 1 {
+		t.Fatalf("expected the original embeds to stay untouched")
+	}
+	if fields := reassigned[0].Fields; len(fields) != 2 || fields[1].Name != assigneeFieldName || fields[1].Value != "<@bob>" {
+		t.Fatalf("expected a single assignee field for bob, got %+v", fields)
+	}
+	if fields := unassigned[0].Fields; len(fields) != 1 || fields[0].Name != "📍 Channel" {
+		t.Fatalf("expected the assignee field to be removed, got %+v", fields)
+	}
+}
@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/example/discord-bookmark-manager/internal/ics"
+	"github.com/example/discord-bookmark-manager/internal/reminders"
+)
+
+// calendarFileName is the .ics attached to bookmarks with a reminder.
+const calendarFileName = "reminder.ics"
+
+// attachCalendar attaches the bookmark's reminder as an .ics file so it can be added to any
+// calendar. The event only carries what the bookmark itself shows.
+func attachCalendar(messageSend *discordgo.MessageSend, bookmarkID string, msg *discordgo.Message, pref *reminders.Preference, ctx saveContext) {
+	payload := reminders.Payload{
+		JumpURL:        ctx.jumpURL,
+		ChannelName:    ctx.channelName,
+		ContentSnippet: extractSnippet(msg),
+		Daily:          pref.Mode == reminders.ModeTimeOfDay,
+	}
+	event := payload.CalendarEvent(bookmarkID, ctx.schedule.Time)
+
+	messageSend.Files = append(messageSend.Files, &discordgo.File{
+		Name:        calendarFileName,
+		ContentType: "text/calendar; charset=utf-8",
+		Reader:      bytes.NewReader(ics.Calendar("Bookmark reminder", []ics.Event{event}, time.Now())),
+	})
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/example/discord-bookmark-manager/internal/reminders"
+)
+
+func TestTimeOfDayReminderRepeatsDaily(t *testing.T) {
+	pref, err := reminders.Parse("8:00")
+	if err != nil || pref.Mode != reminders.ModeTimeOfDay {
+		t.Fatalf("expected a time-of-day reminder, got %+v, %v", pref, err)
+	}
+	schedule, err := reminders.Next(pref, time.Date(2026, 3, 13, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Next returned error: %v", err)
+	}
+
+	messageSend := &discordgo.MessageSend{}
+	msg := &discordgo.Message{Content: "Standup notes"}
+	attachCalendar(messageSend, "bookmark", msg, pref, saveContext{channelName: "general", schedule: schedule})
+
+	if len(messageSend.Files) != 1 || messageSend.Files[0].Name != calendarFileName {
+		t.Fatalf("expected the reminder.ics attachment, got %+v", messageSend.Files)
+	}
+	data, err := io.ReadAll(messageSend.Files[0].Reader)
+	if err != nil {
+		t.Fatalf("reading attachment: %v", err)
+	}
+
+	body := string(data)
+	if strings.Count(body, "BEGIN:VEVENT") != 1 || !strings.Contains(body, "RRULE:FREQ=DAILY\r\n") {
+		t.Fatalf("expected a single daily event:\n%s", body)
+	}
+}
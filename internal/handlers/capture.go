@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/example/discord-bookmark-manager/internal/transcript"
+)
+
+// maxTranscriptMessages caps how many messages a single capture pulls from the API.
+const maxTranscriptMessages = 1000
+
+// resolveThreadID returns the thread started by msg, if any. Forum posts and threads share
+// their ID with the starter message, so reacting inside the thread is detected as well.
+func resolveThreadID(s *discordgo.Session, channelID string, msg *discordgo.Message) (string, bool) {
+	if msg.Thread != nil && msg.Thread.ID != "" {
+		return msg.Thread.ID, true
+	}
+
+	if msg.ID != channelID {
+		return "", false
+	}
+
+	channel, err := fetchChannel(s, channelID)
+	if err != nil {
+		log.Printf("failed to resolve thread channel: %v", err)
+		return "", false
+	}
+
+	if channel.IsThread() {
+		return channel.ID, true
+	}
+
+	return "", false
+}
+
+// fetchThreadMessages pages forward from the start of the thread and returns its messages,
+// oldest first, up to maxTranscriptMessages. truncated reports that later messages were left
+// out. The starter message is prepended when it lives in the parent channel.
+func fetchThreadMessages(s *discordgo.Session, threadID string, starter *discordgo.Message) (collected []*discordgo.Message, truncated bool, err error) {
+	if starter != nil && starter.ChannelID != threadID {
+		collected = append(collected, starter)
+	}
+
+	// Discord returns the messages after afterID newest first, so every page is sorted.
+	afterID := "0"
+	for !truncated {
+		page, err := s.ChannelMessages(threadID, 100, "", afterID, "")
+		if err != nil {
+			return nil, false, err
+		}
+		if len(page) == 0 {
+			break
+		}
+
+		sort.Slice(page, func(a, b int) bool {
+			return snowflakeLess(page[a].ID, page[b].ID)
+		})
+
+		for _, msg := range page {
+			// The thread starter placeholder only references the parent message.
+			if msg.Type == discordgo.MessageTypeThreadStarterMessage {
+				continue
+			}
+			if len(collected) == maxTranscriptMessages {
+				truncated = true
+				break
+			}
+			collected = append(collected, msg)
+		}
+
+		if len(page) < 100 {
+			break
+		}
+		afterID = page[len(page)-1].ID
+	}
+
+	return collected, truncated, nil
+}
+
+// captureThread renders the thread started by msg into a transcript. It reports false when
+// msg did not start a thread. The returned name is used for the attached files.
+func captureThread(s *discordgo.Session, channelID string, msg *discordgo.Message, guildID string) (*transcript.Transcript, string, bool) {
+	threadID, ok := resolveThreadID(s, channelID, msg)
+	if !ok {
+		return nil, "", false
+	}
+
+	messages, truncated, err := fetchThreadMessages(s, threadID, msg)
+	if err != nil {
+		log.Printf("failed to fetch thread messages: %v", err)
+		return nil, "", false
+	}
+
+	threadName := fetchChannelName(s, threadID)
+	capture := transcript.New(fmt.Sprintf("🧵 %s", threadName), buildThreadLink(guildID, threadID), messages)
+	capture.Truncated = truncated
+
+	return capture, fmt.Sprintf("thread-%s", threadID), true
+}
+
+// attachTranscript adds Markdown and HTML renderings of capture to messageSend and notes
+// them on the first embed.
+func attachTranscript(messageSend *discordgo.MessageSend, capture *transcript.Transcript, baseName string) {
+	messageSend.Files = append(messageSend.Files,
+		&discordgo.File{
+			Name:        baseName + ".md",
+			ContentType: "text/markdown; charset=utf-8",
+			Reader:      bytes.NewReader([]byte(capture.Markdown())),
+		},
+		&discordgo.File{
+			Name:        baseName + ".html",
+			ContentType: "text/html; charset=utf-8",
+			Reader:      bytes.NewReader([]byte(capture.HTML())),
+		},
+	)
+
+	if len(messageSend.Embeds) == 0 || messageSend.Embeds[0] == nil {
+		return
+	}
+
+	count := fmt.Sprintf("%d messages", len(capture.Messages))
+	if capture.Truncated {
+		count = fmt.Sprintf("truncated after %d messages", len(capture.Messages))
+	}
+	messageSend.Embeds[0].Fields = append(messageSend.Embeds[0].Fields, &discordgo.MessageEmbedField{
+		Name:  "🧵 Transcript",
+		Value: fmt.Sprintf("%s · %s (Markdown + HTML attached)", capture.Title, count),
+	})
+}
+
+func buildThreadLink(guildID, threadID string) string {
+	if guildID == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("https://discord.com/channels/%s/%s", guildID, threadID)
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// historySession serves a channel of count messages with IDs from 1000 up, newest first the
+// way Discord pages messages after an ID.
+func historySession(t *testing.T, count int) *discordgo.Session {
+	t.Helper()
+
+	s, _ := recordingSession(t, func(r *http.Request) (int, string) {
+		if _, id, ok := strings.Cut(r.URL.Path, "/messages/"); ok {
+			return http.StatusOK, `{"id": "` + id + `", "channel_id": "thread"}`
+		}
+
+		after, _ := strconv.Atoi(r.URL.Query().Get("after"))
+		var page []*discordgo.Message
+		for id := 1000; id < 1000+count && len(page) < 100; id++ {
+			if id > after {
+				page = append(page, &discordgo.Message{ID: strconv.Itoa(id), ChannelID: "thread"})
+			}
+		}
+		sort.Slice(page, func(a, b int) bool { return page[a].ID > page[b].ID })
+
+		body, err := json.Marshal(page)
+		if err != nil {
+			t.Fatalf("encoding page returned error: %v", err)
+		}
+		return http.StatusOK, string(body)
+	})
+
+	return s
+}
+
+func TestFetchThreadMessagesStartsAtTheBeginning(t *testing.T) {
+	s := historySession(t, maxTranscriptMessages+50)
+
+	messages, truncated, err := fetchThreadMessages(s, "thread", &discordgo.Message{ID: "999", ChannelID: "parent"})
+	if err != nil {
+		t.Fatalf("fetchThreadMessages returned error: %v", err)
+	}
+	if !truncated || len(messages) != maxTranscriptMessages {
+		t.Fatalf("expected %d messages and a truncation, got %d (truncated %v)", maxTranscriptMessages, len(messages), truncated)
+	}
+	if messages[0].ID != "999" || messages[1].ID != "1000" {
+		t.Fatalf("expected the starter and the oldest reply first, got %s and %s", messages[0].ID, messages[1].ID)
+	}
+}
+
+func TestFetchThreadMessagesKeepsShortThreads(t *testing.T) {
+	s := historySession(t, 150)
+
+	messages, truncated, err := fetchThreadMessages(s, "thread", nil)
+	if err != nil {
+		t.Fatalf("fetchThreadMessages returned error: %v", err)
+	}
+	if truncated || len(messages) != 150 {
+		t.Fatalf("expected all 150 messages, got %d (truncated %v)", len(messages), truncated)
+	}
+}
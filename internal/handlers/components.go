@@ -1,27 +1,59 @@
 package handlers
 
 import (
+	"fmt"
 	"log"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 
 	"github.com/example/discord-bookmark-manager/internal/reminders"
+	"github.com/example/discord-bookmark-manager/internal/store"
+	"github.com/example/discord-bookmark-manager/internal/vault"
 )
 
-// CompleteButtonID identifies the button that marks a lightweight bookmark as complete.
+// CompleteButtonID is the legacy static custom ID of the Done button. Bookmarks saved before
+// custom IDs were versioned still carry it.
 const CompleteButtonID = "bookmark_complete"
 
-// DeleteButtonID identifies the button that deletes a saved bookmark message.
+// DeleteButtonID is the legacy static custom ID of the Remove button.
 const DeleteButtonID = "bookmark_delete"
 
 // ComponentHandler processes interactions originating from message components.
 type ComponentHandler struct {
+	bookmarks *store.BookmarkStore
 	reminders *reminders.Service
+	vault     *vault.Vault
+	registry  *ComponentRegistry
+	// votesMu serializes votes so concurrent presses don't overwrite each other's score.
+	votesMu sync.Mutex
 }
 
 // NewComponentHandler constructs a component handler instance.
-func NewComponentHandler(reminders *reminders.Service) *ComponentHandler {
-	return &ComponentHandler{reminders: reminders}
+func NewComponentHandler(bookmarks *store.BookmarkStore, reminders *reminders.Service, notes *vault.Vault) *ComponentHandler {
+	h := &ComponentHandler{
+		bookmarks: bookmarks,
+		reminders: reminders,
+		vault:     notes,
+		registry:  NewComponentRegistry(),
+	}
+
+	h.registry.Register(ActionComplete, h.requireOwner(h.complete, accessReaders))
+	h.registry.Register(ActionDelete, h.requireOwner(h.delete, accessOwner))
+	h.registry.Register(ActionClaim, h.claim)
+	h.registry.Register(ActionVote, h.vote)
+	h.registry.Register(ActionAssign, h.requireOwner(h.openAssign, accessSavers))
+	h.registry.Register(ActionAssignTo, h.requireOwner(h.assignTo, accessSavers))
+
+	return h
+}
+
+// Registry exposes the component registry so other features can add actions.
+func (h *ComponentHandler) Registry() *ComponentRegistry {
+	return h.registry
 }
 
 // Handle reacts to button presses on bookmarked messages.
@@ -30,65 +62,328 @@ func (h *ComponentHandler) Handle(s *discordgo.Session, i *discordgo.Interaction
 		return
 	}
 
-	customID := i.MessageComponentData().CustomID
-	switch customID {
-	case CompleteButtonID:
-		// Mark as complete: dim the message and disable buttons
-		if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{Type: discordgo.InteractionResponseDeferredMessageUpdate}); err != nil {
-			log.Printf("failed to acknowledge complete interaction: %v", err)
+	h.registry.Dispatch(s, i)
+}
+
+// lookup resolves the bookmark a component refers to, falling back to the saved message for
+// legacy custom IDs.
+func (h *ComponentHandler) lookup(i *discordgo.InteractionCreate, id CustomID) (store.Bookmark, bool) {
+	if h.bookmarks == nil {
+		return store.Bookmark{}, false
+	}
+
+	if id.BookmarkID != "" {
+		if bookmark, ok := h.bookmarks.Get(id.BookmarkID); ok {
+			return bookmark, true
+		}
+	}
+
+	if i.Message != nil {
+		return h.bookmarks.BySavedMessage(i.Message.ID)
+	}
+
+	return store.Bookmark{}, false
+}
+
+func (h *ComponentHandler) complete(s *discordgo.Session, i *discordgo.InteractionCreate, id CustomID) {
+	// Mark as complete: dim the message and disable buttons
+	if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{Type: discordgo.InteractionResponseDeferredMessageUpdate}); err != nil {
+		log.Printf("failed to acknowledge complete interaction: %v", err)
+		return
+	}
+
+	bookmark, ok := h.lookup(i, id)
+	if ok && bookmark.Shared() {
+		var open bool
+		if bookmark, open = h.completeSaver(s, i, bookmark); open {
 			return
 		}
+	}
+
+	if i.Message != nil && len(i.Message.Embeds) > 0 {
+		embeds := i.Message.Embeds
+		if ok && bookmark.Shared() {
+			embeds = applySavedBy(embeds, bookmark.Savers)
+		}
+
+		// Remove all buttons
+		_, err := s.ChannelMessageEditComplex(&discordgo.MessageEdit{
+			Channel:    i.ChannelID,
+			ID:         i.Message.ID,
+			Embeds:     completeEmbeds(embeds),
+			Components: []discordgo.MessageComponent{},
+		})
+		if err != nil {
+			log.Printf("failed to update completed bookmark: %v", err)
+		}
+	}
 
-		if i.Message != nil && len(i.Message.Embeds) > 0 {
-			// Clone embeds and reduce opacity by making color dimmer
-			updatedEmbeds := make([]*discordgo.MessageEmbed, len(i.Message.Embeds))
-			for idx, embed := range i.Message.Embeds {
-				if embed == nil {
-					continue
-				}
-				cloned := cloneEmbedForComplete(embed)
-				// Add ✅ prefix to title to indicate completion
-				if cloned.Title != "" {
-					cloned.Title = "✅ " + cloned.Title
-				}
-				// Dim the color (make it grayer)
-				if cloned.Color != 0 {
-					cloned.Color = 0x808080 // Gray color
-				}
-				updatedEmbeds[idx] = cloned
+	if ok {
+		if err := h.bookmarks.Update(bookmark.ID, func(b *store.Bookmark) { b.Completed = true }); err != nil {
+			log.Printf("failed to mark bookmark complete: %v", err)
+		}
+		if bookmark.VaultPath != "" && h.vault.Enabled() {
+			if err := h.vault.Complete(bookmark.UserID, bookmark.VaultPath, time.Now()); err != nil {
+				log.Printf("failed to mark vault note done: %v", err)
+			}
+		}
+		// Archive last: messages in archived posts can no longer be edited.
+		if bookmark.ForumChannelID != "" {
+			if err := completeForumPost(s, bookmark); err != nil {
+				log.Printf("failed to close forum post: %v", err)
 			}
+		}
+	}
+
+	if h.reminders != nil {
+		h.reminders.Complete(i.Message.ID)
+	}
+}
+
+// completeSaver marks the member pressing Done on a shared post as done. Its assignee and
+// members with Manage Messages who did not save it mark everyone done. It returns the updated bookmark and true
+// while other savers are still reading, after refreshing the saved-by field.
+func (h *ComponentHandler) completeSaver(s *discordgo.Session, i *discordgo.InteractionCreate, bookmark store.Bookmark) (store.Bookmark, bool) {
+	userID := interactionUserID(i)
+	if !bookmark.SavedBy(userID) {
+		userID = ""
+	}
 
-			// Remove all buttons
-			_, err := s.ChannelMessageEditComplex(&discordgo.MessageEdit{
-				Channel:    i.ChannelID,
-				ID:         i.Message.ID,
-				Embeds:     updatedEmbeds,
-				Components: []discordgo.MessageComponent{},
-			})
-			if err != nil {
-				log.Printf("failed to update completed bookmark: %v", err)
+	updated, err := h.bookmarks.MarkSaverDone(bookmark.ID, userID)
+	if err != nil {
+		log.Printf("failed to mark saver done: %v", err)
+		return bookmark, true
+	}
+
+	if h.reminders != nil {
+		for _, saver := range updated.Savers {
+			if userID == "" || saver.UserID == userID {
+				h.reminders.Complete(saverReminderID(updated, saver.UserID))
 			}
 		}
+	}
+
+	if updated.Completed {
+		return updated, false
+	}
+
+	if _, err := s.ChannelMessageEditComplex(&discordgo.MessageEdit{
+		Channel: i.ChannelID,
+		ID:      i.Message.ID,
+		Embeds:  applySavedBy(i.Message.Embeds, updated.Savers),
+	}); err != nil {
+		log.Printf("failed to update shared bookmark: %v", err)
+	}
 
-		if h.reminders != nil {
-			h.reminders.Complete(i.Message.ID)
+	return updated, true
+}
+
+func (h *ComponentHandler) delete(s *discordgo.Session, i *discordgo.InteractionCreate, id CustomID) {
+	// Delete the message completely
+	if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{Type: discordgo.InteractionResponseDeferredMessageUpdate}); err != nil {
+		log.Printf("failed to acknowledge delete interaction: %v", err)
+		return
+	}
+
+	bookmark, ok := h.lookup(i, id)
+	if ok && bookmark.ForumChannelID != "" {
+		if err := deleteSavedBookmark(s, bookmark); err != nil {
+			log.Printf("failed to delete forum post: %v", err)
 		}
+	} else if err := s.ChannelMessageDelete(i.ChannelID, i.Message.ID); err != nil {
+		log.Printf("failed to delete bookmarked message: %v", err)
+	}
 
-	case DeleteButtonID:
-		// Delete the message completely
-		if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{Type: discordgo.InteractionResponseDeferredMessageUpdate}); err != nil {
-			log.Printf("failed to acknowledge delete interaction: %v", err)
+	if ok && bookmark.VaultPath != "" && h.vault.Enabled() {
+		if _, err := h.vault.Archive(bookmark.UserID, bookmark.VaultPath, bookmark.ID, time.Now()); err != nil {
+			log.Printf("failed to archive vault note: %v", err)
+		}
+	}
+
+	if ok {
+		if _, err := h.bookmarks.Delete(bookmark.ID); err != nil {
+			log.Printf("failed to forget deleted bookmark: %v", err)
+		}
+	}
+
+	if h.reminders != nil {
+		h.reminders.Cancel(i.Message.ID)
+		if ok {
+			for _, reminderID := range reminderIDs(bookmark)[1:] {
+				h.reminders.Cancel(reminderID)
+			}
+		}
+	}
+}
+
+// componentAccess says who besides the saver and moderators may run a bookmark action.
+type componentAccess int
+
+const (
+	// accessOwner limits an action to the member who first saved the bookmark.
+	accessOwner componentAccess = iota
+	// accessSavers extends it to everyone who saved a shared post.
+	accessSavers
+	// accessReaders further extends it to the post's assignee.
+	accessReaders
+)
+
+// requireOwner wraps action so it only runs for users allowed to change the bookmark, as
+// widened by access.
+func (h *ComponentHandler) requireOwner(action ComponentAction, access componentAccess) ComponentAction {
+	return func(s *discordgo.Session, i *discordgo.InteractionCreate, id CustomID) {
+		if !h.authorize(s, i, id, access) {
 			return
 		}
+		action(s, i, id)
+	}
+}
+
+// authorize checks that the user pressing a bookmark button saved it, or has Manage Messages
+// in the channel. Rejected users receive an ephemeral explanation.
+func (h *ComponentHandler) authorize(s *discordgo.Session, i *discordgo.InteractionCreate, id CustomID, access componentAccess) bool {
+	// Only the recipient can see buttons in a DM.
+	if i.GuildID == "" || i.Member == nil {
+		return true
+	}
+
+	userID := interactionUserID(i)
+
+	ownerID := ""
+	if bookmark, ok := h.lookup(i, id); ok {
+		ownerID = bookmark.UserID
+		if access >= accessSavers && userID != "" && bookmark.SavedBy(userID) {
+			return true
+		}
+		if access >= accessReaders && userID != "" && bookmark.AssigneeID == userID {
+			return true
+		}
+	}
+
+	if ownerID != "" && ownerID == userID {
+		return true
+	}
+
+	if i.Member.Permissions&discordgo.PermissionManageMessages != 0 {
+		return true
+	}
+
+	// Bookmarks saved before their savers were recorded only name the source message, so
+	// whoever reacted to it may change them.
+	if ownerID == "" && userID != "" && reactedToSource(s, i.Message, userID) {
+		return true
+	}
+
+	content := "🔒 I can't tell who saved this bookmark, so only members who reacted to the source message or have Manage Messages can change it."
+	if ownerID != "" {
+		content = fmt.Sprintf("🔒 Only <@%s>, who saved this bookmark, or members with Manage Messages can change it.", ownerID)
+	}
+
+	if err := respondEphemeral(s, i, content); err != nil {
+		log.Printf("failed to reject component interaction: %v", err)
+	}
+	return false
+}
+
+// sourceJumpLink matches the link to the source message on a bookmark embed.
+var sourceJumpLink = regexp.MustCompile(`https://discord\.com/channels/(?:\d+|@me)/(\d+)/(\d+)`)
+
+// reactedToSource reports whether userID reacted to the source message linked from the
+// bookmark msg.
+func reactedToSource(s *discordgo.Session, msg *discordgo.Message, userID string) bool {
+	if msg == nil {
+		return false
+	}
+
+	var link []string
+	for _, embed := range msg.Embeds {
+		if embed == nil {
+			continue
+		}
+		for _, field := range embed.Fields {
+			if field != nil && field.Name == "🔗 Source Message" {
+				link = sourceJumpLink.FindStringSubmatch(field.Value)
+			}
+		}
+	}
+	if link == nil {
+		return false
+	}
+	channelID, messageID := link[1], link[2]
 
-		if err := s.ChannelMessageDelete(i.ChannelID, i.Message.ID); err != nil {
-			log.Printf("failed to delete bookmarked message: %v", err)
+	id, err := strconv.ParseUint(userID, 10, 64)
+	if err != nil || id == 0 {
+		return false
+	}
+	source, err := s.ChannelMessage(channelID, messageID)
+	if err != nil {
+		log.Printf("failed to fetch bookmark source: %v", err)
+		return false
+	}
+
+	// Reactors are listed by user ID, so starting right before userID finds them in one request.
+	after := strconv.FormatUint(id-1, 10)
+	for _, reaction := range source.Reactions {
+		if reaction == nil || reaction.Emoji == nil {
+			continue
+		}
+		users, err := s.MessageReactions(channelID, messageID, reaction.Emoji.APIName(), 1, "", after)
+		if err != nil {
+			log.Printf("failed to list reactions on bookmark source: %v", err)
+			continue
+		}
+		if len(users) > 0 && users[0].ID == userID {
+			return true
 		}
+	}
+
+	return false
+}
 
-		if h.reminders != nil {
-			h.reminders.Cancel(i.Message.ID)
+// interactionUserID returns the ID of the member or user behind i.
+func interactionUserID(i *discordgo.InteractionCreate) string {
+	if i.Member != nil && i.Member.User != nil {
+		return i.Member.User.ID
+	}
+	if i.User != nil {
+		return i.User.ID
+	}
+
+	return ""
+}
+
+func respondEphemeral(s *discordgo.Session, i *discordgo.InteractionCreate, content string) error {
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content:         content,
+			Flags:           discordgo.MessageFlagsEphemeral,
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	})
+}
+
+// completeEmbeds returns copies of embeds styled as completed.
+func completeEmbeds(embeds []*discordgo.MessageEmbed) []*discordgo.MessageEmbed {
+	// Clone embeds and reduce opacity by making color dimmer
+	updatedEmbeds := make([]*discordgo.MessageEmbed, len(embeds))
+	for idx, embed := range embeds {
+		if embed == nil {
+			continue
 		}
+		cloned := cloneEmbedForComplete(embed)
+		// Add ✅ prefix to title to indicate completion
+		if cloned.Title != "" {
+			cloned.Title = "✅ " + cloned.Title
+		}
+		// Dim the color (make it grayer)
+		if cloned.Color != 0 {
+			cloned.Color = 0x808080 // Gray color
+		}
+		updatedEmbeds[idx] = cloned
 	}
+
+	return updatedEmbeds
 }
 
 func cloneEmbedForComplete(embed *discordgo.MessageEmbed) *discordgo.MessageEmbed {
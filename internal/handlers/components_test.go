@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/example/discord-bookmark-manager/internal/store"
+)
+
+func TestLegacyBookmarkAcceptsMemberWhoReacted(t *testing.T) {
+	s, calls := recordingSession(t, func(r *http.Request) (int, string) {
+		switch {
+		case strings.Contains(r.URL.Path, "/reactions/"):
+			// Only member 42 reacted; Discord lists reactors after the given user ID.
+			if r.URL.Query().Get("after") < "42" {
+				return http.StatusOK, `[{"id": "42"}]`
+			}
+			return http.StatusOK, `[]`
+		case strings.HasSuffix(r.URL.Path, "/channels/200/messages/300"):
+			return http.StatusOK, `{"id": "300", "channel_id": "200", "reactions": [{"count": 1, "emoji": {"name": "🔖"}}]}`
+		}
+		return http.StatusNoContent, ""
+	})
+
+	bookmarks, err := store.NewBookmarkStore("")
+	if err != nil {
+		t.Fatalf("NewBookmarkStore returned error: %v", err)
+	}
+	h := NewComponentHandler(bookmarks, nil, nil)
+
+	id, err := ParseCustomID(CompleteButtonID)
+	if err != nil {
+		t.Fatalf("ParseCustomID returned error: %v", err)
+	}
+	press := func(userID string) *discordgo.InteractionCreate {
+		return &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+			ID:      "interaction",
+			Token:   "token",
+			GuildID: "100",
+			Member:  &discordgo.Member{User: &discordgo.User{ID: userID}},
+			Message: &discordgo.Message{ID: "saved", Embeds: []*discordgo.MessageEmbed{{
+				Fields: []*discordgo.MessageEmbedField{{Name: "🔗 Source Message", Value: "[Open](https://discord.com/channels/100/200/300)"}},
+			}}},
+		}}
+	}
+
+	if !h.authorize(s, press("42"), id, accessReaders) {
+		t.Fatalf("expected the member who reacted to the source to be allowed")
+	}
+	if h.authorize(s, press("17"), id, accessReaders) {
+		t.Fatalf("expected a member who did not react to be rejected")
+	}
+
+	rejected := false
+	for _, call := range calls() {
+		if strings.HasPrefix(call, "POST /api/v9/interactions/interaction/token/callback") {
+			rejected = true
+		}
+	}
+	if !rejected {
+		t.Fatalf("expected the rejected member to be told why, got %v", calls())
+	}
+}
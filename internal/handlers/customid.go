@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	customIDPrefix = "bm"
+	// customIDVersion is the current encoding version. Legacy static IDs decode as version 0.
+	customIDVersion = 1
+	// maxCustomIDLength is the Discord limit for component custom IDs.
+	maxCustomIDLength = 100
+)
+
+const (
+	// ActionComplete marks a bookmark as done.
+	ActionComplete = "done"
+	// ActionDelete removes a saved bookmark message.
+	ActionDelete = "rm"
+	// ActionClaim assigns a channel post to the member pressing it, or unassigns them.
+	ActionClaim = "claim"
+	// ActionAssign opens a member picker for a channel post.
+	ActionAssign = "assign"
+	// ActionAssignTo assigns a channel post to the member picked in the member picker.
+	ActionAssignTo = "assignto"
+	// ActionVote toggles the member's vote on a channel post; its argument is up or down.
+	ActionVote = "vote"
+)
+
+// CustomID is the structured payload carried in a component custom ID. It encodes as
+// bm:<version>:<action>:<bookmark id>[:<arg>...].
+type CustomID struct {
+	Action     string
+	Version    int
+	BookmarkID string
+	Args       []string
+}
+
+// NewCustomID builds a current-version custom ID for action on bookmarkID.
+func NewCustomID(action, bookmarkID string, args ...string) CustomID {
+	return CustomID{
+		Action:     action,
+		Version:    customIDVersion,
+		BookmarkID: bookmarkID,
+		Args:       args,
+	}
+}
+
+// Encode renders the custom ID. Args are escaped so they may contain the separator.
+func (c CustomID) Encode() string {
+	parts := []string{customIDPrefix, strconv.Itoa(c.Version), c.Action, c.BookmarkID}
+	for _, arg := range c.Args {
+		parts = append(parts, url.QueryEscape(arg))
+	}
+
+	return strings.Join(parts, ":")
+}
+
+// Arg returns the argument at idx, or an empty string when it is missing.
+func (c CustomID) Arg(idx int) string {
+	if idx < 0 || idx >= len(c.Args) {
+		return ""
+	}
+
+	return c.Args[idx]
+}
+
+// ParseCustomID decodes raw into a CustomID. The static IDs used before versioning decode
+// with Version 0 and no bookmark ID.
+func ParseCustomID(raw string) (CustomID, error) {
+	switch raw {
+	case CompleteButtonID:
+		return CustomID{Action: ActionComplete}, nil
+	case DeleteButtonID:
+		return CustomID{Action: ActionDelete}, nil
+	}
+
+	if len(raw) > maxCustomIDLength {
+		return CustomID{}, errors.New("custom id is too long")
+	}
+
+	parts := strings.Split(raw, ":")
+	if len(parts) < 4 || parts[0] != customIDPrefix {
+		return CustomID{}, fmt.Errorf("unrecognised custom id %q", raw)
+	}
+
+	version, err := strconv.Atoi(parts[1])
+	if err != nil || version < 1 {
+		return CustomID{}, fmt.Errorf("invalid custom id version %q", parts[1])
+	}
+	if version > customIDVersion {
+		return CustomID{}, fmt.Errorf("unsupported custom id version %d", version)
+	}
+
+	id := CustomID{
+		Version:    version,
+		Action:     parts[2],
+		BookmarkID: parts[3],
+	}
+	if id.Action == "" {
+		return CustomID{}, errors.New("custom id is missing an action")
+	}
+
+	for _, arg := range parts[4:] {
+		decoded, err := url.QueryUnescape(arg)
+		if err != nil {
+			return CustomID{}, fmt.Errorf("invalid custom id argument: %w", err)
+		}
+		id.Args = append(id.Args, decoded)
+	}
+
+	return id, nil
+}
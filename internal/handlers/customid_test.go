@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCustomIDRoundTrip(t *testing.T) {
+	original := NewCustomID(ActionComplete, "0123456789abcdef", "user:123", "50%")
+
+	encoded := original.Encode()
+	if len(encoded) > maxCustomIDLength {
+		t.Fatalf("encoded custom id exceeds %d characters: %q", maxCustomIDLength, encoded)
+	}
+
+	decoded, err := ParseCustomID(encoded)
+	if err != nil {
+		t.Fatalf("ParseCustomID returned error: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, original) {
+		t.Fatalf("expected %+v, got %+v", original, decoded)
+	}
+	if decoded.Arg(0) != "user:123" || decoded.Arg(5) != "" {
+		t.Fatalf("unexpected args: %+v", decoded.Args)
+	}
+}
+
+func TestParseCustomIDAcceptsLegacyIDs(t *testing.T) {
+	cases := map[string]string{
+		CompleteButtonID: ActionComplete,
+		DeleteButtonID:   ActionDelete,
+	}
+
+	for raw, action := range cases {
+		decoded, err := ParseCustomID(raw)
+		if err != nil {
+			t.Fatalf("ParseCustomID(%q) returned error: %v", raw, err)
+		}
+		if decoded.Action != action || decoded.Version != 0 || decoded.BookmarkID != "" {
+			t.Fatalf("unexpected legacy decoding for %q: %+v", raw, decoded)
+		}
+	}
+}
+
+func TestParseCustomIDRejectsUnknownFormats(t *testing.T) {
+	for _, raw := range []string{"", "something_else", "bm:x:done:id", "bm:99:done:id", "bm:1::id"} {
+		if _, err := ParseCustomID(raw); err == nil {
+			t.Fatalf("expected ParseCustomID(%q) to fail", raw)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/example/discord-bookmark-manager/internal/outbox"
+	"github.com/example/discord-bookmark-manager/internal/reminders"
+	"github.com/example/discord-bookmark-manager/internal/store"
+)
+
+// deliveryMeta is what ReactionHandler needs to finish a save once the outbox has sent the
+// bookmark. It is persisted with the delivery so retries after a restart complete the same way.
+type deliveryMeta struct {
+	Bookmark store.Bookmark `json:"bookmark"`
+	// SaveID ties the targets of one save together. Only the first of them delivered runs
+	// the save's Reactions cleanup and Reminder.
+	SaveID    string           `json:"saveId,omitempty"`
+	Reactions []reactionRef    `json:"reactions,omitempty"`
+	Reminder  *pendingReminder `json:"reminder,omitempty"`
+}
+
+// followUpClaimTTL is how long a save remembers that its follow-up ran, well past the last
+// retry of its deliveries.
+const followUpClaimTTL = 24 * time.Hour
+
+// followUpClaims records which saves already ran their reaction cleanup and reminder. Claims
+// are kept in memory, so a retry restored after a restart may run them again.
+type followUpClaims struct {
+	mu      sync.Mutex
+	claimed map[string]time.Time
+}
+
+// claim reports whether key was not claimed before, and claims it.
+func (c *followUpClaims) claim(key string, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.claimed == nil {
+		c.claimed = make(map[string]time.Time)
+	}
+	for claimed, at := range c.claimed {
+		if now.Sub(at) > followUpClaimTTL {
+			delete(c.claimed, claimed)
+		}
+	}
+
+	if _, ok := c.claimed[key]; ok {
+		return false
+	}
+	c.claimed[key] = now
+	return true
+}
+
+// claimFollowUp returns the reactions and reminder the delivery of meta should handle: those
+// of its save that no earlier delivered target took.
+func (h *ReactionHandler) claimFollowUp(meta deliveryMeta) ([]reactionRef, *pendingReminder) {
+	if meta.SaveID == "" {
+		return meta.Reactions, meta.Reminder
+	}
+
+	now := time.Now()
+	reactions, reminder := meta.Reactions, meta.Reminder
+	if len(reactions) > 0 && !h.followUps.claim(meta.SaveID+":reactions", now) {
+		reactions = nil
+	}
+	if reminder != nil && !h.followUps.claim(meta.SaveID+":reminder", now) {
+		reminder = nil
+	}
+
+	return reactions, reminder
+}
+
+type pendingReminder struct {
+	When             time.Time `json:"when"`
+	RemoveOnComplete bool      `json:"removeOnComplete"`
+	JumpURL          string    `json:"jumpUrl"`
+	ChannelName      string    `json:"channelName"`
+	ContentSnippet   string    `json:"contentSnippet,omitempty"`
+	// Daily repeats reminders set for a time of day.
+	Daily bool `json:"daily,omitempty"`
+}
+
+// renderedTarget is one destination's bookmark, ready for the outbox.
+type renderedTarget struct {
+	messageSend *discordgo.MessageSend
+	meta        deliveryMeta
+	// channelID is the destination channel. It is empty for the user's DMs.
+	channelID string
+	// forum is set when the bookmark becomes a post in the forum channelID.
+	forum *outbox.ForumPost
+	// archive lists the source attachments uploaded onto the bookmark.
+	archive archiveResult
+}
+
+// enqueue hands a rendered bookmark to the outbox and reports whether it was delivered right
+// away.
+func (h *ReactionHandler) enqueue(userID, summary string, rendered *renderedTarget) bool {
+	message, err := outbox.NewMessage(rendered.messageSend)
+	if err != nil {
+		log.Printf("failed to prepare bookmark delivery: %v", err)
+		return false
+	}
+
+	encodedMeta, err := json.Marshal(rendered.meta)
+	if err != nil {
+		log.Printf("failed to encode bookmark delivery: %v", err)
+		return false
+	}
+
+	var tooLarge *outbox.Message
+	if len(rendered.archive.archived) > 0 {
+		fallback := withoutArchive(message, rendered.archive)
+		tooLarge = &fallback
+	}
+
+	return h.outbox.Enqueue(outbox.Delivery{
+		ID:              rendered.meta.Bookmark.ID,
+		UserID:          userID,
+		ChannelID:       rendered.channelID,
+		SourceGuildID:   rendered.meta.Bookmark.SourceGuildID,
+		SourceChannelID: rendered.meta.Bookmark.SourceChannelID,
+		Summary:         summary,
+		Message:         message,
+		TooLarge:        tooLarge,
+		Forum:           rendered.forum,
+		Meta:            encodedMeta,
+	})
+}
+
+// delivered records the bookmark, removes silent-save reactions and schedules the reminder
+// once the outbox has sent a bookmark. Deliveries outside Discord leave no bookmark to record.
+func (h *ReactionHandler) delivered(s *discordgo.Session, delivery outbox.Delivery, sent *discordgo.Message) {
+	var meta deliveryMeta
+	if err := json.Unmarshal(delivery.Meta, &meta); err != nil {
+		log.Printf("failed to decode bookmark delivery %s: %v", delivery.ID, err)
+		return
+	}
+	meta.Reactions, meta.Reminder = h.claimFollowUp(meta)
+
+	if delivery.Kind != "" {
+		for _, ref := range meta.Reactions {
+			removeReaction(s, ref)
+		}
+		if meta.Reminder != nil {
+			h.scheduleEmailReminder(s, delivery.UserID, meta.Reminder)
+		}
+		return
+	}
+
+	bookmark := meta.Bookmark
+	bookmark.SavedChannelID = sent.ChannelID
+	bookmark.SavedMessageID = sent.ID
+	if bookmark.SavedGuildID == "" {
+		// DM fallbacks land in a server thread.
+		bookmark.SavedGuildID = sent.GuildID
+	}
+	if h.bookmarks != nil {
+		if existing, duplicate := h.recordBookmark(bookmark); duplicate {
+			if err := deleteSavedBookmark(s, bookmark); err != nil {
+				log.Printf("failed to delete duplicate of shared bookmark %s: %v", existing.ID, err)
+			}
+			h.joinShared(s, bookmark.UserID, existing, meta.Reactions, meta.Reminder)
+			return
+		}
+	}
+
+	for _, ref := range meta.Reactions {
+		removeReaction(s, ref)
+	}
+
+	if meta.Reminder == nil || h.reminders == nil {
+		return
+	}
+
+	reminderChannelID := sent.ChannelID
+	if bookmark.Destination == store.DestinationChannel {
+		dmChannel, err := s.UserChannelCreate(bookmark.UserID)
+		if err != nil {
+			log.Printf("failed to create DM channel for reminder: %v", err)
+			return
+		}
+		reminderChannelID = dmChannel.ID
+	}
+
+	payload := reminderPayload(bookmark, bookmark.UserID, meta.Reminder)
+	payload.ChannelID = reminderChannelID
+	payload.Email = h.reminderEmail(bookmark.UserID)
+	h.reminders.Schedule(sent.ID, meta.Reminder.When, payload, meta.Reminder.RemoveOnComplete)
+}
+
+// recordBookmark stores a delivered bookmark. A channel post is first checked against the
+// posts already in its channel: when another member's save of the same message got there
+// while this one waited in the outbox, that post is returned and nothing is stored.
+func (h *ReactionHandler) recordBookmark(bookmark store.Bookmark) (store.Bookmark, bool) {
+	if bookmark.Destination == store.DestinationChannel && (bookmark.Capture == "" || bookmark.Capture == store.CaptureMessage) {
+		channelID := bookmark.SavedChannelID
+		if bookmark.ForumChannelID != "" {
+			channelID = bookmark.ForumChannelID
+		}
+
+		unlock := h.sharedLocks.lock(bookmark.SourceMessageID + ":" + channelID)
+		defer unlock()
+
+		if existing, ok := h.bookmarks.ByDestination(bookmark.SourceMessageID, channelID); ok && existing.ID != bookmark.ID {
+			return existing, true
+		}
+	}
+
+	if err := h.bookmarks.Add(bookmark); err != nil {
+		log.Printf("failed to record bookmark: %v", err)
+	}
+
+	return store.Bookmark{}, false
+}
+
+// reminderPayload describes the reminder userID gets for the delivered bookmark. The caller
+// picks the channel it is sent to.
+func reminderPayload(bookmark store.Bookmark, userID string, reminder *pendingReminder) reminders.Payload {
+	return reminders.Payload{
+		UserID:          userID,
+		GuildID:         bookmark.SourceGuildID,
+		SourceChannelID: bookmark.SourceChannelID,
+		JumpURL:         reminder.JumpURL,
+		BookmarkURL:     buildJumpLink(bookmark.SavedGuildID, bookmark.SavedChannelID, bookmark.SavedMessageID),
+		ChannelName:     reminder.ChannelName,
+		ContentSnippet:  reminder.ContentSnippet,
+		Daily:           reminder.Daily,
+	}
+}
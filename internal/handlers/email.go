@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/example/discord-bookmark-manager/internal/email"
+	"github.com/example/discord-bookmark-manager/internal/outbox"
+	"github.com/example/discord-bookmark-manager/internal/reminders"
+	"github.com/example/discord-bookmark-manager/internal/store"
+)
+
+// emailKind is the outbox kind of email deliveries.
+const emailKind = "email"
+
+// markdownLink matches the [label](url) links the bookmark layouts use in embed fields.
+var markdownLink = regexp.MustCompile(`\[([^\]]+)\]\((https?://[^)\s]+)\)`)
+
+// buildEmailBookmark renders msg with the layout for target.Mode as an email to address.
+func buildEmailBookmark(address string, event *discordgo.MessageReactionAdd, target store.DestinationTarget, msg *discordgo.Message, ctx saveContext) (email.Message, bool) {
+	color := target.Color
+	if !target.HasColor {
+		color = defaultEmbedColor
+	}
+
+	rendered := buildBookmark(store.NewBookmarkID(), target.Mode, msg, ctx.channelName, ctx.jumpURL, color, &event.Emoji, ctx.schedule)
+	if rendered == nil {
+		return email.Message{}, false
+	}
+
+	return email.Message{
+		To:      address,
+		Subject: fmt.Sprintf("%s Bookmark from #%s: %s", reactionKeyLabel(&event.Emoji), ctx.channelName, forumTitle(msg, ctx.channelName)),
+		Text:    emailText(rendered.Embeds),
+		HTML:    emailHTML(rendered.Embeds, color),
+	}, true
+}
+
+// reactionKeyLabel is the emoji as shown in subjects; custom emoji fall back to 🔖.
+func reactionKeyLabel(emoji *discordgo.Emoji) string {
+	if emoji == nil || emoji.ID != "" || emoji.Name == "" {
+		return "🔖"
+	}
+
+	return emoji.Name
+}
+
+func emailText(embeds []*discordgo.MessageEmbed) string {
+	var sections []string
+	for _, embed := range embeds {
+		if embed == nil {
+			continue
+		}
+
+		var lines []string
+		if embed.Title != "" {
+			lines = append(lines, embed.Title, strings.Repeat("=", len([]rune(embed.Title))))
+		}
+		if embed.Description != "" {
+			lines = append(lines, embed.Description, "")
+		}
+		for _, field := range embed.Fields {
+			if field != nil {
+				lines = append(lines, fmt.Sprintf("%s: %s", field.Name, textLinks(field.Value)))
+			}
+		}
+		if embed.URL != "" {
+			lines = append(lines, embed.URL)
+		}
+		if embed.Image != nil && embed.Image.URL != "" {
+			lines = append(lines, "Image: "+embed.Image.URL)
+		}
+		sections = append(sections, strings.TrimSpace(strings.Join(lines, "\n")))
+	}
+
+	return strings.Join(sections, "\n\n")
+}
+
+func emailHTML(embeds []*discordgo.MessageEmbed, color int) string {
+	var body strings.Builder
+	body.WriteString(`<!DOCTYPE html><html><body style="font-family: sans-serif;">` + "\n")
+	for _, embed := range embeds {
+		if embed == nil {
+			continue
+		}
+
+		embedColor := embed.Color
+		if embedColor == 0 {
+			embedColor = color
+		}
+		fmt.Fprintf(&body, `<div style="border-left: 4px solid #%06x; padding: 4px 12px; margin-bottom: 16px;">`+"\n", embedColor)
+		if embed.Title != "" {
+			title := html.EscapeString(embed.Title)
+			if embed.URL != "" {
+				title = fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(embed.URL), title)
+			}
+			fmt.Fprintf(&body, "<h2>%s</h2>\n", title)
+		}
+		if embed.Description != "" {
+			fmt.Fprintf(&body, `<p style="white-space: pre-wrap;">%s</p>`+"\n", htmlLinks(embed.Description))
+		}
+		if len(embed.Fields) > 0 {
+			body.WriteString("<table>\n")
+			for _, field := range embed.Fields {
+				if field != nil {
+					fmt.Fprintf(&body, `<tr><th align="left" valign="top">%s</th><td style="white-space: pre-wrap;">%s</td></tr>`+"\n", html.EscapeString(field.Name), htmlLinks(field.Value))
+				}
+			}
+			body.WriteString("</table>\n")
+		}
+		if embed.Image != nil && embed.Image.URL != "" {
+			fmt.Fprintf(&body, `<p><img src="%s" alt="" style="max-width: 100%%;"></p>`+"\n", html.EscapeString(embed.Image.URL))
+		}
+		body.WriteString("</div>\n")
+	}
+	body.WriteString("</body></html>")
+
+	return body.String()
+}
+
+// textLinks rewrites [label](url) as "label: url".
+func textLinks(value string) string {
+	return markdownLink.ReplaceAllString(value, "$1: $2")
+}
+
+// htmlLinks escapes value and turns [label](url) into anchors.
+func htmlLinks(value string) string {
+	var out strings.Builder
+	last := 0
+	for _, match := range markdownLink.FindAllStringSubmatchIndex(value, -1) {
+		out.WriteString(html.EscapeString(value[last:match[0]]))
+		fmt.Fprintf(&out, `<a href="%s">%s</a>`, html.EscapeString(value[match[4]:match[5]]), html.EscapeString(value[match[2]:match[3]]))
+		last = match[1]
+	}
+	out.WriteString(html.EscapeString(value[last:]))
+
+	return out.String()
+}
+
+// enqueueEmail hands message to the outbox and reports whether it was delivered right away.
+// Once the relay accepts it the reactions in meta are removed and, when the email took the
+// save's reminder, the reminder is scheduled. If the relay never accepts it the user is told
+// by DM.
+func (h *ReactionHandler) enqueueEmail(userID, summary string, message email.Message, meta deliveryMeta) bool {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("failed to encode email delivery: %v", err)
+		return false
+	}
+
+	encodedMeta, err := json.Marshal(meta)
+	if err != nil {
+		log.Printf("failed to encode email delivery: %v", err)
+		return false
+	}
+
+	return h.outbox.Enqueue(outbox.Delivery{
+		UserID:  userID,
+		Kind:    emailKind,
+		Payload: payload,
+		Summary: summary,
+		Meta:    encodedMeta,
+	})
+}
+
+// sendEmail makes one attempt at an outbox email delivery.
+func (h *ReactionHandler) sendEmail(delivery outbox.Delivery) error {
+	var message email.Message
+	if err := json.Unmarshal(delivery.Payload, &message); err != nil {
+		return &outbox.RetryError{Err: fmt.Errorf("decode email delivery: %w", err), Permanent: true}
+	}
+
+	if retryable, err := h.mailer.Attempt(message); err != nil {
+		return &outbox.RetryError{Err: err, Permanent: !retryable}
+	}
+
+	return nil
+}
+
+// scheduleEmailReminder schedules the reminder of a save whose only destinations are outside
+// Discord. It goes to the user's email when they asked for email reminders and to their DMs
+// otherwise, or if the email fails.
+func (h *ReactionHandler) scheduleEmailReminder(s *discordgo.Session, userID string, reminder *pendingReminder) {
+	if h.reminders == nil {
+		return
+	}
+
+	payload := reminders.Payload{
+		UserID:         userID,
+		JumpURL:        reminder.JumpURL,
+		ChannelName:    reminder.ChannelName,
+		ContentSnippet: reminder.ContentSnippet,
+		Email:          h.reminderEmail(userID),
+		Daily:          reminder.Daily,
+	}
+	if dmChannel, err := s.UserChannelCreate(userID); err != nil {
+		log.Printf("failed to create DM channel for reminder: %v", err)
+		if payload.Email == "" {
+			return
+		}
+	} else {
+		payload.ChannelID = dmChannel.ID
+	}
+
+	h.reminders.Schedule(store.NewBookmarkID(), reminder.When, payload, reminder.RemoveOnComplete)
+}
+
+// reminderEmail returns the verified address userID wants reminders sent to, if any.
+func (h *ReactionHandler) reminderEmail(userID string) string {
+	if !h.mailer.Enabled() {
+		return ""
+	}
+
+	settings, ok := h.store.Email(userID)
+	if !ok || !settings.Verified || !settings.Reminders {
+		return ""
+	}
+
+	return settings.Address
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/example/discord-bookmark-manager/internal/email"
+	"github.com/example/discord-bookmark-manager/internal/outbox"
+)
+
+func TestHTMLLinksEscapesAndConvertsLinks(t *testing.T) {
+	got := htmlLinks(`<b>hi</b> [Open](https://discord.com/channels/1/2/3?a=1&b=2)`)
+	want := `&lt;b&gt;hi&lt;/b&gt; <a href="https://discord.com/channels/1/2/3?a=1&amp;b=2">Open</a>`
+	if got != want {
+		t.Fatalf("htmlLinks() = %q, want %q", got, want)
+	}
+}
+
+func TestEmailTextMirrorsEmbeds(t *testing.T) {
+	text := emailText([]*discordgo.MessageEmbed{{
+		Title:       "🔖 Smart Save",
+		Description: "Ship it",
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "📺 Channel", Value: "#general"},
+			{Name: "🔗 Source Message", Value: "[Open](https://discord.com/channels/1/2/3)"},
+		},
+		Image: &discordgo.MessageEmbedImage{URL: "https://cdn.example/a.png"},
+	}})
+
+	for _, want := range []string{
+		"🔖 Smart Save\n",
+		"Ship it\n",
+		"📺 Channel: #general\n",
+		"🔗 Source Message: Open: https://discord.com/channels/1/2/3\n",
+		"Image: https://cdn.example/a.png",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text is missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestEmailDeliveriesWaitInOutbox(t *testing.T) {
+	// A closed port makes the relay unreachable, a temporary failure.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	path := filepath.Join(t.TempDir(), "outbox.json")
+	deliveries, err := outbox.NewService(nil, path)
+	if err != nil {
+		t.Fatalf("NewService returned error: %v", err)
+	}
+	t.Cleanup(deliveries.Close)
+
+	mailer := email.NewSender(email.Config{Host: "127.0.0.1", Port: port, From: "bot@example.com"})
+	h := NewReactionHandler(nil, nil, nil, nil, nil, deliveries, nil, nil, mailer)
+
+	started := time.Now()
+	if h.enqueueEmail("user", "a message in #general", email.Message{To: "reader@example.com", Subject: "Bookmark"}, deliveryMeta{}) {
+		t.Fatalf("expected the unreachable relay not to count as delivered")
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Fatalf("expected a single attempt, enqueue took %s", elapsed)
+	}
+	deliveries.Close()
+
+	reloaded, err := outbox.NewService(nil, path)
+	if err != nil {
+		t.Fatalf("reloading outbox returned error: %v", err)
+	}
+	if reloaded.Pending() != 1 {
+		t.Fatalf("expected the email to survive a restart, got %d pending", reloaded.Pending())
+	}
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/example/discord-bookmark-manager/internal/store"
+)
+
+// fallbackThreadArchiveMinutes keeps the fallback thread open for a week between deliveries.
+const fallbackThreadArchiveMinutes = 10080
+
+// DMFallback finds somewhere to deliver bookmarks and reminders for users whose DMs are
+// closed to the bot.
+type DMFallback struct {
+	store *store.EmojiStore
+	// mu serialises thread creation so concurrent deliveries share one thread.
+	mu sync.Mutex
+}
+
+// NewDMFallback constructs a DMFallback backed by the users' stored fallback settings.
+func NewDMFallback(store *store.EmojiStore) *DMFallback {
+	return &DMFallback{store: store}
+}
+
+// Resolve returns the private thread for userID in guildID, creating it on first use. The
+// thread is created in the channel the user picked for the server, or next to channelID.
+func (f *DMFallback) Resolve(s *discordgo.Session, userID, guildID, channelID string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	settings := f.store.DMFallback(userID)
+	if settings.Mode == store.DMFallbackNone {
+		return "", errors.New("the user turned the DM fallback off")
+	}
+	if guildID == "" {
+		return "", errors.New("the bookmark has no source server to fall back to")
+	}
+
+	if threadID := settings.Threads[guildID]; threadID != "" {
+		if reopenThread(s, threadID) {
+			return threadID, nil
+		}
+	}
+
+	parentID := settings.Channels[guildID]
+	if parentID == "" {
+		parentID = channelID
+	}
+	parent, err := fetchChannel(s, parentID)
+	if err != nil {
+		return "", fmt.Errorf("resolve fallback channel: %w", err)
+	}
+	if parent.IsThread() {
+		if parent, err = fetchChannel(s, parent.ParentID); err != nil {
+			return "", fmt.Errorf("resolve fallback channel: %w", err)
+		}
+	}
+	if parent.Type != discordgo.ChannelTypeGuildText {
+		return "", fmt.Errorf("private threads cannot be created in #%s", parent.Name)
+	}
+
+	thread, err := s.ThreadStartComplex(parent.ID, &discordgo.ThreadStart{
+		Name:                fallbackThreadName(s, userID),
+		AutoArchiveDuration: fallbackThreadArchiveMinutes,
+		Type:                discordgo.ChannelTypeGuildPrivateThread,
+		Invitable:           false,
+	})
+	if err != nil {
+		return "", fmt.Errorf("create fallback thread: %w", err)
+	}
+	if err := s.ThreadMemberAdd(thread.ID, userID); err != nil {
+		return "", fmt.Errorf("add user to fallback thread: %w", err)
+	}
+
+	if err := f.store.UpdateDMFallback(userID, func(fallback *store.DMFallback) {
+		fallback.Threads[guildID] = thread.ID
+	}); err != nil {
+		log.Printf("failed to remember fallback thread: %v", err)
+	}
+
+	if !settings.Notified {
+		explainClosedDMs(s, thread.ID, userID)
+		if err := f.store.UpdateDMFallback(userID, func(fallback *store.DMFallback) {
+			fallback.Notified = true
+		}); err != nil {
+			log.Printf("failed to remember DM fallback notice: %v", err)
+		}
+	}
+
+	return thread.ID, nil
+}
+
+// reopenThread reports whether threadID still exists, unarchiving it when needed.
+func reopenThread(s *discordgo.Session, threadID string) bool {
+	thread, err := fetchChannel(s, threadID)
+	if err != nil {
+		return false
+	}
+	if thread.ThreadMetadata == nil || !thread.ThreadMetadata.Archived {
+		return true
+	}
+
+	archived := false
+	if _, err := s.ChannelEdit(threadID, &discordgo.ChannelEdit{Archived: &archived}); err != nil {
+		log.Printf("failed to unarchive fallback thread: %v", err)
+		return false
+	}
+
+	return true
+}
+
+func fallbackThreadName(s *discordgo.Session, userID string) string {
+	user, err := s.User(userID)
+	if err != nil || user.Username == "" {
+		return "📚 Bookmarks"
+	}
+
+	return fmt.Sprintf("📚 Bookmarks for %s", user.Username)
+}
+
+// explainClosedDMs tells the user, once, why their bookmarks arrive in a thread and how to
+// get them back in DMs.
+func explainClosedDMs(s *discordgo.Session, threadID, userID string) {
+	_, err := s.ChannelMessageSendComplex(threadID, &discordgo.MessageSend{
+		Content: fmt.Sprintf("<@%s>", userID),
+		Embeds: []*discordgo.MessageEmbed{{
+			Title: "📭 Your DMs are closed",
+			Description: "I couldn't send your bookmark by DM, so it goes to this private thread instead. " +
+				"To get bookmarks and reminders in your DMs again, open this server's **Privacy Settings** and turn on " +
+				"**Direct Messages**. Use `/dm-fallback` to choose a different channel for this thread or to turn the fallback off.",
+			Color: 0xFEE75C,
+		}},
+		AllowedMentions: &discordgo.MessageAllowedMentions{Users: []string{userID}},
+	})
+	if err != nil {
+		log.Printf("failed to explain closed DMs: %v", err)
+	}
+}
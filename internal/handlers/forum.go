@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/example/discord-bookmark-manager/internal/store"
+)
+
+const (
+	// maxForumTitleLength is the Discord limit for thread names.
+	maxForumTitleLength = 100
+	// maxAppliedTags is the Discord limit for tags on one forum post.
+	maxAppliedTags = 5
+	// forumDoneTagName is the tag applied to a forum post when its bookmark is marked done.
+	forumDoneTagName = "done"
+)
+
+// forumTitle derives a post title from the first line of msg, falling back to embed titles,
+// attachment names and finally the source channel.
+func forumTitle(msg *discordgo.Message, channelName string) string {
+	candidates := []string{msg.Content}
+	for _, embed := range msg.Embeds {
+		if embed != nil {
+			candidates = append(candidates, embed.Title, embed.Description)
+		}
+	}
+	for _, attachment := range msg.Attachments {
+		if attachment != nil {
+			candidates = append(candidates, attachment.Filename)
+		}
+	}
+
+	for _, candidate := range candidates {
+		for _, line := range strings.Split(candidate, "\n") {
+			title := strings.Join(strings.Fields(line), " ")
+			if title == "" {
+				continue
+			}
+			if utf8.RuneCountInString(title) > maxForumTitleLength {
+				title = string([]rune(title)[:maxForumTitleLength-1]) + "…"
+			}
+			return title
+		}
+	}
+
+	return fmt.Sprintf("Bookmark from #%s", channelName)
+}
+
+// forumTagsForEmoji returns the forum tags whose emoji matches the reaction emoji.
+func forumTagsForEmoji(forum *discordgo.Channel, emoji *discordgo.Emoji) []string {
+	var tags []string
+	for _, tag := range forum.AvailableTags {
+		matches := false
+		if emoji.ID != "" {
+			matches = tag.EmojiID == emoji.ID
+		} else {
+			matches = tag.EmojiID == "" && tag.EmojiName != "" && tag.EmojiName == emoji.Name
+		}
+		if matches && tag.ID != "" {
+			tags = append(tags, tag.ID)
+		}
+		if len(tags) == maxAppliedTags {
+			break
+		}
+	}
+
+	return tags
+}
+
+// forumDoneTag returns the forum's "done" tag, matched by name or a ✅ emoji.
+func forumDoneTag(forum *discordgo.Channel) (string, bool) {
+	for _, tag := range forum.AvailableTags {
+		if strings.EqualFold(strings.TrimSpace(tag.Name), forumDoneTagName) || tag.EmojiName == "✅" {
+			return tag.ID, tag.ID != ""
+		}
+	}
+
+	return "", false
+}
+
+// completeForumPost applies the forum's done tag to the bookmark's post and archives it.
+func completeForumPost(s *discordgo.Session, bookmark store.Bookmark) error {
+	thread, err := fetchChannel(s, bookmark.SavedChannelID)
+	if err != nil {
+		return fmt.Errorf("resolve forum post: %w", err)
+	}
+
+	tags := append([]string(nil), thread.AppliedTags...)
+	forum, err := fetchChannel(s, bookmark.ForumChannelID)
+	if err != nil {
+		log.Printf("failed to resolve forum %s for done tag: %v", bookmark.ForumChannelID, err)
+	} else if doneTag, ok := forumDoneTag(forum); ok {
+		tags = withTag(tags, doneTag)
+	}
+
+	archived := true
+	_, err = s.ChannelEdit(thread.ID, &discordgo.ChannelEdit{
+		AppliedTags: &tags,
+		Archived:    &archived,
+	})
+	return err
+}
+
+// withTag adds tag to tags, dropping the oldest tag when the post is already at the limit.
+func withTag(tags []string, tag string) []string {
+	for _, existing := range tags {
+		if existing == tag {
+			return tags
+		}
+	}
+
+	if len(tags) >= maxAppliedTags {
+		tags = tags[len(tags)-maxAppliedTags+1:]
+	}
+
+	return append(tags, tag)
+}
+
+// deleteSavedBookmark removes the saved copy of bookmark: the whole post for forum bookmarks,
+// otherwise the saved message.
+func deleteSavedBookmark(s *discordgo.Session, bookmark store.Bookmark) error {
+	if bookmark.ForumChannelID != "" {
+		_, err := s.ChannelDelete(bookmark.SavedChannelID)
+		return err
+	}
+
+	return s.ChannelMessageDelete(bookmark.SavedChannelID, bookmark.SavedMessageID)
+}
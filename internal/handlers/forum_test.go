@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestForumTitleUsesFirstContentLine(t *testing.T) {
+	msg := &discordgo.Message{Content: "\n  Read   this RFC  \nsecond line"}
+	if title := forumTitle(msg, "general"); title != "Read this RFC" {
+		t.Fatalf("unexpected title %q", title)
+	}
+}
+
+func TestForumTitleTruncatesAndFallsBack(t *testing.T) {
+	long := &discordgo.Message{Content: strings.Repeat("é", 150)}
+	title := forumTitle(long, "general")
+	if got := len([]rune(title)); got != maxForumTitleLength || !strings.HasSuffix(title, "…") {
+		t.Fatalf("expected a truncated title of %d runes, got %d: %q", maxForumTitleLength, got, title)
+	}
+
+	attachmentOnly := &discordgo.Message{Attachments: []*discordgo.MessageAttachment{{Filename: "diagram.png"}}}
+	if title := forumTitle(attachmentOnly, "general"); title != "diagram.png" {
+		t.Fatalf("expected the attachment name, got %q", title)
+	}
+
+	if title := forumTitle(&discordgo.Message{}, "general"); title != "Bookmark from #general" {
+		t.Fatalf("expected the channel fallback, got %q", title)
+	}
+}
+
+func TestForumTagsMatchReactionEmoji(t *testing.T) {
+	forum := &discordgo.Channel{AvailableTags: []discordgo.ForumTag{
+		{ID: "read", Name: "to read", EmojiName: "📣"},
+		{ID: "custom", Name: "party", EmojiID: "42", EmojiName: "party"},
+		{ID: "done", Name: "Done"},
+	}}
+
+	if tags := forumTagsForEmoji(forum, &discordgo.Emoji{Name: "📣"}); len(tags) != 1 || tags[0] != "read" {
+		t.Fatalf("expected the unicode tag, got %v", tags)
+	}
+	if tags := forumTagsForEmoji(forum, &discordgo.Emoji{Name: "party", ID: "42"}); len(tags) != 1 || tags[0] != "custom" {
+		t.Fatalf("expected the custom emoji tag, got %v", tags)
+	}
+	if tags := forumTagsForEmoji(forum, &discordgo.Emoji{Name: "party"}); len(tags) != 0 {
+		t.Fatalf("expected no match for a unicode name of a custom emoji tag, got %v", tags)
+	}
+
+	if doneTag, ok := forumDoneTag(forum); !ok || doneTag != "done" {
+		t.Fatalf("expected the done tag, got %q", doneTag)
+	}
+}
+
+func TestWithTagRespectsLimit(t *testing.T) {
+	tags := withTag([]string{"a", "b", "c", "d", "e"}, "done")
+	if len(tags) != maxAppliedTags || tags[len(tags)-1] != "done" || tags[0] != "b" {
+		t.Fatalf("expected the oldest tag to make room, got %v", tags)
+	}
+
+	if again := withTag(tags, "done"); len(again) != len(tags) {
+		t.Fatalf("expected no duplicate tag, got %v", again)
+	}
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import "sync"
+
+// keyedMutex serializes work per key, such as a message ID, without making unrelated keys
+// wait on each other. The zero value is ready to use.
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*keyedLock
+}
+
+type keyedLock struct {
+	sync.Mutex
+	// holders counts the goroutines holding or waiting for the lock, so idle keys are
+	// forgotten.
+	holders int
+}
+
+// lock locks key and returns the function that unlocks it.
+func (k *keyedMutex) lock(key string) func() {
+	k.mu.Lock()
+	if k.locks == nil {
+		k.locks = make(map[string]*keyedLock)
+	}
+	entry, ok := k.locks[key]
+	if !ok {
+		entry = &keyedLock{}
+		k.locks[key] = entry
+	}
+	entry.holders++
+	k.mu.Unlock()
+
+	entry.Lock()
+	return func() {
+		entry.Unlock()
+
+		k.mu.Lock()
+		entry.holders--
+		if entry.holders == 0 {
+			delete(k.locks, key)
+		}
+		k.mu.Unlock()
+	}
+}
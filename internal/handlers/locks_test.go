@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKeyedMutexSerializesPerKey(t *testing.T) {
+	var locks keyedMutex
+	unlock := locks.lock("a")
+
+	// Another key is not held up.
+	locks.lock("b")()
+
+	done := make(chan struct{})
+	go func() {
+		locks.lock("a")()
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatalf("expected the second lock of the same key to wait")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	unlock()
+	<-done
+	if len(locks.locks) != 0 {
+		t.Fatalf("expected idle keys to be forgotten, got %d", len(locks.locks))
+	}
+}
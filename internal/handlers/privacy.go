@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// audienceCheck describes whether forwarding from one channel to another widens who can read
+// the content.
+type audienceCheck struct {
+	broader bool
+	reason  string
+}
+
+// computePermissions returns the effective permissions of a member with roleIDs in channel,
+// applying role permissions and channel overwrites in the order Discord does.
+func computePermissions(guild *discordgo.Guild, channel *discordgo.Channel, userID string, roleIDs []string) int64 {
+	if guild.OwnerID != "" && guild.OwnerID == userID {
+		return discordgo.PermissionAll
+	}
+
+	roles := make(map[string]*discordgo.Role, len(guild.Roles))
+	for _, role := range guild.Roles {
+		roles[role.ID] = role
+	}
+
+	var perms int64
+	if everyone, ok := roles[guild.ID]; ok {
+		perms = everyone.Permissions
+	}
+	for _, roleID := range roleIDs {
+		if role, ok := roles[roleID]; ok {
+			perms |= role.Permissions
+		}
+	}
+
+	if perms&discordgo.PermissionAdministrator != 0 {
+		return discordgo.PermissionAll
+	}
+
+	memberRoles := make(map[string]bool, len(roleIDs))
+	for _, roleID := range roleIDs {
+		memberRoles[roleID] = true
+	}
+
+	// @everyone overwrite first, then all role overwrites together, then the member overwrite.
+	for _, overwrite := range channel.PermissionOverwrites {
+		if overwrite.Type == discordgo.PermissionOverwriteTypeRole && overwrite.ID == guild.ID {
+			perms &^= overwrite.Deny
+			perms |= overwrite.Allow
+		}
+	}
+
+	var roleAllow, roleDeny int64
+	for _, overwrite := range channel.PermissionOverwrites {
+		if overwrite.Type == discordgo.PermissionOverwriteTypeRole && memberRoles[overwrite.ID] {
+			roleAllow |= overwrite.Allow
+			roleDeny |= overwrite.Deny
+		}
+	}
+	perms &^= roleDeny
+	perms |= roleAllow
+
+	for _, overwrite := range channel.PermissionOverwrites {
+		if overwrite.Type == discordgo.PermissionOverwriteTypeMember && overwrite.ID == userID {
+			perms &^= overwrite.Deny
+			perms |= overwrite.Allow
+		}
+	}
+
+	return perms
+}
+
+func canView(guild *discordgo.Guild, channel *discordgo.Channel, userID string, roleIDs []string) bool {
+	return computePermissions(guild, channel, userID, roleIDs)&discordgo.PermissionViewChannel != 0
+}
+
+// compareAudience reports whether some principal can read destination but not source. It
+// checks @everyone, every role on its own, and every member with an overwrite on either
+// channel using their actual roles.
+func compareAudience(guild *discordgo.Guild, source, destination *discordgo.Channel, memberRoles func(userID string) ([]string, bool)) audienceCheck {
+	if !canView(guild, source, "", nil) && canView(guild, destination, "", nil) {
+		return audienceCheck{broader: true, reason: "everyone in the server can read the destination, but not the source channel"}
+	}
+
+	for _, role := range guild.Roles {
+		if role.ID == guild.ID {
+			continue
+		}
+		roleIDs := []string{role.ID}
+		if canView(guild, destination, "", roleIDs) && !canView(guild, source, "", roleIDs) {
+			return audienceCheck{broader: true, reason: fmt.Sprintf("members with the %s role can read the destination, but not the source channel", role.Name)}
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, channel := range []*discordgo.Channel{source, destination} {
+		for _, overwrite := range channel.PermissionOverwrites {
+			if overwrite.Type != discordgo.PermissionOverwriteTypeMember || seen[overwrite.ID] {
+				continue
+			}
+			seen[overwrite.ID] = true
+
+			roleIDs, ok := memberRoles(overwrite.ID)
+			if !ok {
+				continue
+			}
+			if canView(guild, destination, overwrite.ID, roleIDs) && !canView(guild, source, overwrite.ID, roleIDs) {
+				return audienceCheck{broader: true, reason: fmt.Sprintf("<@%s> can read the destination, but not the source channel", overwrite.ID)}
+			}
+		}
+	}
+
+	return audienceCheck{}
+}
+
+// checkAudience compares the audience of the source channel with the destination channel.
+func checkAudience(s *discordgo.Session, sourceGuildID, sourceChannelID string, destination *discordgo.Channel) audienceCheck {
+	if sourceGuildID == "" {
+		return audienceCheck{broader: true, reason: "the source is a direct message"}
+	}
+	if destination.GuildID != sourceGuildID {
+		return audienceCheck{broader: true, reason: "the destination channel is in a different server"}
+	}
+
+	source, err := fetchChannel(s, sourceChannelID)
+	if err != nil {
+		log.Printf("failed to resolve source channel for permission check: %v", err)
+		return audienceCheck{broader: true, reason: "the source channel permissions could not be checked"}
+	}
+
+	if source.ID == destination.ID {
+		return audienceCheck{}
+	}
+
+	// Private threads are limited to their members, which no channel destination can match.
+	if source.Type == discordgo.ChannelTypeGuildPrivateThread {
+		return audienceCheck{broader: true, reason: "the source is a private thread"}
+	}
+
+	source, err = permissionChannel(s, source)
+	if err != nil {
+		return audienceCheck{broader: true, reason: "the source channel permissions could not be checked"}
+	}
+	destination, err = permissionChannel(s, destination)
+	if err != nil {
+		return audienceCheck{broader: true, reason: "the destination channel permissions could not be checked"}
+	}
+
+	guild, err := fetchGuild(s, sourceGuildID)
+	if err != nil {
+		log.Printf("failed to resolve guild for permission check: %v", err)
+		return audienceCheck{broader: true, reason: "the server roles could not be checked"}
+	}
+
+	return compareAudience(guild, source, destination, func(userID string) ([]string, bool) {
+		member, err := fetchMember(s, sourceGuildID, userID)
+		if err != nil {
+			return nil, false
+		}
+		return member.Roles, true
+	})
+}
+
+// checkServerAudience reports whether a destination every member of the source server can
+// read, such as the server's webhook, is broader than the source channel.
+func checkServerAudience(s *discordgo.Session, sourceGuildID, sourceChannelID string) audienceCheck {
+	if sourceGuildID == "" {
+		return audienceCheck{broader: true, reason: "the source is a direct message"}
+	}
+
+	source, err := fetchChannel(s, sourceChannelID)
+	if err != nil {
+		log.Printf("failed to resolve source channel for permission check: %v", err)
+		return audienceCheck{broader: true, reason: "the source channel permissions could not be checked"}
+	}
+	if source.Type == discordgo.ChannelTypeGuildPrivateThread {
+		return audienceCheck{broader: true, reason: "the source is a private thread"}
+	}
+
+	source, err = permissionChannel(s, source)
+	if err != nil {
+		return audienceCheck{broader: true, reason: "the source channel permissions could not be checked"}
+	}
+
+	guild, err := fetchGuild(s, sourceGuildID)
+	if err != nil {
+		log.Printf("failed to resolve guild for permission check: %v", err)
+		return audienceCheck{broader: true, reason: "the server roles could not be checked"}
+	}
+
+	return compareServerAudience(guild, source)
+}
+
+// compareServerAudience reports whether @everyone is denied the source channel.
+func compareServerAudience(guild *discordgo.Guild, source *discordgo.Channel) audienceCheck {
+	if !canView(guild, source, "", nil) {
+		return audienceCheck{broader: true, reason: "not everyone in the server can read the source channel"}
+	}
+
+	return audienceCheck{}
+}
+
+// permissionChannel returns the channel whose overwrites govern access to channel. Threads
+// inherit their parent's permissions.
+func permissionChannel(s *discordgo.Session, channel *discordgo.Channel) (*discordgo.Channel, error) {
+	if !channel.IsThread() || channel.ParentID == "" {
+		return channel, nil
+	}
+
+	return fetchChannel(s, channel.ParentID)
+}
+
+func fetchGuild(s *discordgo.Session, guildID string) (*discordgo.Guild, error) {
+	if guild, err := s.State.Guild(guildID); err == nil && guild != nil && len(guild.Roles) > 0 {
+		return guild, nil
+	}
+
+	return s.Guild(guildID)
+}
+
+func fetchMember(s *discordgo.Session, guildID, userID string) (*discordgo.Member, error) {
+	if member, err := s.State.Member(guildID, userID); err == nil && member != nil {
+		return member, nil
+	}
+
+	return s.GuildMember(guildID, userID)
+}
+
+// redactMessage returns a copy of msg that only keeps metadata, for destinations whose
+// audience is broader than the source.
+func redactMessage(msg *discordgo.Message) *discordgo.Message {
+	return &discordgo.Message{
+		ID:        msg.ID,
+		ChannelID: msg.ChannelID,
+		GuildID:   msg.GuildID,
+		Timestamp: msg.Timestamp,
+		Author:    msg.Author,
+		Content:   "🔒 Content hidden because this channel has a broader audience than the source. Open the source message to read it.",
+	}
+}
+
+// notifyPrivacy tells the user by DM why their bookmark to destination was refused or
+// redacted.
+func notifyPrivacy(s *discordgo.Session, userID, destination, reason string, redacted bool) {
+	dmChannel, err := s.UserChannelCreate(userID)
+	if err != nil {
+		log.Printf("failed to create DM channel for privacy notice: %v", err)
+		return
+	}
+
+	outcome := "was not posted"
+	if redacted {
+		outcome = "was posted without its content"
+	}
+
+	_, err = s.ChannelMessageSendComplex(dmChannel.ID, &discordgo.MessageSend{
+		Embeds: []*discordgo.MessageEmbed{{
+			Title:       "🔒 Bookmark withheld",
+			Description: fmt.Sprintf("Your bookmark to %s %s because %s.", destination, outcome, reason),
+			Color:       0xED4245,
+		}},
+	})
+	if err != nil {
+		log.Printf("failed to send privacy notice: %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	testGuildID = "guild"
+	staffRoleID = "staff"
+)
+
+func testGuild() *discordgo.Guild {
+	return &discordgo.Guild{
+		ID:      testGuildID,
+		OwnerID: "owner",
+		Roles: []*discordgo.Role{
+			{ID: testGuildID, Name: "@everyone", Permissions: discordgo.PermissionViewChannel},
+			{ID: staffRoleID, Name: "Staff"},
+		},
+	}
+}
+
+func staffOnlyChannel() *discordgo.Channel {
+	return &discordgo.Channel{
+		ID:      "staff-room",
+		GuildID: testGuildID,
+		PermissionOverwrites: []*discordgo.PermissionOverwrite{
+			{ID: testGuildID, Type: discordgo.PermissionOverwriteTypeRole, Deny: discordgo.PermissionViewChannel},
+			{ID: staffRoleID, Type: discordgo.PermissionOverwriteTypeRole, Allow: discordgo.PermissionViewChannel},
+		},
+	}
+}
+
+func publicChannel() *discordgo.Channel {
+	return &discordgo.Channel{ID: "general", GuildID: testGuildID}
+}
+
+func noMembers(string) ([]string, bool) { return nil, false }
+
+func TestComputePermissionsAppliesOverwrites(t *testing.T) {
+	guild := testGuild()
+	channel := staffOnlyChannel()
+
+	if canView(guild, channel, "member", nil) {
+		t.Fatalf("expected @everyone to be denied")
+	}
+	if !canView(guild, channel, "member", []string{staffRoleID}) {
+		t.Fatalf("expected staff role overwrite to allow viewing")
+	}
+	if !canView(guild, channel, "owner", nil) {
+		t.Fatalf("expected the guild owner to see every channel")
+	}
+}
+
+func TestComputePermissionsMemberOverwriteWins(t *testing.T) {
+	guild := testGuild()
+	channel := staffOnlyChannel()
+	channel.PermissionOverwrites = append(channel.PermissionOverwrites, &discordgo.PermissionOverwrite{
+		ID: "banned", Type: discordgo.PermissionOverwriteTypeMember, Deny: discordgo.PermissionViewChannel,
+	})
+
+	if canView(guild, channel, "banned", []string{staffRoleID}) {
+		t.Fatalf("expected member overwrite to deny viewing")
+	}
+}
+
+func TestCompareAudienceDetectsLeakToPublicChannel(t *testing.T) {
+	check := compareAudience(testGuild(), staffOnlyChannel(), publicChannel(), noMembers)
+	if !check.broader {
+		t.Fatalf("expected forwarding from a staff channel to a public channel to be broader")
+	}
+}
+
+func TestCompareAudienceAllowsNarrowerDestination(t *testing.T) {
+	check := compareAudience(testGuild(), publicChannel(), staffOnlyChannel(), noMembers)
+	if check.broader {
+		t.Fatalf("expected forwarding from a public channel to a staff channel to be allowed: %s", check.reason)
+	}
+}
+
+func TestCompareAudienceChecksMemberOverwrites(t *testing.T) {
+	destination := staffOnlyChannel()
+	destination.ID = "staff-lounge"
+	destination.PermissionOverwrites = append(destination.PermissionOverwrites, &discordgo.PermissionOverwrite{
+		ID: "guest", Type: discordgo.PermissionOverwriteTypeMember, Allow: discordgo.PermissionViewChannel,
+	})
+
+	check := compareAudience(testGuild(), staffOnlyChannel(), destination, func(userID string) ([]string, bool) {
+		return nil, true
+	})
+	if !check.broader {
+		t.Fatalf("expected a member-specific overwrite on the destination to broaden the audience")
+	}
+}
+
+func TestCompareServerAudienceRequiresPublicSource(t *testing.T) {
+	if check := compareServerAudience(testGuild(), staffOnlyChannel()); !check.broader {
+		t.Fatalf("expected the server webhook to be broader than a staff channel")
+	}
+	if check := compareServerAudience(testGuild(), publicChannel()); check.broader {
+		t.Fatalf("expected a public channel to be allowed: %s", check.reason)
+	}
+}
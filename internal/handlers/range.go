@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/example/discord-bookmark-manager/internal/emojikey"
+	"github.com/example/discord-bookmark-manager/internal/store"
+	"github.com/example/discord-bookmark-manager/internal/transcript"
+)
+
+// rangeCaptureTimeout is how long a start marker waits for its matching end marker.
+const rangeCaptureTimeout = 30 * time.Minute
+
+type pendingRange struct {
+	messageID string
+	expires   time.Time
+}
+
+// rangeTracker remembers start markers per user and channel until the end marker arrives.
+type rangeTracker struct {
+	mu      sync.Mutex
+	pending map[string]pendingRange
+	timeout time.Duration
+}
+
+func newRangeTracker(timeout time.Duration) *rangeTracker {
+	return &rangeTracker{
+		pending: make(map[string]pendingRange),
+		timeout: timeout,
+	}
+}
+
+// start records messageID as the beginning of a range. A later start replaces the earlier one.
+func (t *rangeTracker) start(userID, channelID, messageID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	for key, entry := range t.pending {
+		if now.After(entry.expires) {
+			delete(t.pending, key)
+		}
+	}
+
+	t.pending[rangeKey(userID, channelID)] = pendingRange{
+		messageID: messageID,
+		expires:   now.Add(t.timeout),
+	}
+}
+
+// finish removes and returns the pending start marker for the user and channel.
+func (t *rangeTracker) finish(userID, channelID string) (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := rangeKey(userID, channelID)
+	entry, ok := t.pending[key]
+	if !ok {
+		return "", false
+	}
+	delete(t.pending, key)
+
+	if time.Now().After(entry.expires) {
+		return "", false
+	}
+
+	return entry.messageID, true
+}
+
+func rangeKey(userID, channelID string) string {
+	return userID + ":" + channelID
+}
+
+// findRangeByEndEmoji returns the start emoji and range preference among prefs whose end
+// marker is emoji, ignoring skin tones with foldSkinTones.
+func findRangeByEndEmoji(prefs map[string]store.EmojiPreference, emoji string, foldSkinTones bool) (string, store.EmojiPreference, bool) {
+	for start, pref := range prefs {
+		if pref.Capture == store.CaptureRange && emojikey.Same(pref.RangeEndEmoji, emoji, foldSkinTones) {
+			return start, pref, true
+		}
+	}
+
+	return "", store.EmojiPreference{}, false
+}
+
+// finishRange closes the user's pending range in the event channel and saves every message
+// between the two markers as a single bookmark.
+func (h *ReactionHandler) finishRange(s *discordgo.Session, event *discordgo.MessageReactionAdd, startEmoji string, pref store.EmojiPreference) {
+	startID, ok := h.ranges.finish(event.UserID, event.ChannelID)
+	if !ok {
+		log.Printf("range end marker from %s in %s has no pending start marker", event.UserID, event.ChannelID)
+		return
+	}
+	markerID := startID
+
+	endID := event.MessageID
+	if snowflakeLess(endID, startID) {
+		startID, endID = endID, startID
+	}
+
+	messages, truncated, err := fetchRangeMessages(s, event.ChannelID, startID, endID)
+	if err != nil {
+		log.Printf("failed to fetch range messages: %v", err)
+		return
+	}
+	if len(messages) == 0 {
+		return
+	}
+
+	capture := transcript.New(
+		fmt.Sprintf("📑 #%s", fetchChannelName(s, event.ChannelID)),
+		buildJumpLink(event.GuildID, event.ChannelID, startID),
+		messages,
+	)
+	capture.Truncated = truncated
+
+	h.save(s, event, pref, capture.Messages[0], capture, reactionRef{
+		ChannelID: event.ChannelID,
+		MessageID: markerID,
+		Emoji:     startEmoji,
+		UserID:    event.UserID,
+	})
+}
+
+// fetchRangeMessages returns the messages from startID through endID inclusive, capped at
+// maxTranscriptMessages. truncated reports that the range goes on past the cap.
+func fetchRangeMessages(s *discordgo.Session, channelID, startID, endID string) (collected []*discordgo.Message, truncated bool, err error) {
+	start, err := s.ChannelMessage(channelID, startID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	collected = []*discordgo.Message{start}
+	if startID == endID {
+		return collected, false, nil
+	}
+
+	afterID := startID
+	for {
+		page, err := s.ChannelMessages(channelID, 100, "", afterID, "")
+		if err != nil {
+			return nil, false, err
+		}
+		if len(page) == 0 {
+			break
+		}
+
+		sort.Slice(page, func(a, b int) bool {
+			return snowflakeLess(page[a].ID, page[b].ID)
+		})
+
+		for _, msg := range page {
+			if snowflakeLess(endID, msg.ID) {
+				return collected, false, nil
+			}
+			if len(collected) == maxTranscriptMessages {
+				return collected, true, nil
+			}
+			collected = append(collected, msg)
+			if msg.ID == endID {
+				return collected, false, nil
+			}
+		}
+
+		if len(page) < 100 {
+			break
+		}
+		afterID = page[len(page)-1].ID
+	}
+
+	return collected, false, nil
+}
+
+// snowflakeLess reports whether snowflake a was created before b.
+func snowflakeLess(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+
+	return a < b
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/example/discord-bookmark-manager/internal/store"
+)
+
+func TestRangeTrackerFinishReturnsStart(t *testing.T) {
+	tracker := newRangeTracker(time.Minute)
+	tracker.start("user", "channel", "100")
+
+	startID, ok := tracker.finish("user", "channel")
+	if !ok || startID != "100" {
+		t.Fatalf("expected pending start 100, got %q (ok=%v)", startID, ok)
+	}
+
+	if _, ok := tracker.finish("user", "channel"); ok {
+		t.Fatalf("expected the pending range to be consumed")
+	}
+}
+
+func TestRangeTrackerIsScopedPerChannel(t *testing.T) {
+	tracker := newRangeTracker(time.Minute)
+	tracker.start("user", "channel-a", "100")
+
+	if _, ok := tracker.finish("user", "channel-b"); ok {
+		t.Fatalf("expected no pending range in another channel")
+	}
+}
+
+func TestRangeTrackerExpires(t *testing.T) {
+	tracker := newRangeTracker(-time.Second)
+	tracker.start("user", "channel", "100")
+
+	if _, ok := tracker.finish("user", "channel"); ok {
+		t.Fatalf("expected the pending range to have expired")
+	}
+}
+
+func TestSnowflakeLess(t *testing.T) {
+	if !snowflakeLess("99", "100") {
+		t.Fatalf("expected shorter snowflakes to sort first")
+	}
+	if snowflakeLess("200", "100") {
+		t.Fatalf("expected 200 to sort after 100")
+	}
+}
+
+func TestFetchRangeMessagesReportsTruncation(t *testing.T) {
+	s := historySession(t, 3000)
+
+	messages, truncated, err := fetchRangeMessages(s, "channel", "1000", "1199")
+	if err != nil {
+		t.Fatalf("fetchRangeMessages returned error: %v", err)
+	}
+	if truncated || len(messages) != 200 || messages[199].ID != "1199" {
+		t.Fatalf("expected the whole range of 200 messages, got %d (truncated %v)", len(messages), truncated)
+	}
+
+	messages, truncated, err = fetchRangeMessages(s, "channel", "1000", "2999")
+	if err != nil {
+		t.Fatalf("fetchRangeMessages returned error: %v", err)
+	}
+	if !truncated || len(messages) != maxTranscriptMessages || messages[0].ID != "1000" {
+		t.Fatalf("expected the first %d messages and a truncation, got %d (truncated %v)", maxTranscriptMessages, len(messages), truncated)
+	}
+}
+
+func TestFindRangeByEndEmojiIgnoresPresentationAndTones(t *testing.T) {
+	prefs := map[string]store.EmojiPreference{
+		"▶": {Capture: store.CaptureRange, RangeEndEmoji: "✋"},
+	}
+
+	if start, _, ok := findRangeByEndEmoji(prefs, "✋️", false); !ok || start != "▶" {
+		t.Fatalf("expected ✋️ to end the ▶ range, got %q (ok=%v)", start, ok)
+	}
+	if _, _, ok := findRangeByEndEmoji(prefs, "✋🏾", false); ok {
+		t.Fatalf("expected another skin tone not to match without folding")
+	}
+	if _, _, ok := findRangeByEndEmoji(prefs, "✋🏾", true); !ok {
+		t.Fatalf("expected another skin tone to match with folding")
+	}
+}
@@ -4,12 +4,19 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
 
+	"github.com/example/discord-bookmark-manager/internal/email"
+	"github.com/example/discord-bookmark-manager/internal/emojikey"
+	"github.com/example/discord-bookmark-manager/internal/outbox"
 	"github.com/example/discord-bookmark-manager/internal/reminders"
 	"github.com/example/discord-bookmark-manager/internal/store"
+	"github.com/example/discord-bookmark-manager/internal/transcript"
+	"github.com/example/discord-bookmark-manager/internal/vault"
+	"github.com/example/discord-bookmark-manager/internal/webhook"
 )
 
 const defaultEmbedColor = 0x5865F2
@@ -17,12 +24,48 @@ const defaultEmbedColor = 0x5865F2
 // ReactionHandler sends a direct message when a user reacts with their registered emoji.
 type ReactionHandler struct {
 	store     *store.EmojiStore
+	bookmarks *store.BookmarkStore
+	guilds    *store.GuildStore
 	reminders *reminders.Service
+	outbox    *outbox.Service
+	webhooks  *webhook.Client
+	vault     *vault.Vault
+	mailer    *email.Sender
+	ranges    *rangeTracker
+	starboard *store.StarboardStore
+	// foldSkinTones lets reactions in any skin tone match an emoji configured in another.
+	foldSkinTones bool
+	// feeds keeps the content of saved messages for the users' feeds.
+	feeds bool
+	// starboardLocks serializes starboard updates per message so it is showcased once.
+	starboardLocks keyedMutex
+	// sharedMu serializes edits to shared channel posts.
+	sharedMu sync.Mutex
+	// followUps records which saves already ran their reminder and reaction cleanup.
+	followUps followUpClaims
+	// sharedLocks serializes recording posts per source message and channel, so two saves
+	// delivered at once end up as one shared post.
+	sharedLocks keyedMutex
 }
 
-// NewReactionHandler constructs a ReactionHandler.
-func NewReactionHandler(store *store.EmojiStore, reminders *reminders.Service) *ReactionHandler {
-	return &ReactionHandler{store: store, reminders: reminders}
+// NewReactionHandler constructs a ReactionHandler and registers it for delivered bookmarks.
+func NewReactionHandler(store *store.EmojiStore, bookmarks *store.BookmarkStore, guilds *store.GuildStore, starboard *store.StarboardStore, reminders *reminders.Service, deliveries *outbox.Service, webhooks *webhook.Client, notes *vault.Vault, mailer *email.Sender) *ReactionHandler {
+	h := &ReactionHandler{store: store, bookmarks: bookmarks, guilds: guilds, starboard: starboard, reminders: reminders, outbox: deliveries, webhooks: webhooks, vault: notes, mailer: mailer, ranges: newRangeTracker(rangeCaptureTimeout)}
+	deliveries.OnDelivered(h.delivered)
+	deliveries.RegisterSender(webhookKind, h.sendWebhook)
+	deliveries.RegisterSender(emailKind, h.sendEmail)
+	return h
+}
+
+// SetFoldSkinTones sets whether reactions in any skin tone match an emoji configured without
+// one or in another tone.
+func (h *ReactionHandler) SetFoldSkinTones(enabled bool) {
+	h.foldSkinTones = enabled
+}
+
+// SetFeeds sets whether bookmarks keep the content their feed entries show.
+func (h *ReactionHandler) SetFeeds(enabled bool) {
+	h.feeds = enabled
 }
 
 // Handle reacts to MessageReactionAdd events.
@@ -35,139 +78,424 @@ func (h *ReactionHandler) Handle(s *discordgo.Session, event *discordgo.MessageR
 		return
 	}
 
-	prefs, ok := h.store.Get(event.UserID)
-	if !ok || len(prefs.Emojis) == 0 {
-		return
-	}
+	h.updateStarboard(s, event.MessageReaction, 1)
 
-	reactionID := event.Emoji.APIName()
-	if reactionID == "" {
-		reactionID = event.Emoji.Name
-	}
+	prefs, _ := h.store.Get(event.UserID)
+	effective := prefs.Effective(event.GuildID)
+	reactionID := reactionKey(&event.Emoji)
 
-	pref, ok := prefs.Emojis[reactionID]
-	if !ok {
+	_, personal, hasPersonal := emojikey.Lookup(effective, reactionID, h.foldSkinTones)
+	team, hasTeam, precedence := h.teamEmoji(event.GuildID, reactionID)
+	if !hasPersonal && !hasTeam {
+		if startEmoji, rangePref, found := findRangeByEndEmoji(effective, reactionID, h.foldSkinTones); found {
+			h.finishRange(s, event, startEmoji, rangePref)
+		}
 		return
 	}
 
-	msg, err := s.ChannelMessage(event.ChannelID, event.MessageID)
-	if err != nil {
-		log.Printf("failed to fetch message: %v", err)
-		return
-	}
+	var msg *discordgo.Message
+	for _, pref := range resolveEmoji(personal, hasPersonal, team, hasTeam, precedence) {
+		if pref.Capture == store.CaptureRange {
+			h.ranges.start(event.UserID, event.ChannelID, event.MessageID)
+			continue
+		}
 
-	channelName := fetchChannelName(s, event.ChannelID)
+		if msg == nil {
+			fetched, err := s.ChannelMessage(event.ChannelID, event.MessageID)
+			if err != nil {
+				log.Printf("failed to fetch message: %v", err)
+				return
+			}
+			msg = fetched
+		}
 
-	color := pref.Color
-	if !pref.HasColor {
-		color = defaultEmbedColor
+		h.save(s, event, pref, msg, nil)
 	}
+}
+
+// saveContext holds what every destination target of one save shares.
+type saveContext struct {
+	channelName string
+	jumpURL     string
+	now         time.Time
+	schedule    *reminders.Schedule
+	// capture is the thread or range transcript, saved as captureName.md/.html.
+	capture     *transcript.Transcript
+	captureName string
+	// silentReactions are removed once the first target is delivered.
+	silentReactions []reactionRef
+	// downloads holds the attachments archived by the save's targets.
+	downloads attachmentDownloads
+}
 
-	jumpURL := buildJumpLink(event.GuildID, event.ChannelID, event.MessageID)
-	now := time.Now()
+// save renders msg for every destination target of pref and hands each one to the outbox.
+// Targets are delivered independently, so a refused or failing target does not hold back the
+// others. When capture is non-nil the transcript is attached to the bookmark. Silent saves
+// also remove the reactions in markers once the bookmark is delivered.
+func (h *ReactionHandler) save(s *discordgo.Session, event *discordgo.MessageReactionAdd, pref store.EmojiPreference, msg *discordgo.Message, capture *transcript.Transcript, markers ...reactionRef) {
+	ctx := saveContext{
+		channelName: fetchChannelName(s, event.ChannelID),
+		jumpURL:     buildJumpLink(event.GuildID, event.ChannelID, msg.ID),
+		now:         time.Now(),
+		capture:     capture,
+		captureName: fmt.Sprintf("range-%s", msg.ID),
+		downloads:   make(attachmentDownloads),
+	}
 
-	var schedule *reminders.Schedule
 	if pref.Reminder != nil {
-		computed, err := reminders.Next(pref.Reminder, now)
+		computed, err := reminders.Next(pref.Reminder, ctx.now)
 		if err != nil {
 			log.Printf("failed to compute reminder: %v", err)
 		} else {
-			schedule = computed
+			ctx.schedule = computed
 		}
 	}
 
-	var messageSend *discordgo.MessageSend
+	if capture == nil && pref.Capture == store.CaptureThread {
+		threadCapture, name, ok := captureThread(s, event.ChannelID, msg, event.GuildID)
+		if ok {
+			ctx.capture = threadCapture
+			ctx.captureName = name
+		} else {
+			log.Printf("thread capture requested but message %s did not start a thread; saving the message only", msg.ID)
+		}
+	}
 
-	switch pref.Mode {
-	case store.ModeLightweight:
-		messageSend = buildLightweightBookmark(msg, channelName, jumpURL, color, &event.Emoji, schedule)
-	case store.ModeComplete:
-		messageSend = buildCompleteBookmark(msg, channelName, jumpURL, color, schedule)
-	case store.ModeBalanced:
-		messageSend = buildBalancedBookmark(msg, channelName, jumpURL, color, schedule)
-	default:
-		messageSend = buildBalancedBookmark(msg, channelName, jumpURL, color, schedule)
+	canRemoveReaction := false
+	if pref.Silent {
+		canRemoveReaction = botCanManageMessages(s, event.GuildID, event.ChannelID)
+		if canRemoveReaction {
+			ctx.silentReactions = append([]reactionRef{{
+				ChannelID: event.ChannelID,
+				MessageID: event.MessageID,
+				Emoji:     reactionKey(&event.Emoji),
+				UserID:    event.UserID,
+			}}, markers...)
+		}
 	}
 
-	if messageSend == nil {
-		return
+	summary := fmt.Sprintf("a message in #%s (%s)", ctx.channelName, ctx.jumpURL)
+
+	// Every target carries the reminder and reaction cleanup until one is delivered on its
+	// first attempt. Whichever target is delivered first runs them; see claimFollowUp.
+	saveID := store.NewBookmarkID()
+	reactions := ctx.silentReactions
+	reminder := newPendingReminder(pref, msg, ctx)
+
+	var webhookTargets, emailTargets []store.DestinationTarget
+	for _, target := range pref.AllTargets() {
+		switch target.Destination {
+		case store.DestinationWebhook:
+			webhookTargets = append(webhookTargets, target)
+			continue
+		case store.DestinationEmail:
+			emailTargets = append(emailTargets, target)
+			continue
+		}
+
+		// Another member already posted this message here; add this save to their post.
+		if target.Destination == store.DestinationChannel && ctx.capture == nil && h.bookmarks != nil {
+			if existing, ok := h.bookmarks.ByDestination(msg.ID, target.ChannelID); ok {
+				claimedReactions, claimedReminder := h.claimFollowUp(deliveryMeta{SaveID: saveID, Reactions: reactions, Reminder: reminder})
+				h.joinShared(s, event.UserID, existing, claimedReactions, claimedReminder)
+				reactions, reminder = nil, nil
+				continue
+			}
+		}
+
+		rendered := h.renderTarget(s, event, pref, target, msg, ctx)
+		if rendered == nil {
+			continue
+		}
+
+		if pref.Silent {
+			noteSilentSave(rendered.messageSend, canRemoveReaction)
+		}
+		rendered.meta.SaveID = saveID
+		rendered.meta.Reactions = reactions
+		rendered.meta.Reminder = reminder
+
+		if h.enqueue(event.UserID, summary, rendered) {
+			reactions, reminder = nil, nil
+		}
+	}
+
+	// Email carries the reminder too while no Discord target was delivered.
+	for _, target := range emailTargets {
+		address, ok := h.store.VerifiedEmail(event.UserID)
+		if !ok || !h.mailer.Enabled() {
+			log.Printf("bookmark destination misconfigured: no verified email for user %s", event.UserID)
+			continue
+		}
+
+		// Email leaves Discord, where the server's permissions no longer apply, so it gets the
+		// same check as the server's webhook. Saves from DMs stay with their participant.
+		source := msg
+		if event.GuildID != "" {
+			if check := checkServerAudience(s, event.GuildID, event.ChannelID); check.broader {
+				redacted := h.privacyAction(event.GuildID, event.GuildID) == store.PrivacyRedact
+				notifyPrivacy(s, event.UserID, "your email", check.reason, redacted)
+				if !redacted {
+					continue
+				}
+				source = redactMessage(msg)
+			}
+		}
+
+		message, ok := buildEmailBookmark(address, event, target, source, ctx)
+		if !ok {
+			continue
+		}
+
+		if h.enqueueEmail(event.UserID, summary, message, deliveryMeta{SaveID: saveID, Reactions: reactions, Reminder: reminder}) {
+			reactions, reminder = nil, nil
+		}
+	}
+
+	// Webhook documents carry the reminder themselves; only reaction cleanup is left to them
+	// while no Discord or email target was delivered.
+	for _, target := range webhookTargets {
+		endpoint, shared, ok := h.webhookEndpoint(event.UserID, event.GuildID)
+		if !ok {
+			log.Printf("bookmark destination misconfigured: no webhook for user %s in guild %s", event.UserID, event.GuildID)
+			continue
+		}
+
+		// The server's webhook reaches whoever runs it, so it gets the same check as a
+		// channel every member can read.
+		source := msg
+		if shared {
+			if check := checkServerAudience(s, event.GuildID, event.ChannelID); check.broader {
+				redacted := h.privacyAction(event.GuildID, event.GuildID) == store.PrivacyRedact
+				notifyPrivacy(s, event.UserID, "the server's webhook", check.reason, redacted)
+				if !redacted {
+					continue
+				}
+				source = redactMessage(msg)
+			}
+		}
+
+		if h.enqueueWebhook(event.UserID, summary, endpoint, buildWebhookDocument(event, target, source, ctx), deliveryMeta{SaveID: saveID, Reactions: reactions}) {
+			reactions = nil
+		}
 	}
+}
 
+// newPendingReminder returns the reminder the first delivered target schedules, or nil
+// without one.
+func newPendingReminder(pref store.EmojiPreference, msg *discordgo.Message, ctx saveContext) *pendingReminder {
+	if ctx.schedule == nil || pref.Reminder == nil {
+		return nil
+	}
+
+	return &pendingReminder{
+		When:             ctx.schedule.Time,
+		RemoveOnComplete: pref.Reminder.RemoveOnComplete,
+		JumpURL:          ctx.jumpURL,
+		ChannelName:      ctx.channelName,
+		ContentSnippet:   extractSnippet(msg),
+		Daily:            pref.Reminder.Mode == reminders.ModeTimeOfDay,
+	}
+}
+
+// renderTarget builds the bookmark message for one destination target. It returns nil when
+// the target is misconfigured or refused by the privacy check. Forum channels get a new post
+// titled from the message and tagged from the reaction emoji.
+func (h *ReactionHandler) renderTarget(s *discordgo.Session, event *discordgo.MessageReactionAdd, pref store.EmojiPreference, target store.DestinationTarget, msg *discordgo.Message, ctx saveContext) *renderedTarget {
+	color := target.Color
+	if !target.HasColor {
+		color = defaultEmbedColor
+	}
+
+	bookmarkID := store.NewBookmarkID()
 	destinationChannelID := ""
 	destinationGuildID := ""
+	redacted := false
+	vaultPath := ""
+	var forum *discordgo.Channel
 
-	switch pref.Destination {
+	switch target.Destination {
 	case store.DestinationChannel:
-		if pref.ChannelID == "" {
+		if target.ChannelID == "" {
 			log.Printf("bookmark destination misconfigured: missing channel id for emoji %s", event.Emoji.Name)
-			return
+			return nil
 		}
 
-		channel, err := fetchChannel(s, pref.ChannelID)
+		channel, err := fetchChannel(s, target.ChannelID)
 		if err != nil {
-			log.Printf("failed to resolve destination channel: %v", err)
-			return
+			log.Printf("failed to resolve destination channel %s: %v", target.ChannelID, err)
+			return nil
 		}
 
 		destinationChannelID = channel.ID
 		destinationGuildID = channel.GuildID
+		if channel.Type == discordgo.ChannelTypeGuildForum {
+			forum = channel
+		}
+
+		if check := checkAudience(s, event.GuildID, event.ChannelID, channel); check.broader {
+			redacted = h.privacyAction(event.GuildID, destinationGuildID) == store.PrivacyRedact
+			notifyPrivacy(s, event.UserID, fmt.Sprintf("<#%s>", destinationChannelID), check.reason, redacted)
+			if !redacted {
+				return nil
+			}
+		}
 	case store.DestinationDM, "":
-		dmChannel, err := s.UserChannelCreate(event.UserID)
+		// The outbox opens the DM channel so failures there are retried too.
+	case store.DestinationVault:
+		if !h.vault.Enabled() {
+			log.Printf("bookmark destination misconfigured: vault is not enabled for emoji %s", event.Emoji.Name)
+			return nil
+		}
+
+		relPath, err := h.writeVaultNote(event, bookmarkID, target, msg, ctx)
 		if err != nil {
-			log.Printf("failed to create DM channel: %v", err)
-			return
+			log.Printf("failed to write vault note: %v", err)
+			return nil
 		}
-		destinationChannelID = dmChannel.ID
+		vaultPath = relPath
 	default:
-		log.Printf("unsupported bookmark destination: %s", pref.Destination)
-		return
+		log.Printf("unsupported bookmark destination: %s", target.Destination)
+		return nil
 	}
 
-	sentMessage, err := s.ChannelMessageSendComplex(destinationChannelID, messageSend)
-	if err != nil {
-		log.Printf("failed to send bookmark: %v", err)
-		return
+	source := msg
+	if redacted {
+		source = redactMessage(msg)
 	}
 
-	if schedule != nil && h.reminders != nil && pref.Reminder != nil {
-		reminderChannelID := destinationChannelID
-
-		if pref.Destination == store.DestinationChannel {
-			dmChannel, err := s.UserChannelCreate(event.UserID)
-			if err != nil {
-				log.Printf("failed to create DM channel for reminder: %v", err)
-				reminderChannelID = ""
-			} else {
-				reminderChannelID = dmChannel.ID
-			}
+	var messageSend *discordgo.MessageSend
+	if vaultPath != "" {
+		reminder := ""
+		if ctx.schedule != nil {
+			reminder = ctx.schedule.Description
 		}
+		messageSend = buildVaultReceipt(bookmarkID, vaultPath, forumTitle(msg, ctx.channelName), ctx.channelName, ctx.jumpURL, color, reminder)
+	} else {
+		messageSend = buildBookmark(bookmarkID, target.Mode, source, ctx.channelName, ctx.jumpURL, color, &event.Emoji, ctx.schedule)
+	}
+	if messageSend == nil {
+		return nil
+	}
 
-		if reminderChannelID == "" {
-			dmChannel, err := s.UserChannelCreate(event.UserID)
-			if err != nil {
-				log.Printf("failed to prepare reminder destination: %v", err)
-			} else {
-				reminderChannelID = dmChannel.ID
-			}
+	// Transcripts and archived files would leak the hidden content of redacted bookmarks.
+	// Vault notes already carry both.
+	if ctx.capture != nil && !redacted && vaultPath == "" {
+		attachTranscript(messageSend, ctx.capture, ctx.captureName)
+	}
+	if ctx.schedule != nil && pref.Reminder != nil {
+		attachCalendar(messageSend, bookmarkID, source, pref.Reminder, ctx)
+	}
+	var archive archiveResult
+	if pref.Archive && !redacted && vaultPath == "" {
+		archive = archiveAttachments(messageSend, msg.Attachments, ctx.downloads)
+	}
+	if target.Destination == store.DestinationChannel {
+		messageSend.Components = withTeamButtons(messageSend.Components, bookmarkID)
+	}
+
+	meta := deliveryMeta{
+		Bookmark: store.Bookmark{
+			ID:              bookmarkID,
+			UserID:          event.UserID,
+			Emoji:           reactionKey(&event.Emoji),
+			Mode:            target.Mode,
+			Color:           color,
+			Capture:         pref.Capture,
+			Destination:     target.Destination,
+			TrackEdits:      pref.TrackEdits && !redacted && vaultPath == "" && pref.Capture == store.CaptureMessage,
+			SourceGuildID:   event.GuildID,
+			SourceChannelID: event.ChannelID,
+			SourceMessageID: msg.ID,
+			SavedGuildID:    destinationGuildID,
+			VaultPath:       vaultPath,
+			ChannelName:     ctx.channelName,
+			SavedAt:         ctx.now,
+		},
+	}
+	if ctx.schedule != nil {
+		meta.Bookmark.ReminderDescription = ctx.schedule.Description
+	}
+	if !redacted {
+		recordContent(&meta.Bookmark, msg, ctx.channelName, h.feeds)
+	} else {
+		meta.Bookmark.Title = forumTitle(source, ctx.channelName)
+	}
+
+	rendered := &renderedTarget{messageSend: messageSend, meta: meta, channelID: destinationChannelID, archive: archive}
+	if forum != nil {
+		rendered.meta.Bookmark.ForumChannelID = forum.ID
+		rendered.forum = &outbox.ForumPost{
+			Title:       forumTitle(source, ctx.channelName),
+			AppliedTags: forumTagsForEmoji(forum, &event.Emoji),
 		}
+	}
 
-		if reminderChannelID != "" {
-			snippet := extractSnippet(msg)
-			bookmarkURL := ""
-			if sentMessage != nil {
-				bookmarkURL = buildJumpLink(destinationGuildID, destinationChannelID, sentMessage.ID)
-			}
-			h.reminders.Schedule(sentMessage.ID, schedule.Time, reminders.Payload{
-				ChannelID:      reminderChannelID,
-				JumpURL:        jumpURL,
-				BookmarkURL:    bookmarkURL,
-				ChannelName:    channelName,
-				ContentSnippet: snippet,
-			}, pref.Reminder.RemoveOnComplete)
+	return rendered
+}
+
+// privacyAction returns the configured response to a broader destination audience. The
+// source server decides; bookmarks from DMs follow the destination server.
+func (h *ReactionHandler) privacyAction(sourceGuildID, destinationGuildID string) store.PrivacyAction {
+	if h.guilds == nil {
+		return store.PrivacyRefuse
+	}
+
+	guildID := sourceGuildID
+	if guildID == "" {
+		guildID = destinationGuildID
+	}
+
+	return h.guilds.Get(guildID).Privacy
+}
+
+// recordContent copies the title of msg onto bookmark and, when feeds are on, what the
+// user's feed shows of it. Without feeds no message content is kept.
+func recordContent(bookmark *store.Bookmark, msg *discordgo.Message, channelName string, feeds bool) {
+	bookmark.Title = forumTitle(msg, channelName)
+	if !feeds {
+		return
+	}
+
+	bookmark.Content = msg.Content
+	bookmark.AuthorName = ""
+	if msg.Author != nil {
+		bookmark.AuthorName = msg.Author.Username
+	}
+
+	bookmark.AttachmentURLs = nil
+	for _, attachment := range msg.Attachments {
+		if attachment != nil {
+			bookmark.AttachmentURLs = append(bookmark.AttachmentURLs, attachment.URL)
 		}
 	}
 }
 
+// reactionKey returns the identifier used to store preferences for emoji.
+func reactionKey(emoji *discordgo.Emoji) string {
+	key := emoji.APIName()
+	if key == "" {
+		key = emoji.Name
+	}
+
+	return key
+}
+
+// buildBookmark renders msg with the layout for mode. Buttons carry bookmarkID in their
+// custom IDs.
+func buildBookmark(bookmarkID string, mode store.BookmarkMode, msg *discordgo.Message, channelName, jumpURL string, color int, emoji *discordgo.Emoji, schedule *reminders.Schedule) *discordgo.MessageSend {
+	switch mode {
+	case store.ModeLightweight:
+		return buildLightweightBookmark(bookmarkID, msg, channelName, jumpURL, color, emoji, schedule)
+	case store.ModeComplete:
+		return buildCompleteBookmark(bookmarkID, msg, channelName, jumpURL, color, schedule)
+	case store.ModeBalanced:
+		return buildBalancedBookmark(bookmarkID, msg, channelName, jumpURL, color, schedule)
+	default:
+		return buildBalancedBookmark(bookmarkID, msg, channelName, jumpURL, color, schedule)
+	}
+}
+
 func fetchChannelName(s *discordgo.Session, channelID string) string {
 	if channel, err := s.State.Channel(channelID); err == nil && channel != nil {
 		return channel.Name
@@ -207,7 +535,7 @@ func buildJumpLink(guildID, channelID, messageID string) string {
 	return fmt.Sprintf("https://discord.com/channels/%s/%s/%s", guildID, channelID, messageID)
 }
 
-func buildLightweightBookmark(msg *discordgo.Message, channelName, jumpURL string, color int, emoji *discordgo.Emoji, schedule *reminders.Schedule) *discordgo.MessageSend {
+func buildLightweightBookmark(bookmarkID string, msg *discordgo.Message, channelName, jumpURL string, color int, emoji *discordgo.Emoji, schedule *reminders.Schedule) *discordgo.MessageSend {
 	titleEmoji := "👀"
 	if emoji != nil && emoji.Name != "" {
 		titleEmoji = emoji.Name
@@ -264,13 +592,13 @@ func buildLightweightBookmark(msg *discordgo.Message, channelName, jumpURL strin
 				discordgo.Button{
 					Label:    "Done",
 					Style:    discordgo.SuccessButton,
-					CustomID: CompleteButtonID,
+					CustomID: NewCustomID(ActionComplete, bookmarkID).Encode(),
 					Emoji:    discordgo.ComponentEmoji{Name: "✅"},
 				},
 				discordgo.Button{
 					Label:    "Remove",
 					Style:    discordgo.DangerButton,
-					CustomID: DeleteButtonID,
+					CustomID: NewCustomID(ActionDelete, bookmarkID).Encode(),
 					Emoji:    discordgo.ComponentEmoji{Name: "🗑️"},
 				},
 			}},
@@ -278,7 +606,7 @@ func buildLightweightBookmark(msg *discordgo.Message, channelName, jumpURL strin
 	}
 }
 
-func buildCompleteBookmark(msg *discordgo.Message, channelName, jumpURL string, color int, schedule *reminders.Schedule) *discordgo.MessageSend {
+func buildCompleteBookmark(bookmarkID string, msg *discordgo.Message, channelName, jumpURL string, color int, schedule *reminders.Schedule) *discordgo.MessageSend {
 	infoEmbed := buildInfoEmbed("📌 Full Save", msg, channelName, jumpURL, color, true, schedule)
 
 	embeds := []*discordgo.MessageEmbed{infoEmbed}
@@ -303,7 +631,7 @@ func buildCompleteBookmark(msg *discordgo.Message, channelName, jumpURL string,
 		buttons = append(buttons, discordgo.Button{
 			Label:    "Done",
 			Style:    discordgo.SuccessButton,
-			CustomID: CompleteButtonID,
+			CustomID: NewCustomID(ActionComplete, bookmarkID).Encode(),
 			Emoji:    discordgo.ComponentEmoji{Name: "✅"},
 		})
 	}
@@ -311,7 +639,7 @@ func buildCompleteBookmark(msg *discordgo.Message, channelName, jumpURL string,
 	buttons = append(buttons, discordgo.Button{
 		Label:    "Remove",
 		Style:    discordgo.DangerButton,
-		CustomID: DeleteButtonID,
+		CustomID: NewCustomID(ActionDelete, bookmarkID).Encode(),
 		Emoji:    discordgo.ComponentEmoji{Name: "🗑️"},
 	})
 
@@ -323,7 +651,7 @@ func buildCompleteBookmark(msg *discordgo.Message, channelName, jumpURL string,
 	}
 }
 
-func buildBalancedBookmark(msg *discordgo.Message, channelName, jumpURL string, color int, schedule *reminders.Schedule) *discordgo.MessageSend {
+func buildBalancedBookmark(bookmarkID string, msg *discordgo.Message, channelName, jumpURL string, color int, schedule *reminders.Schedule) *discordgo.MessageSend {
 	infoEmbed := buildInfoEmbed("🔖 Smart Save", msg, channelName, jumpURL, color, false, schedule)
 
 	embeds := []*discordgo.MessageEmbed{infoEmbed}
@@ -337,7 +665,7 @@ func buildBalancedBookmark(msg *discordgo.Message, channelName, jumpURL string,
 		buttons = append(buttons, discordgo.Button{
 			Label:    "Done",
 			Style:    discordgo.SuccessButton,
-			CustomID: CompleteButtonID,
+			CustomID: NewCustomID(ActionComplete, bookmarkID).Encode(),
 			Emoji:    discordgo.ComponentEmoji{Name: "✅"},
 		})
 	}
@@ -345,7 +673,7 @@ func buildBalancedBookmark(msg *discordgo.Message, channelName, jumpURL string,
 	buttons = append(buttons, discordgo.Button{
 		Label:    "Remove",
 		Style:    discordgo.DangerButton,
-		CustomID: DeleteButtonID,
+		CustomID: NewCustomID(ActionDelete, bookmarkID).Encode(),
 		Emoji:    discordgo.ComponentEmoji{Name: "🗑️"},
 	})
 
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"log"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ComponentAction handles a component interaction whose custom ID decoded to id.
+type ComponentAction func(s *discordgo.Session, i *discordgo.InteractionCreate, id CustomID)
+
+// ComponentRegistry routes component interactions to the action named in their custom ID.
+type ComponentRegistry struct {
+	actions map[string]ComponentAction
+}
+
+// NewComponentRegistry constructs an empty registry.
+func NewComponentRegistry() *ComponentRegistry {
+	return &ComponentRegistry{actions: make(map[string]ComponentAction)}
+}
+
+// Register associates action with handler, replacing any previous handler.
+func (r *ComponentRegistry) Register(action string, handler ComponentAction) {
+	r.actions[action] = handler
+}
+
+// Dispatch decodes the interaction's custom ID and invokes the matching action. It returns
+// false when the custom ID is not recognised.
+func (r *ComponentRegistry) Dispatch(s *discordgo.Session, i *discordgo.InteractionCreate) bool {
+	raw := i.MessageComponentData().CustomID
+
+	id, err := ParseCustomID(raw)
+	if err != nil {
+		log.Printf("ignoring component interaction: %v", err)
+		return false
+	}
+
+	handler, ok := r.actions[id.Action]
+	if !ok {
+		log.Printf("no component action registered for %q", id.Action)
+		return false
+	}
+
+	handler(s, i, id)
+	return true
+}
@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/example/discord-bookmark-manager/internal/store"
+)
+
+// savedByFieldName names the embed field listing everyone who saved a shared channel post.
+const savedByFieldName = "📌 Saved by"
+
+// joinShared adds userID to the savers of bookmark, an existing post of the same source
+// message in the same channel, instead of posting it again. The saver's reactions are
+// removed and their reminder scheduled as if the post had just been delivered.
+func (h *ReactionHandler) joinShared(s *discordgo.Session, userID string, bookmark store.Bookmark, reactions []reactionRef, reminder *pendingReminder) {
+	h.sharedMu.Lock()
+	defer h.sharedMu.Unlock()
+
+	updated, added, err := h.bookmarks.AddSaver(bookmark.ID, userID, time.Now())
+	if err != nil {
+		log.Printf("failed to add saver to bookmark %s: %v", bookmark.ID, err)
+		return
+	}
+
+	if added {
+		if err := updateSharedPost(s, updated); err != nil {
+			log.Printf("failed to update shared bookmark %s: %v", bookmark.ID, err)
+		}
+	}
+
+	for _, ref := range reactions {
+		removeReaction(s, ref)
+	}
+
+	if !added || reminder == nil || h.reminders == nil {
+		return
+	}
+
+	dmChannel, err := s.UserChannelCreate(userID)
+	if err != nil {
+		log.Printf("failed to create DM channel for reminder: %v", err)
+		return
+	}
+
+	payload := reminderPayload(updated, userID, reminder)
+	payload.ChannelID = dmChannel.ID
+	payload.Email = h.reminderEmail(userID)
+	h.reminders.Schedule(saverReminderID(updated, userID), reminder.When, payload, reminder.RemoveOnComplete)
+}
+
+// updateSharedPost refreshes the saved-by field of a shared post and makes sure it has a Done
+// button, which every saver uses for their own state.
+func updateSharedPost(s *discordgo.Session, bookmark store.Bookmark) error {
+	saved, err := s.ChannelMessage(bookmark.SavedChannelID, bookmark.SavedMessageID)
+	if err != nil {
+		return err
+	}
+
+	components := withDoneButton(saved.Components, bookmark.ID)
+	_, err = s.ChannelMessageEditComplex(&discordgo.MessageEdit{
+		Channel:    bookmark.SavedChannelID,
+		ID:         bookmark.SavedMessageID,
+		Embeds:     applySavedBy(saved.Embeds, bookmark.Savers),
+		Components: components,
+	})
+	return err
+}
+
+// applySavedBy returns copies of embeds whose first embed lists savers.
+func applySavedBy(embeds []*discordgo.MessageEmbed, savers []store.Saver) []*discordgo.MessageEmbed {
+	return withEmbedField(embeds, savedByFieldName, describeSavers(savers))
+}
+
+// withEmbedField returns copies of embeds whose first embed has the field name set to value,
+// or removed when value is empty.
+func withEmbedField(embeds []*discordgo.MessageEmbed, name, value string) []*discordgo.MessageEmbed {
+	updated := make([]*discordgo.MessageEmbed, len(embeds))
+	for idx, embed := range embeds {
+		updated[idx] = cloneEmbed(embed)
+	}
+	if len(updated) == 0 || updated[0] == nil {
+		return updated
+	}
+
+	fields := make([]*discordgo.MessageEmbedField, 0, len(updated[0].Fields)+1)
+	found := false
+	for _, field := range updated[0].Fields {
+		if field != nil && field.Name == name {
+			if found || value == "" {
+				continue
+			}
+			field.Value = value
+			found = true
+		}
+		fields = append(fields, field)
+	}
+	if !found && value != "" {
+		fields = append(fields, &discordgo.MessageEmbedField{Name: name, Value: value})
+	}
+	updated[0].Fields = fields
+
+	return updated
+}
+
+func describeSavers(savers []store.Saver) string {
+	lines := make([]string, 0, len(savers))
+	for _, saver := range savers {
+		line := fmt.Sprintf("<@%s>", saver.UserID)
+		if saver.Done {
+			line += " ✅"
+		}
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// withDoneButton returns components with a Done button for bookmarkID, added in front of the
+// first row of bookmark buttons when there is none yet.
+func withDoneButton(components []discordgo.MessageComponent, bookmarkID string) []discordgo.MessageComponent {
+	done := discordgo.Button{
+		Label:    "Done",
+		Style:    discordgo.SuccessButton,
+		CustomID: NewCustomID(ActionComplete, bookmarkID).Encode(),
+		Emoji:    discordgo.ComponentEmoji{Name: "✅"},
+	}
+
+	result := make([]discordgo.MessageComponent, 0, len(components)+1)
+	added := false
+	for _, component := range components {
+		var row []discordgo.MessageComponent
+		switch typed := component.(type) {
+		case *discordgo.ActionsRow:
+			row = typed.Components
+		case discordgo.ActionsRow:
+			row = typed.Components
+		default:
+			result = append(result, component)
+			continue
+		}
+
+		for _, child := range row {
+			if isActionButton(child, ActionComplete) {
+				added = true
+			}
+		}
+		if !added && len(row) < 5 {
+			row = append([]discordgo.MessageComponent{done}, row...)
+			added = true
+		}
+		result = append(result, discordgo.ActionsRow{Components: row})
+	}
+
+	if !added {
+		result = append(result, discordgo.ActionsRow{Components: []discordgo.MessageComponent{done}})
+	}
+
+	return result
+}
+
+// isActionButton reports whether component is a button whose custom ID carries action.
+func isActionButton(component discordgo.MessageComponent, action string) bool {
+	var customID string
+	switch button := component.(type) {
+	case *discordgo.Button:
+		customID = button.CustomID
+	case discordgo.Button:
+		customID = button.CustomID
+	default:
+		return false
+	}
+
+	id, err := ParseCustomID(customID)
+	return err == nil && id.Action == action
+}
+
+// saverReminderID is the reminder ID of userID on bookmark. The original saver's reminder is
+// keyed by the saved message, like any other bookmark's.
+func saverReminderID(bookmark store.Bookmark, userID string) string {
+	if userID == bookmark.UserID {
+		return bookmark.SavedMessageID
+	}
+
+	return bookmark.SavedMessageID + ":" + userID
+}
+
+// reminderIDs lists the reminders of every saver of bookmark.
+func reminderIDs(bookmark store.Bookmark) []string {
+	ids := []string{bookmark.SavedMessageID}
+	for _, saver := range bookmark.Savers {
+		if saver.UserID != bookmark.UserID {
+			ids = append(ids, saverReminderID(bookmark, saver.UserID))
+		}
+	}
+
+	return ids
+}
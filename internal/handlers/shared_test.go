@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/example/discord-bookmark-manager/internal/outbox"
+	"github.com/example/discord-bookmark-manager/internal/store"
+)
+
+func TestApplySavedByReplacesField(t *testing.T) {
+	embeds := []*discordgo.MessageEmbed{{Title: "🔖 Smart Save"}}
+
+	first := applySavedBy(embeds, []store.Saver{{UserID: "alice"}, {UserID: "bob"}})
+	second := applySavedBy(first, []store.Saver{{UserID: "alice"}, {UserID: "bob", Done: true}})
+
+	if len(embeds[0].Fields) != 0 {
+		t.Fatalf("expected the original embeds to stay untouched")
+	}
+	if len(second[0].Fields) != 1 || second[0].Fields[0].Name != savedByFieldName {
+		t.Fatalf("expected a single saved-by field, got %+v", second[0].Fields)
+	}
+	if want := "<@alice>\n<@bob> ✅"; second[0].Fields[0].Value != want {
+		t.Fatalf("expected %q, got %q", want, second[0].Fields[0].Value)
+	}
+}
+
+func TestWithDoneButtonAddsOnce(t *testing.T) {
+	components := []discordgo.MessageComponent{
+		&discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			&discordgo.Button{Style: discordgo.DangerButton, CustomID: NewCustomID(ActionDelete, "id").Encode()},
+		}},
+	}
+
+	once := withDoneButton(components, "id")
+	twice := withDoneButton(once, "id")
+
+	row := twice[0].(discordgo.ActionsRow)
+	if len(twice) != 1 || len(row.Components) != 2 {
+		t.Fatalf("expected Done and Remove in one row, got %+v", twice)
+	}
+	if !isActionButton(row.Components[0], ActionComplete) || !isActionButton(row.Components[1], ActionDelete) {
+		t.Fatalf("expected Done before Remove, got %+v", row.Components)
+	}
+}
+
+func TestDeliveredMergesConcurrentChannelSaves(t *testing.T) {
+	s, calls := recordingSession(t, func(r *http.Request) (int, string) {
+		if r.Method == http.MethodGet {
+			return http.StatusOK, `{"id":"first-post","channel_id":"team","embeds":[{"title":"Bookmark"}]}`
+		}
+		if r.Method == http.MethodDelete {
+			return http.StatusNoContent, ""
+		}
+		return http.StatusOK, `{"id":"first-post","channel_id":"team"}`
+	})
+
+	bookmarks, err := store.NewBookmarkStore("")
+	if err != nil {
+		t.Fatalf("NewBookmarkStore returned error: %v", err)
+	}
+	deliveries, err := outbox.NewService(nil, "")
+	if err != nil {
+		t.Fatalf("NewService returned error: %v", err)
+	}
+	h := NewReactionHandler(nil, bookmarks, nil, nil, nil, deliveries, nil, nil, nil)
+
+	deliver := func(id, userID, savedID string) {
+		meta, err := json.Marshal(deliveryMeta{Bookmark: store.Bookmark{
+			ID:              id,
+			UserID:          userID,
+			Destination:     store.DestinationChannel,
+			SourceMessageID: "source",
+		}})
+		if err != nil {
+			t.Fatalf("encoding meta returned error: %v", err)
+		}
+		h.delivered(s, outbox.Delivery{ID: id, UserID: userID, ChannelID: "team", Meta: meta}, &discordgo.Message{ID: savedID, ChannelID: "team"})
+	}
+	deliver("first", "alice", "first-post")
+	deliver("second", "bob", "second-post")
+
+	if _, ok := bookmarks.Get("second"); ok {
+		t.Fatalf("expected the late save not to be recorded as its own post")
+	}
+	first, _ := bookmarks.Get("first")
+	if !first.SavedBy("bob") || !first.Shared() {
+		t.Fatalf("expected bob to join the first post, got %+v", first.Savers)
+	}
+
+	deleted := false
+	for _, call := range calls() {
+		if call == "DELETE /api/v9/channels/team/messages/second-post" {
+			deleted = true
+		}
+	}
+	if !deleted {
+		t.Fatalf("expected the duplicate post to be deleted, got %v", calls())
+	}
+}
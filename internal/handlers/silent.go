@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"log"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// botCanManageMessages reports whether the bot may remove other users' reactions in the
+// channel. Reactions in DMs can never be removed by the bot.
+func botCanManageMessages(s *discordgo.Session, guildID, channelID string) bool {
+	if guildID == "" || s.State.User == nil {
+		return false
+	}
+
+	perms, err := s.State.UserChannelPermissions(s.State.User.ID, channelID)
+	if err != nil {
+		perms, err = s.UserChannelPermissions(s.State.User.ID, channelID)
+		if err != nil {
+			log.Printf("failed to check bot permissions: %v", err)
+			return false
+		}
+	}
+
+	return perms&discordgo.PermissionManageMessages != 0
+}
+
+// noteSilentSave records on the bookmark that it was saved silently, and whether the
+// reaction could actually be removed.
+func noteSilentSave(messageSend *discordgo.MessageSend, canRemove bool) {
+	if len(messageSend.Embeds) == 0 || messageSend.Embeds[0] == nil {
+		return
+	}
+
+	text := "🤫 Saved silently — your reaction was removed"
+	if !canRemove {
+		text = "🤫 Silent save requested, but your reaction stays visible (the bot needs Manage Messages in the source channel)"
+	}
+
+	messageSend.Embeds[0].Footer = &discordgo.MessageEmbedFooter{Text: text}
+}
+
+// reactionRef identifies one user's reaction on a message.
+type reactionRef struct {
+	ChannelID string `json:"channelId"`
+	MessageID string `json:"messageId"`
+	Emoji     string `json:"emoji"`
+	UserID    string `json:"userId"`
+}
+
+func removeReaction(s *discordgo.Session, ref reactionRef) {
+	if err := s.MessageReactionRemove(ref.ChannelID, ref.MessageID, ref.Emoji, ref.UserID); err != nil {
+		log.Printf("failed to remove reaction for silent save: %v", err)
+	}
+}
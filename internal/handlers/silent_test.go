@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/example/discord-bookmark-manager/internal/outbox"
+	"github.com/example/discord-bookmark-manager/internal/store"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (fn roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return fn(r)
+}
+
+// recordingSession returns a session whose REST calls are recorded as "METHOD path" and
+// answered by respond, or with an empty success when respond is nil.
+func recordingSession(t *testing.T, respond func(r *http.Request) (int, string)) (*discordgo.Session, func() []string) {
+	t.Helper()
+
+	s, err := discordgo.New("Bot test")
+	if err != nil {
+		t.Fatalf("discordgo.New returned error: %v", err)
+	}
+
+	var mu sync.Mutex
+	var calls []string
+	s.Client = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		mu.Lock()
+		calls = append(calls, r.Method+" "+r.URL.EscapedPath())
+		mu.Unlock()
+
+		status, body := http.StatusNoContent, ""
+		if respond != nil {
+			status, body = respond(r)
+		}
+		return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(body)), Header: http.Header{}, Request: r}, nil
+	})}
+
+	return s, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), calls...)
+	}
+}
+
+func TestNoteSilentSave(t *testing.T) {
+	removed := &discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{{}}}
+	noteSilentSave(removed, true)
+	if footer := removed.Embeds[0].Footer; footer == nil || !strings.Contains(footer.Text, "your reaction was removed") {
+		t.Fatalf("expected a silent save footer, got %+v", footer)
+	}
+
+	kept := &discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{{}}}
+	noteSilentSave(kept, false)
+	if footer := kept.Embeds[0].Footer; footer == nil || !strings.Contains(footer.Text, "needs Manage Messages") {
+		t.Fatalf("expected the missing permission to be explained, got %+v", footer)
+	}
+
+	noteSilentSave(&discordgo.MessageSend{Content: "no embed"}, true)
+}
+
+func TestSilentSaveFooterSurvivesRefresh(t *testing.T) {
+	embed := &discordgo.MessageEmbed{}
+	carryOverSavedFields(embed, &discordgo.Message{Embeds: []*discordgo.MessageEmbed{{
+		Footer: &discordgo.MessageEmbedFooter{Text: "🤫 Saved silently — your reaction was removed"},
+	}}})
+
+	if embed.Footer == nil || embed.Footer.Text != "🤫 Saved silently — your reaction was removed" {
+		t.Fatalf("expected the footer to be carried over, got %+v", embed.Footer)
+	}
+}
+
+func TestBotCanManageMessages(t *testing.T) {
+	state := discordgo.NewState()
+	state.User = &discordgo.User{ID: "bot"}
+	if err := state.GuildAdd(&discordgo.Guild{
+		ID: "guild",
+		Roles: []*discordgo.Role{
+			{ID: "guild", Permissions: discordgo.PermissionViewChannel},
+			{ID: "mod", Permissions: discordgo.PermissionManageMessages},
+		},
+		Channels: []*discordgo.Channel{
+			{ID: "open", GuildID: "guild"},
+			{ID: "locked", GuildID: "guild", PermissionOverwrites: []*discordgo.PermissionOverwrite{
+				{ID: "mod", Type: discordgo.PermissionOverwriteTypeRole, Deny: discordgo.PermissionManageMessages},
+			}},
+		},
+		Members: []*discordgo.Member{{GuildID: "guild", User: &discordgo.User{ID: "bot"}, Roles: []string{"mod"}}},
+	}); err != nil {
+		t.Fatalf("GuildAdd returned error: %v", err)
+	}
+	s := &discordgo.Session{State: state}
+
+	if !botCanManageMessages(s, "guild", "open") {
+		t.Fatalf("expected the mod role to allow removing reactions")
+	}
+	if botCanManageMessages(s, "guild", "locked") {
+		t.Fatalf("expected the channel overwrite to deny removing reactions")
+	}
+	if botCanManageMessages(s, "", "dm") {
+		t.Fatalf("expected reactions in DMs never to be removable")
+	}
+}
+
+func TestDeliveredRemovesSilentReactions(t *testing.T) {
+	s, calls := recordingSession(t, nil)
+
+	bookmarks, err := store.NewBookmarkStore("")
+	if err != nil {
+		t.Fatalf("NewBookmarkStore returned error: %v", err)
+	}
+	deliveries, err := outbox.NewService(nil, "")
+	if err != nil {
+		t.Fatalf("NewService returned error: %v", err)
+	}
+	h := NewReactionHandler(nil, bookmarks, nil, nil, nil, deliveries, nil, nil, nil)
+
+	meta, err := json.Marshal(deliveryMeta{
+		Bookmark:  store.Bookmark{ID: "bookmark", UserID: "user", SourceMessageID: "source"},
+		Reactions: []reactionRef{{ChannelID: "channel", MessageID: "source", Emoji: "🔖", UserID: "user"}},
+	})
+	if err != nil {
+		t.Fatalf("encoding meta returned error: %v", err)
+	}
+	h.delivered(s, outbox.Delivery{ID: "bookmark", UserID: "user", Meta: meta}, &discordgo.Message{ID: "saved", ChannelID: "dm"})
+
+	if _, ok := bookmarks.BySavedMessage("saved"); !ok {
+		t.Fatalf("expected the delivered bookmark to be recorded")
+	}
+	got := calls()
+	if len(got) != 1 || !strings.HasPrefix(got[0], "DELETE /api/v9/channels/channel/messages/source/reactions/") || !strings.HasSuffix(got[0], "/user") {
+		t.Fatalf("expected the silent save reaction to be removed, got %v", got)
+	}
+}
+
+func TestOnlyFirstDeliveredTargetRemovesSilentReactions(t *testing.T) {
+	s, calls := recordingSession(t, nil)
+
+	bookmarks, err := store.NewBookmarkStore("")
+	if err != nil {
+		t.Fatalf("NewBookmarkStore returned error: %v", err)
+	}
+	deliveries, err := outbox.NewService(nil, "")
+	if err != nil {
+		t.Fatalf("NewService returned error: %v", err)
+	}
+	h := NewReactionHandler(nil, bookmarks, nil, nil, nil, deliveries, nil, nil, nil)
+
+	for idx, saved := range []string{"first", "second"} {
+		meta, err := json.Marshal(deliveryMeta{
+			Bookmark:  store.Bookmark{ID: saved, UserID: "user", SourceMessageID: "source"},
+			SaveID:    "save",
+			Reactions: []reactionRef{{ChannelID: "channel", MessageID: "source", Emoji: "🔖", UserID: "user"}},
+		})
+		if err != nil {
+			t.Fatalf("encoding meta returned error: %v", err)
+		}
+		h.delivered(s, outbox.Delivery{ID: saved, UserID: "user", Meta: meta}, &discordgo.Message{ID: saved, ChannelID: "dm"})
+
+		if got := calls(); len(got) != 1 {
+			t.Fatalf("after target %d: expected the reaction to be removed once, got %v", idx+1, got)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/example/discord-bookmark-manager/internal/emojikey"
+	"github.com/example/discord-bookmark-manager/internal/store"
+)
+
+// reactionRemoveEmojiEvent is the gateway event for every reaction of one emoji being removed
+// from a message. discordgo only delivers it as a raw *discordgo.Event.
+const reactionRemoveEmojiEvent = "MESSAGE_REACTION_REMOVE_EMOJI"
+
+const (
+	starboardColor = 0xFFAC33
+	// reactorsPageSize is the most users Discord returns per reactions request.
+	reactorsPageSize = 100
+	// starboardRecountMargin is how far above the threshold a showcase post's count has to be
+	// before reactions adjust it by one instead of paging through every reactor.
+	starboardRecountMargin = 10
+)
+
+// HandleRemove reacts to MessageReactionRemove events. Only the starboard cares about
+// reactions going away.
+func (h *ReactionHandler) HandleRemove(s *discordgo.Session, event *discordgo.MessageReactionRemove) {
+	if botUser := s.State.User; botUser != nil && event.UserID == botUser.ID {
+		return
+	}
+
+	h.updateStarboard(s, event.MessageReaction, -1)
+}
+
+// HandleRemoveAll reacts to MessageReactionRemoveAll events by taking down the message's
+// showcase post.
+func (h *ReactionHandler) HandleRemoveAll(s *discordgo.Session, event *discordgo.MessageReactionRemoveAll) {
+	if h.starboard == nil {
+		return
+	}
+
+	h.clearStarboard(s, event.MessageID)
+}
+
+// HandleEvent picks the events discordgo has no type for out of the raw event stream. When
+// every starboard reaction is removed from a message, its showcase post is taken down.
+func (h *ReactionHandler) HandleEvent(s *discordgo.Session, event *discordgo.Event) {
+	if event.Type != reactionRemoveEmojiEvent || h.starboard == nil || h.guilds == nil {
+		return
+	}
+
+	var reaction discordgo.MessageReaction
+	if err := json.Unmarshal(event.RawData, &reaction); err != nil {
+		log.Printf("failed to decode %s event: %v", event.Type, err)
+		return
+	}
+	if reaction.GuildID == "" {
+		return
+	}
+
+	settings := h.guilds.Get(reaction.GuildID).Starboard
+	if settings == nil || !emojikey.Same(reactionKey(&reaction.Emoji), settings.Emoji, false) {
+		return
+	}
+
+	h.clearStarboard(s, reaction.MessageID)
+}
+
+// updateStarboard counts the starboard emoji on the reacted message and brings its showcase
+// post in line: it is posted once the count reaches the server's threshold, its count is
+// updated while it stays there, and it is deleted when the count drops below. delta is +1 for
+// an added reaction and -1 for a removed one. Reactors are only paged through near the
+// threshold; well above it the shown count moves by delta.
+func (h *ReactionHandler) updateStarboard(s *discordgo.Session, reaction *discordgo.MessageReaction, delta int) {
+	if h.starboard == nil || h.guilds == nil || reaction.GuildID == "" {
+		return
+	}
+
+	settings := h.guilds.Get(reaction.GuildID).Starboard
+	if settings == nil || !emojikey.Same(reactionKey(&reaction.Emoji), settings.Emoji, false) {
+		return
+	}
+
+	// Reactions on the showcase posts themselves never count.
+	if reaction.ChannelID == settings.ChannelID {
+		return
+	}
+
+	// Adds and removes for one message arrive in bursts; serialize them so the message is
+	// posted once.
+	unlock := h.starboardLocks.lock(reaction.MessageID)
+	defer unlock()
+
+	msg, err := s.ChannelMessage(reaction.ChannelID, reaction.MessageID)
+	if err != nil {
+		log.Printf("failed to fetch starboard candidate %s: %v", reaction.MessageID, err)
+		return
+	}
+
+	key := reactionKey(&reaction.Emoji)
+	post, posted := h.starboard.Get(msg.ID)
+
+	var count int
+	switch {
+	case !posted && reactionTotal(msg, key) < settings.Threshold:
+		// Even counting bots and the author the message is short of the threshold.
+		return
+	case posted && post.Count >= settings.Threshold+starboardRecountMargin:
+		count = post.Count
+		if countsForStarboard(s, reaction.GuildID, msg, reaction.UserID) {
+			count += delta
+		}
+	default:
+		count, err = countReactors(s, msg, key)
+		if err != nil {
+			log.Printf("failed to count starboard reactions on %s: %v", msg.ID, err)
+			return
+		}
+	}
+
+	switch {
+	case count >= settings.Threshold && !posted:
+		h.postStarboard(s, reaction, settings, msg, count)
+	case count >= settings.Threshold && count != post.Count:
+		content := starboardContent(settings.Emoji, count, reaction.ChannelID)
+		if _, err := s.ChannelMessageEditComplex(&discordgo.MessageEdit{
+			ID:      post.MessageID,
+			Channel: post.ChannelID,
+			Content: &content,
+		}); err != nil {
+			log.Printf("failed to update starboard post %s: %v", post.MessageID, err)
+			return
+		}
+
+		post.Count = count
+		if err := h.starboard.Put(post); err != nil {
+			log.Printf("failed to record starboard post: %v", err)
+		}
+	case count < settings.Threshold && posted:
+		h.removeStarboardPost(s, post)
+	}
+}
+
+// clearStarboard takes down the showcase post of messageID after its reactions were removed.
+func (h *ReactionHandler) clearStarboard(s *discordgo.Session, messageID string) {
+	unlock := h.starboardLocks.lock(messageID)
+	defer unlock()
+
+	if post, posted := h.starboard.Get(messageID); posted {
+		h.removeStarboardPost(s, post)
+	}
+}
+
+func (h *ReactionHandler) removeStarboardPost(s *discordgo.Session, post store.StarboardPost) {
+	if err := s.ChannelMessageDelete(post.ChannelID, post.MessageID); err != nil {
+		log.Printf("failed to delete starboard post %s: %v", post.MessageID, err)
+	}
+	if _, err := h.starboard.Delete(post.SourceMessageID); err != nil {
+		log.Printf("failed to forget starboard post: %v", err)
+	}
+}
+
+// postStarboard showcases msg in the balanced layout. The server's privacy setting applies
+// when the showcase channel has a broader audience than the source.
+func (h *ReactionHandler) postStarboard(s *discordgo.Session, reaction *discordgo.MessageReaction, settings *store.StarboardSettings, msg *discordgo.Message, count int) {
+	channel, err := fetchChannel(s, settings.ChannelID)
+	if err != nil {
+		log.Printf("failed to resolve starboard channel %s: %v", settings.ChannelID, err)
+		return
+	}
+
+	source := msg
+	if check := checkAudience(s, reaction.GuildID, reaction.ChannelID, channel); check.broader {
+		if h.privacyAction(reaction.GuildID, channel.GuildID) != store.PrivacyRedact {
+			log.Printf("not showcasing message %s: %s", msg.ID, check.reason)
+			return
+		}
+		source = redactMessage(msg)
+	}
+
+	channelName := fetchChannelName(s, reaction.ChannelID)
+	jumpURL := buildJumpLink(reaction.GuildID, reaction.ChannelID, msg.ID)
+	messageSend := buildStarboardPost(settings.Emoji, count, source, reaction.ChannelID, channelName, jumpURL)
+
+	sent, err := s.ChannelMessageSendComplex(channel.ID, messageSend)
+	if err != nil {
+		log.Printf("failed to post to starboard channel %s: %v", channel.ID, err)
+		return
+	}
+
+	if err := h.starboard.Put(store.StarboardPost{
+		GuildID:         reaction.GuildID,
+		SourceChannelID: reaction.ChannelID,
+		SourceMessageID: msg.ID,
+		ChannelID:       sent.ChannelID,
+		MessageID:       sent.ID,
+		Count:           count,
+	}); err != nil {
+		log.Printf("failed to record starboard post: %v", err)
+	}
+}
+
+// buildStarboardPost renders msg with the balanced layout, replacing the bookmark buttons with
+// a link to the source.
+func buildStarboardPost(emoji string, count int, msg *discordgo.Message, channelID, channelName, jumpURL string) *discordgo.MessageSend {
+	messageSend := buildBalancedBookmark(store.NewBookmarkID(), msg, channelName, jumpURL, starboardColor, nil)
+	messageSend.Content = starboardContent(emoji, count, channelID)
+	messageSend.Embeds[0].Title = "🌟 Hall of Fame"
+	messageSend.Components = []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.Button{
+				Style: discordgo.LinkButton,
+				Label: "Source",
+				URL:   jumpURL,
+				Emoji: discordgo.ComponentEmoji{Name: "🔗"},
+			},
+		}},
+	}
+
+	return messageSend
+}
+
+func starboardContent(emoji string, count int, channelID string) string {
+	return fmt.Sprintf("%s **%d** · <#%s>", formatReactionKey(emoji), count, channelID)
+}
+
+// formatReactionKey renders a stored reaction key as message text; custom emoji keys are
+// "name:id", or "a:name:id" in settings saved before animated emoji lost their prefix.
+func formatReactionKey(key string) string {
+	switch strings.Count(key, ":") {
+	case 1:
+		return "<:" + key + ">"
+	case 2:
+		return "<" + key + ">"
+	}
+
+	return emojikey.Display(key)
+}
+
+// reactionTotal returns how many reactions with emoji msg carries, bots and the author
+// included.
+func reactionTotal(msg *discordgo.Message, emoji string) int {
+	for _, reaction := range msg.Reactions {
+		if reaction != nil && reaction.Emoji != nil && reactionKey(reaction.Emoji) == emoji {
+			return reaction.Count
+		}
+	}
+
+	return 0
+}
+
+// countsForStarboard reports whether a reaction by userID counts towards msg's showcase post.
+// Bots are recognized from the session state; members missing from it are assumed human.
+func countsForStarboard(s *discordgo.Session, guildID string, msg *discordgo.Message, userID string) bool {
+	if msg.Author != nil && msg.Author.ID == userID {
+		return false
+	}
+
+	if member, err := s.State.Member(guildID, userID); err == nil && member.User != nil {
+		return !member.User.Bot
+	}
+
+	return true
+}
+
+// countReactors returns how many distinct members reacted to msg with emoji, leaving out bots
+// and the message author.
+func countReactors(s *discordgo.Session, msg *discordgo.Message, emoji string) (int, error) {
+	count := 0
+	after := ""
+	for {
+		users, err := s.MessageReactions(msg.ChannelID, msg.ID, emoji, reactorsPageSize, "", after)
+		if err != nil {
+			return 0, err
+		}
+
+		for _, user := range users {
+			if user.Bot || (msg.Author != nil && user.ID == msg.Author.ID) {
+				continue
+			}
+			count++
+		}
+
+		if len(users) < reactorsPageSize {
+			return count, nil
+		}
+		after = users[len(users)-1].ID
+	}
+}
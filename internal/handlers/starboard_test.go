@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/example/discord-bookmark-manager/internal/outbox"
+	"github.com/example/discord-bookmark-manager/internal/store"
+)
+
+func TestBuildStarboardPostLinksOnlyToSource(t *testing.T) {
+	msg := &discordgo.Message{ID: "message", ChannelID: "general", Content: "Worth remembering", Author: &discordgo.User{ID: "author", Username: "author"}}
+	jumpURL := buildJumpLink("guild", "general", "message")
+
+	post := buildStarboardPost("star:123", 5, msg, "general", "general", jumpURL)
+
+	if post.Content != "<:star:123> **5** · <#general>" {
+		t.Fatalf("unexpected content %q", post.Content)
+	}
+	if !strings.Contains(post.Embeds[0].Title, "Hall of Fame") {
+		t.Fatalf("unexpected title %q", post.Embeds[0].Title)
+	}
+
+	row := post.Components[0].(discordgo.ActionsRow)
+	if len(row.Components) != 1 {
+		t.Fatalf("expected only the source link, got %d buttons", len(row.Components))
+	}
+	if button := row.Components[0].(discordgo.Button); button.Style != discordgo.LinkButton || button.URL != jumpURL {
+		t.Fatalf("expected a link to the source, got %+v", button)
+	}
+}
+
+func TestFormatReactionKey(t *testing.T) {
+	for key, want := range map[string]string{
+		"⭐":          "⭐",
+		"star:123":   "<:star:123>",
+		"a:spin:456": "<a:spin:456>",
+	} {
+		if got := formatReactionKey(key); got != want {
+			t.Errorf("formatReactionKey(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func starboardHandler(t *testing.T, threshold int) (*ReactionHandler, *store.StarboardStore) {
+	t.Helper()
+
+	guilds, err := store.NewGuildStore("")
+	if err != nil {
+		t.Fatalf("NewGuildStore returned error: %v", err)
+	}
+	if err := guilds.Update("guild", func(settings *store.GuildSettings) {
+		settings.Starboard = &store.StarboardSettings{Emoji: "⭐", ChannelID: "hall", Threshold: threshold}
+	}); err != nil {
+		t.Fatalf("Update returned error: %v", err)
+	}
+	starboard, err := store.NewStarboardStore("")
+	if err != nil {
+		t.Fatalf("NewStarboardStore returned error: %v", err)
+	}
+	deliveries, err := outbox.NewService(nil, "")
+	if err != nil {
+		t.Fatalf("NewService returned error: %v", err)
+	}
+
+	return NewReactionHandler(nil, nil, guilds, starboard, nil, deliveries, nil, nil, nil), starboard
+}
+
+// starredMessage answers message fetches with a message carrying stars ⭐ reactions.
+func starredMessage(stars int) func(r *http.Request) (int, string) {
+	return func(r *http.Request) (int, string) {
+		if r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/messages/message") {
+			return http.StatusOK, fmt.Sprintf(`{"id":"message","channel_id":"general","author":{"id":"author"},"reactions":[{"count":%d,"emoji":{"name":"⭐"}}]}`, stars)
+		}
+		return http.StatusOK, `{"id":"edited","channel_id":"hall"}`
+	}
+}
+
+func starReaction(userID string) *discordgo.MessageReaction {
+	return &discordgo.MessageReaction{UserID: userID, MessageID: "message", ChannelID: "general", GuildID: "guild", Emoji: discordgo.Emoji{Name: "⭐"}}
+}
+
+func TestStarboardSkipsCountingBelowThreshold(t *testing.T) {
+	h, _ := starboardHandler(t, 3)
+	s, calls := recordingSession(t, starredMessage(2))
+
+	h.updateStarboard(s, starReaction("member"), 1)
+
+	for _, call := range calls() {
+		if strings.Contains(call, "/reactions/") {
+			t.Fatalf("expected no reactor paging below the threshold, got %v", calls())
+		}
+	}
+}
+
+func TestStarboardAppliesDeltasWellAboveThreshold(t *testing.T) {
+	h, starboard := starboardHandler(t, 3)
+	if err := starboard.Put(store.StarboardPost{GuildID: "guild", SourceChannelID: "general", SourceMessageID: "message", ChannelID: "hall", MessageID: "post", Count: 20}); err != nil {
+		t.Fatalf("Put returned error: %v", err)
+	}
+	s, calls := recordingSession(t, starredMessage(21))
+
+	h.updateStarboard(s, starReaction("member"), 1)
+	h.updateStarboard(s, starReaction("author"), -1)
+
+	if post, _ := starboard.Get("message"); post.Count != 21 {
+		t.Fatalf("expected the member's star to count and the author's not to, got %d", post.Count)
+	}
+	for _, call := range calls() {
+		if strings.Contains(call, "/reactions/") {
+			t.Fatalf("expected no reactor paging well above the threshold, got %v", calls())
+		}
+	}
+}
+
+func TestStarboardRemoveAllTakesPostDown(t *testing.T) {
+	h, starboard := starboardHandler(t, 3)
+	if err := starboard.Put(store.StarboardPost{GuildID: "guild", SourceChannelID: "general", SourceMessageID: "message", ChannelID: "hall", MessageID: "post", Count: 5}); err != nil {
+		t.Fatalf("Put returned error: %v", err)
+	}
+	s, calls := recordingSession(t, nil)
+
+	h.HandleEvent(s, &discordgo.Event{Type: reactionRemoveEmojiEvent, RawData: []byte(`{"guild_id":"guild","channel_id":"general","message_id":"message","emoji":{"name":"🔥"}}`)})
+	if _, posted := starboard.Get("message"); !posted {
+		t.Fatalf("expected removing another emoji to keep the post")
+	}
+
+	h.HandleRemoveAll(s, &discordgo.MessageReactionRemoveAll{MessageReaction: starReaction("")})
+	if _, posted := starboard.Get("message"); posted {
+		t.Fatalf("expected the post to be forgotten")
+	}
+	if got := calls(); len(got) != 1 || got[0] != "DELETE /api/v9/channels/hall/messages/post" {
+		t.Fatalf("expected the showcase post to be deleted, got %v", got)
+	}
+}
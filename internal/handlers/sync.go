@@ -0,0 +1,325 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/example/discord-bookmark-manager/internal/reminders"
+	"github.com/example/discord-bookmark-manager/internal/store"
+)
+
+// preservedFieldNames lists embed fields added at save time that cannot be regenerated from
+// the source message alone.
+var preservedFieldNames = []string{"🧵 Transcript", archivedFieldName, notArchivedFieldName, savedByFieldName, assigneeFieldName, scoreFieldName}
+
+// SourceSyncHandler keeps saved bookmarks in sync with changes to their source messages.
+type SourceSyncHandler struct {
+	bookmarks *store.BookmarkStore
+	guilds    *store.GuildStore
+	reminders *reminders.Service
+	// feeds keeps the content of edited sources for the users' feeds.
+	feeds bool
+}
+
+// NewSourceSyncHandler constructs a SourceSyncHandler.
+func NewSourceSyncHandler(bookmarks *store.BookmarkStore, guilds *store.GuildStore, reminders *reminders.Service) *SourceSyncHandler {
+	return &SourceSyncHandler{bookmarks: bookmarks, guilds: guilds, reminders: reminders}
+}
+
+// SetFeeds sets whether bookmarks keep the content their feed entries show.
+func (h *SourceSyncHandler) SetFeeds(enabled bool) {
+	h.feeds = enabled
+}
+
+// HandleUpdate reacts to MessageUpdate events and refreshes bookmarks that track edits.
+func (h *SourceSyncHandler) HandleUpdate(s *discordgo.Session, event *discordgo.MessageUpdate) {
+	if event.Message == nil || event.ID == "" {
+		return
+	}
+
+	var tracked []store.Bookmark
+	for _, bookmark := range h.bookmarks.BySource(event.ID) {
+		if bookmark.TrackEdits && !bookmark.SourceDeleted {
+			tracked = append(tracked, bookmark)
+		}
+	}
+	if len(tracked) == 0 {
+		return
+	}
+
+	msg, err := s.ChannelMessage(event.ChannelID, event.ID)
+	if err != nil {
+		log.Printf("failed to fetch edited source message: %v", err)
+		return
+	}
+
+	// Link unfurls also trigger updates; only refresh on real edits.
+	if msg.EditedTimestamp == nil {
+		return
+	}
+
+	for _, bookmark := range tracked {
+		if err := refreshBookmark(s, bookmark, msg); err != nil {
+			log.Printf("failed to refresh bookmark %s: %v", bookmark.ID, err)
+			continue
+		}
+		if err := h.bookmarks.Update(bookmark.ID, func(b *store.Bookmark) { recordContent(b, msg, b.ChannelName, h.feeds) }); err != nil {
+			log.Printf("failed to record edited bookmark %s: %v", bookmark.ID, err)
+		}
+	}
+}
+
+// HandleDelete reacts to MessageDelete events for tracked sources and saved bookmarks.
+func (h *SourceSyncHandler) HandleDelete(s *discordgo.Session, event *discordgo.MessageDelete) {
+	if event.Message == nil || event.ID == "" {
+		return
+	}
+
+	h.sourcesDeleted(s, event.GuildID, []string{event.ID})
+}
+
+// HandleDeleteBulk reacts to MessageDeleteBulk events for tracked sources and saved bookmarks.
+func (h *SourceSyncHandler) HandleDeleteBulk(s *discordgo.Session, event *discordgo.MessageDeleteBulk) {
+	h.sourcesDeleted(s, event.GuildID, event.Messages)
+}
+
+func (h *SourceSyncHandler) sourcesDeleted(s *discordgo.Session, guildID string, messageIDs []string) {
+	action := store.SourceDeleteAnnotate
+	if guildID != "" && h.guilds != nil {
+		action = h.guilds.Get(guildID).OnSourceDelete
+	}
+
+	for _, messageID := range messageIDs {
+		// A saved bookmark removed by hand no longer needs to be tracked.
+		if saved, ok := h.bookmarks.BySavedMessage(messageID); ok {
+			h.forget(saved)
+		}
+
+		for _, bookmark := range h.bookmarks.BySource(messageID) {
+			if bookmark.SourceDeleted {
+				continue
+			}
+
+			switch action {
+			case store.SourceDeletePurge:
+				if err := deleteSavedBookmark(s, bookmark); err != nil {
+					log.Printf("failed to purge bookmark %s: %v", bookmark.ID, err)
+					continue
+				}
+				h.forget(bookmark)
+			default:
+				if err := annotateSourceDeleted(s, bookmark); err != nil {
+					log.Printf("failed to annotate bookmark %s: %v", bookmark.ID, err)
+					continue
+				}
+				if err := h.bookmarks.Update(bookmark.ID, markSourceDeleted); err != nil {
+					log.Printf("failed to record deleted source: %v", err)
+				}
+			}
+		}
+	}
+}
+
+func (h *SourceSyncHandler) forget(bookmark store.Bookmark) {
+	if _, err := h.bookmarks.Delete(bookmark.ID); err != nil {
+		log.Printf("failed to forget bookmark %s: %v", bookmark.ID, err)
+	}
+	if h.reminders != nil {
+		for _, reminderID := range reminderIDs(bookmark) {
+			h.reminders.Cancel(reminderID)
+		}
+	}
+}
+
+// markSourceDeleted records that the source of b is gone. Its feed entry stops showing the
+// deleted content and attachments.
+func markSourceDeleted(b *store.Bookmark) {
+	b.SourceDeleted = true
+	b.Content = ""
+	b.AttachmentURLs = nil
+}
+
+// annotateSourceDeleted marks the saved bookmark as orphaned while keeping its content.
+func annotateSourceDeleted(s *discordgo.Session, bookmark store.Bookmark) error {
+	saved, err := s.ChannelMessage(bookmark.SavedChannelID, bookmark.SavedMessageID)
+	if err != nil {
+		return err
+	}
+
+	embeds := make([]*discordgo.MessageEmbed, len(saved.Embeds))
+	for idx, embed := range saved.Embeds {
+		embeds[idx] = cloneEmbed(embed)
+	}
+
+	if len(embeds) > 0 && embeds[0] != nil {
+		setFieldValue(embeds[0], "🔗 Source Message", "~~Open~~ (deleted)")
+		embeds[0].Fields = append(embeds[0].Fields, &discordgo.MessageEmbedField{
+			Name:  "🗑️ Source deleted",
+			Value: fmt.Sprintf("The original message was deleted on %s. The saved copy is kept here.", time.Now().Format("2006-01-02 15:04")),
+		})
+	}
+
+	_, err = s.ChannelMessageEditComplex(&discordgo.MessageEdit{
+		Channel:    bookmark.SavedChannelID,
+		ID:         bookmark.SavedMessageID,
+		Embeds:     embeds,
+		Components: withoutLinkButtons(saved.Components),
+	})
+	return err
+}
+
+// withoutLinkButtons drops link buttons, such as the Source button in complete mode, and any
+// rows left empty as a result.
+func withoutLinkButtons(components []discordgo.MessageComponent) []discordgo.MessageComponent {
+	result := []discordgo.MessageComponent{}
+	for _, component := range components {
+		var row []discordgo.MessageComponent
+		switch typed := component.(type) {
+		case *discordgo.ActionsRow:
+			row = typed.Components
+		case discordgo.ActionsRow:
+			row = typed.Components
+		default:
+			result = append(result, component)
+			continue
+		}
+
+		kept := []discordgo.MessageComponent{}
+		for _, child := range row {
+			switch button := child.(type) {
+			case *discordgo.Button:
+				if button.Style == discordgo.LinkButton {
+					continue
+				}
+			case discordgo.Button:
+				if button.Style == discordgo.LinkButton {
+					continue
+				}
+			}
+			kept = append(kept, child)
+		}
+
+		if len(kept) > 0 {
+			result = append(result, discordgo.ActionsRow{Components: kept})
+		}
+	}
+
+	return result
+}
+
+// refreshBookmark regenerates the saved copy of bookmark from msg with the original layout.
+func refreshBookmark(s *discordgo.Session, bookmark store.Bookmark, msg *discordgo.Message) error {
+	saved, err := s.ChannelMessage(bookmark.SavedChannelID, bookmark.SavedMessageID)
+	if err != nil {
+		return err
+	}
+
+	var schedule *reminders.Schedule
+	if bookmark.ReminderDescription != "" {
+		schedule = &reminders.Schedule{Description: bookmark.ReminderDescription}
+	}
+
+	jumpURL := buildJumpLink(bookmark.SourceGuildID, bookmark.SourceChannelID, bookmark.SourceMessageID)
+	messageSend := buildBookmark(bookmark.ID, bookmark.Mode, msg, bookmark.ChannelName, jumpURL, bookmark.Color, emojiFromKey(bookmark.Emoji), schedule)
+	if messageSend == nil || len(messageSend.Embeds) == 0 {
+		return nil
+	}
+
+	embed := messageSend.Embeds[0]
+	setFieldValue(embed, "💾 Saved", bookmark.SavedAt.Format("2006-01-02 15:04"))
+	carryOverSavedFields(embed, saved)
+
+	editedAt := time.Now()
+	if msg.EditedTimestamp != nil {
+		editedAt = *msg.EditedTimestamp
+	}
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+		Name:  "✏️ Edited",
+		Value: fmt.Sprintf("Source edited at %s", editedAt.Local().Format("2006-01-02 15:04")),
+	})
+
+	embeds := messageSend.Embeds
+	components := messageSend.Components
+	if bookmark.Completed {
+		embeds = completeEmbeds(embeds)
+		components = []discordgo.MessageComponent{}
+	} else {
+		if bookmark.Shared() {
+			components = withDoneButton(components, bookmark.ID)
+		}
+		if bookmark.Destination == store.DestinationChannel {
+			components = withTeamButtons(components, bookmark.ID)
+		}
+	}
+
+	_, err = s.ChannelMessageEditComplex(&discordgo.MessageEdit{
+		Channel:    bookmark.SavedChannelID,
+		ID:         bookmark.SavedMessageID,
+		Embeds:     embeds,
+		Components: components,
+	})
+	return err
+}
+
+// carryOverSavedFields copies fields, the footer and archived images from the currently saved
+// bookmark onto the regenerated embed.
+func carryOverSavedFields(embed *discordgo.MessageEmbed, saved *discordgo.Message) {
+	if saved == nil || len(saved.Embeds) == 0 || saved.Embeds[0] == nil {
+		return
+	}
+	current := saved.Embeds[0]
+
+	for _, field := range current.Fields {
+		if field == nil {
+			continue
+		}
+		for _, name := range preservedFieldNames {
+			if field.Name == name {
+				copied := *field
+				embed.Fields = append(embed.Fields, &copied)
+			}
+		}
+	}
+
+	// The footer notes silent saves, which the regenerated bookmark knows nothing about.
+	if current.Footer != nil && embed.Footer == nil {
+		copied := *current.Footer
+		embed.Footer = &copied
+	}
+
+	// Archived images are served from the bookmark's own attachments; keep pointing at them.
+	if current.Image == nil {
+		return
+	}
+	for _, attachment := range saved.Attachments {
+		if attachment != nil && attachment.ID != "" && strings.Contains(current.Image.URL, attachment.ID) {
+			embed.Image = &discordgo.MessageEmbedImage{URL: "attachment://" + attachment.Filename}
+			return
+		}
+	}
+}
+
+func setFieldValue(embed *discordgo.MessageEmbed, name, value string) {
+	for _, field := range embed.Fields {
+		if field != nil && field.Name == name {
+			field.Value = value
+		}
+	}
+}
+
+// emojiFromKey rebuilds an emoji from the key produced by reactionKey.
+func emojiFromKey(key string) *discordgo.Emoji {
+	parts := strings.Split(key, ":")
+	switch len(parts) {
+	case 2:
+		return &discordgo.Emoji{Name: parts[0], ID: parts[1]}
+	case 3:
+		return &discordgo.Emoji{Name: parts[1], ID: parts[2], Animated: true}
+	}
+
+	return &discordgo.Emoji{Name: key}
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/example/discord-bookmark-manager/internal/store"
+)
+
+func TestWithoutLinkButtonsDropsSourceButton(t *testing.T) {
+	components := []discordgo.MessageComponent{
+		&discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			&discordgo.Button{Style: discordgo.LinkButton, URL: "https://discord.com/channels/1/2/3"},
+			&discordgo.Button{Style: discordgo.DangerButton, CustomID: DeleteButtonID},
+		}},
+	}
+
+	result := withoutLinkButtons(components)
+	if len(result) != 1 {
+		t.Fatalf("expected one row, got %d", len(result))
+	}
+
+	row, ok := result[0].(discordgo.ActionsRow)
+	if !ok || len(row.Components) != 1 {
+		t.Fatalf("expected a row with only the remove button, got %#v", result[0])
+	}
+	if button, ok := row.Components[0].(*discordgo.Button); !ok || button.CustomID != DeleteButtonID {
+		t.Fatalf("expected remove button to be kept, got %#v", row.Components[0])
+	}
+}
+
+func TestWithoutLinkButtonsDropsEmptyRows(t *testing.T) {
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.Button{Style: discordgo.LinkButton, URL: "https://example.com"},
+		}},
+	}
+
+	if result := withoutLinkButtons(components); len(result) != 0 {
+		t.Fatalf("expected empty rows to be dropped, got %#v", result)
+	}
+}
+
+func TestRecordContentOnlyWithFeeds(t *testing.T) {
+	msg := &discordgo.Message{
+		Content:     "Ship it",
+		Author:      &discordgo.User{Username: "author"},
+		Attachments: []*discordgo.MessageAttachment{{Filename: "plan.pdf", URL: "https://cdn.example/plan.pdf"}},
+	}
+
+	var without store.Bookmark
+	recordContent(&without, msg, "general", false)
+	if without.Title != "Ship it" || without.Content != "" || without.AuthorName != "" || len(without.AttachmentURLs) != 0 {
+		t.Fatalf("expected only the title without feeds, got %+v", without)
+	}
+
+	var with store.Bookmark
+	recordContent(&with, msg, "general", true)
+	if with.Content != "Ship it" || with.AuthorName != "author" || len(with.AttachmentURLs) != 1 {
+		t.Fatalf("expected the feed content, got %+v", with)
+	}
+
+	markSourceDeleted(&with)
+	if !with.SourceDeleted || with.Content != "" || len(with.AttachmentURLs) != 0 {
+		t.Fatalf("expected the deleted source's content to be cleared, got %+v", with)
+	}
+}
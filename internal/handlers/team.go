@@ -0,0 +1,35 @@
+package handlers
+
+import "github.com/example/discord-bookmark-manager/internal/store"
+
+// resolveEmoji returns the preferences a reaction saves with, given the member's personal
+// mapping and the server's team mapping for the same emoji. precedence only matters when
+// both exist.
+func resolveEmoji(personal store.EmojiPreference, hasPersonal bool, team store.EmojiPreference, hasTeam bool, precedence store.EmojiPrecedence) []store.EmojiPreference {
+	switch {
+	case hasPersonal && hasTeam:
+		switch precedence {
+		case store.PrecedenceTeam:
+			return []store.EmojiPreference{team}
+		case store.PrecedencePersonal:
+			return []store.EmojiPreference{personal}
+		}
+		return []store.EmojiPreference{team, personal}
+	case hasTeam:
+		return []store.EmojiPreference{team}
+	case hasPersonal:
+		return []store.EmojiPreference{personal}
+	}
+
+	return nil
+}
+
+// teamEmoji returns the team mapping for emoji in guildID and the server's precedence rule.
+func (h *ReactionHandler) teamEmoji(guildID, emoji string) (store.EmojiPreference, bool, store.EmojiPrecedence) {
+	if h.guilds == nil || guildID == "" {
+		return store.EmojiPreference{}, false, store.PrecedenceBoth
+	}
+
+	pref, ok := h.guilds.TeamEmoji(guildID, emoji, h.foldSkinTones)
+	return pref, ok, h.guilds.Get(guildID).EmojiPrecedence
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/example/discord-bookmark-manager/internal/store"
+)
+
+func TestResolveEmojiPrecedence(t *testing.T) {
+	personal := store.EmojiPreference{Mode: store.ModeLightweight, Destination: store.DestinationDM}
+	team := store.EmojiPreference{Mode: store.ModeBalanced, Destination: store.DestinationChannel, ChannelID: "team-reading-list"}
+
+	tests := []struct {
+		name        string
+		hasPersonal bool
+		hasTeam     bool
+		precedence  store.EmojiPrecedence
+		want        []store.DestinationType
+	}{
+		{name: "personal only", hasPersonal: true, precedence: store.PrecedenceTeam, want: []store.DestinationType{store.DestinationDM}},
+		{name: "team only", hasTeam: true, precedence: store.PrecedencePersonal, want: []store.DestinationType{store.DestinationChannel}},
+		{name: "collision saves both", hasPersonal: true, hasTeam: true, precedence: store.PrecedenceBoth, want: []store.DestinationType{store.DestinationChannel, store.DestinationDM}},
+		{name: "collision team wins", hasPersonal: true, hasTeam: true, precedence: store.PrecedenceTeam, want: []store.DestinationType{store.DestinationChannel}},
+		{name: "collision personal wins", hasPersonal: true, hasTeam: true, precedence: store.PrecedencePersonal, want: []store.DestinationType{store.DestinationDM}},
+		{name: "neither", precedence: store.PrecedenceBoth},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := resolveEmoji(personal, tt.hasPersonal, team, tt.hasTeam, tt.precedence)
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %d preferences, got %+v", len(tt.want), got)
+			}
+			for idx, pref := range got {
+				if pref.Destination != tt.want[idx] {
+					t.Fatalf("preference %d: expected %s, got %s", idx, tt.want[idx], pref.Destination)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/example/discord-bookmark-manager/internal/store"
+	"github.com/example/discord-bookmark-manager/internal/vault"
+)
+
+// maxVaultAttachmentBytes bounds the attachments downloaded for one vault note.
+const maxVaultAttachmentBytes = 100 << 20
+
+// writeVaultNote writes msg into the user's vault and returns the note path relative to the
+// user's vault directory.
+func (h *ReactionHandler) writeVaultNote(event *discordgo.MessageReactionAdd, bookmarkID string, target store.DestinationTarget, msg *discordgo.Message, ctx saveContext) (string, error) {
+	note := vault.Note{
+		ID:        bookmarkID,
+		Title:     forumTitle(msg, ctx.channelName),
+		SourceURL: ctx.jumpURL,
+		Channel:   ctx.channelName,
+		SavedAt:   ctx.now,
+		Emoji:     reactionKey(&event.Emoji),
+		Mode:      string(target.Mode),
+		Tags:      vaultTags(&event.Emoji, ctx.channelName),
+		Content:   msg.Content,
+	}
+	if msg.Author != nil {
+		note.Author = msg.Author.Username
+		note.AuthorID = msg.Author.ID
+	}
+	if ctx.schedule != nil {
+		note.Reminder = ctx.schedule.Description
+	}
+
+	budget := maxVaultAttachmentBytes
+	for _, attachment := range msg.Attachments {
+		if attachment == nil {
+			continue
+		}
+
+		entry := vault.Attachment{Name: attachmentFileName(attachment), URL: attachment.URL}
+		if attachment.Size <= budget {
+			data, err := downloadAttachment(attachment.URL, budget)
+			if err != nil {
+				log.Printf("failed to download attachment %s for vault note: %v", attachment.ID, err)
+			} else {
+				entry.Data = data
+				budget -= len(data)
+			}
+		}
+		note.Attachments = append(note.Attachments, entry)
+	}
+
+	if target.Mode == store.ModeComplete {
+		for _, embed := range msg.Embeds {
+			if section, ok := embedSection(embed); ok {
+				note.Extra = append(note.Extra, section)
+			}
+		}
+	}
+	if ctx.capture != nil {
+		note.Extra = append(note.Extra, vault.Section{Title: "Transcript", Body: ctx.capture.Markdown()})
+	}
+
+	return h.vault.Save(event.UserID, h.store.VaultTemplate(event.UserID), note)
+}
+
+// vaultTags returns the note tags: "bookmark", the source channel and custom emoji names.
+// Unicode emoji are not valid tags in most Markdown apps and are kept in the emoji field only.
+func vaultTags(emoji *discordgo.Emoji, channelName string) []string {
+	tags := []string{"bookmark"}
+	for _, candidate := range []string{channelName, customEmojiName(emoji)} {
+		if tag := vaultTag(candidate); tag != "" && tag != tags[len(tags)-1] {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
+
+func customEmojiName(emoji *discordgo.Emoji) string {
+	if emoji == nil || emoji.ID == "" {
+		return ""
+	}
+
+	return emoji.Name
+}
+
+// vaultTag lowercases value and keeps only letters, digits, "-", "_" and "/".
+func vaultTag(value string) string {
+	var builder strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(value)) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_', r == '/':
+			builder.WriteRune(r)
+		case r == ' ':
+			builder.WriteRune('-')
+		}
+	}
+
+	return strings.Trim(builder.String(), "-/")
+}
+
+func embedSection(embed *discordgo.MessageEmbed) (vault.Section, bool) {
+	if embed == nil {
+		return vault.Section{}, false
+	}
+
+	var lines []string
+	if embed.Description != "" {
+		lines = append(lines, embed.Description)
+	}
+	for _, field := range embed.Fields {
+		if field != nil {
+			lines = append(lines, fmt.Sprintf("**%s**: %s", field.Name, field.Value))
+		}
+	}
+	if embed.URL != "" {
+		lines = append(lines, embed.URL)
+	}
+	if len(lines) == 0 {
+		return vault.Section{}, false
+	}
+
+	title := embed.Title
+	if title == "" {
+		title = "Embed"
+	}
+
+	return vault.Section{Title: title, Body: strings.Join(lines, "\n\n")}, true
+}
+
+// buildVaultReceipt is the DM sent for a vault bookmark. Done marks the note done and Remove
+// moves it to the archive folder.
+func buildVaultReceipt(bookmarkID, relPath, title, channelName, jumpURL string, color int, reminder string) *discordgo.MessageSend {
+	embed := &discordgo.MessageEmbed{
+		Title:       "🗃️ Saved to vault",
+		Description: title,
+		Color:       color,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "📄 Note", Value: fmt.Sprintf("`%s`", relPath)},
+			{Name: "📺 Channel", Value: fmt.Sprintf("#%s", channelName), Inline: true},
+			{Name: "🔗 Source Message", Value: fmt.Sprintf("[Open](%s)", jumpURL), Inline: true},
+		},
+	}
+	if reminder != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "⏰ Reminder", Value: reminder, Inline: true})
+	}
+
+	return &discordgo.MessageSend{
+		Embeds: []*discordgo.MessageEmbed{embed},
+		Components: []discordgo.MessageComponent{
+			discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    "Done",
+					Style:    discordgo.SuccessButton,
+					CustomID: NewCustomID(ActionComplete, bookmarkID).Encode(),
+					Emoji:    discordgo.ComponentEmoji{Name: "✅"},
+				},
+				discordgo.Button{
+					Label:    "Remove",
+					Style:    discordgo.DangerButton,
+					CustomID: NewCustomID(ActionDelete, bookmarkID).Encode(),
+					Emoji:    discordgo.ComponentEmoji{Name: "🗑️"},
+				},
+			}},
+		},
+	}
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/example/discord-bookmark-manager/internal/store"
+)
+
+// scoreFieldName names the embed field showing the votes on a channel post.
+const scoreFieldName = "🗳️ Score"
+
+// Arguments of ActionVote custom IDs.
+const (
+	voteUpArg   = "up"
+	voteDownArg = "down"
+)
+
+// describeScore renders the score field of bookmark, or nothing before anyone voted.
+func describeScore(bookmark store.Bookmark) string {
+	up, down := bookmark.Tally()
+	if up == 0 && down == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("**%+d** · 👍 %d · 👎 %d", up-down, up, down)
+}
+
+// vote toggles the vote of the member pressing 👍 or 👎 and updates the post's score.
+func (h *ComponentHandler) vote(s *discordgo.Session, i *discordgo.InteractionCreate, id CustomID) {
+	value := store.VoteUp
+	if id.Arg(0) == voteDownArg {
+		value = store.VoteDown
+	}
+
+	bookmark, ok := h.lookup(i, id)
+	userID := interactionUserID(i)
+	if !ok || userID == "" {
+		if err := respondEphemeral(s, i, "⚠️ I can't find this bookmark anymore, so votes can't be counted."); err != nil {
+			log.Printf("failed to reject vote: %v", err)
+		}
+		return
+	}
+
+	if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{Type: discordgo.InteractionResponseDeferredMessageUpdate}); err != nil {
+		log.Printf("failed to acknowledge vote interaction: %v", err)
+		return
+	}
+
+	h.votesMu.Lock()
+	defer h.votesMu.Unlock()
+
+	updated, err := h.bookmarks.Vote(bookmark.ID, userID, value)
+	if err != nil {
+		log.Printf("failed to record vote on bookmark %s: %v", bookmark.ID, err)
+		return
+	}
+
+	if _, err := s.ChannelMessageEditComplex(&discordgo.MessageEdit{
+		Channel:    i.ChannelID,
+		ID:         i.Message.ID,
+		Embeds:     withEmbedField(i.Message.Embeds, scoreFieldName, describeScore(updated)),
+		Components: i.Message.Components,
+	}); err != nil {
+		log.Printf("failed to update bookmark score: %v", err)
+	}
+}
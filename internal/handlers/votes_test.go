@@ -0,0 +1,18 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/example/discord-bookmark-manager/internal/store"
+)
+
+func TestDescribeScore(t *testing.T) {
+	if got := describeScore(store.Bookmark{}); got != "" {
+		t.Fatalf("expected no score before anyone voted, got %q", got)
+	}
+
+	bookmark := store.Bookmark{Votes: map[string]int{"alice": store.VoteUp, "bob": store.VoteUp, "carol": store.VoteDown}}
+	if want, got := "**+1** · 👍 2 · 👎 1", describeScore(bookmark); got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/example/discord-bookmark-manager/internal/outbox"
+	"github.com/example/discord-bookmark-manager/internal/store"
+	"github.com/example/discord-bookmark-manager/internal/webhook"
+)
+
+// webhookKind is the outbox kind of webhook deliveries.
+const webhookKind = "webhook"
+
+// webhookPayload is what the outbox keeps for a webhook delivery.
+type webhookPayload struct {
+	Endpoint store.WebhookEndpoint `json:"endpoint"`
+	Document webhook.Document      `json:"document"`
+}
+
+// webhookEndpoint returns where userID's webhook bookmarks go: their own endpoint, otherwise
+// the webhook of the server the message was saved in. shared reports the server's webhook,
+// whose readers are not limited to the user.
+func (h *ReactionHandler) webhookEndpoint(userID, guildID string) (endpoint store.WebhookEndpoint, shared, ok bool) {
+	if endpoint, ok := h.store.Webhook(userID); ok {
+		return endpoint, false, true
+	}
+
+	if guildID == "" || h.guilds == nil {
+		return store.WebhookEndpoint{}, false, false
+	}
+
+	settings := h.guilds.Get(guildID)
+	if settings.Webhook == nil {
+		return store.WebhookEndpoint{}, false, false
+	}
+
+	return *settings.Webhook, true, true
+}
+
+// buildWebhookDocument describes the saved message for a webhook target.
+func buildWebhookDocument(event *discordgo.MessageReactionAdd, target store.DestinationTarget, msg *discordgo.Message, ctx saveContext) webhook.Document {
+	doc := webhook.Document{
+		Version:    webhook.DocumentVersion,
+		Type:       webhook.EventBookmarkSaved,
+		BookmarkID: store.NewBookmarkID(),
+		SavedAt:    ctx.now.UTC(),
+		UserID:     event.UserID,
+		Emoji:      reactionKey(&event.Emoji),
+		Mode:       string(target.Mode),
+		Source: webhook.Source{
+			GuildID:     event.GuildID,
+			ChannelID:   event.ChannelID,
+			MessageID:   msg.ID,
+			ChannelName: ctx.channelName,
+			URL:         ctx.jumpURL,
+		},
+		Content:     msg.Content,
+		CreatedAt:   msg.Timestamp.UTC(),
+		Attachments: []webhook.Attachment{},
+	}
+
+	if msg.Author != nil {
+		doc.Author = webhook.Author{ID: msg.Author.ID, Username: msg.Author.Username, Bot: msg.Author.Bot}
+	}
+
+	for _, attachment := range msg.Attachments {
+		if attachment == nil {
+			continue
+		}
+		doc.Attachments = append(doc.Attachments, webhook.Attachment{
+			ID:          attachment.ID,
+			Filename:    attachment.Filename,
+			URL:         attachment.URL,
+			ContentType: attachment.ContentType,
+			Size:        attachment.Size,
+		})
+	}
+
+	if ctx.schedule != nil {
+		doc.Reminder = &webhook.Reminder{At: ctx.schedule.Time.UTC(), Description: ctx.schedule.Description}
+	}
+
+	return doc
+}
+
+// enqueueWebhook hands doc to the outbox and reports whether it was delivered right away. The
+// reactions in meta are removed once the endpoint accepts the document; if it never does the
+// user is told by DM.
+func (h *ReactionHandler) enqueueWebhook(userID, summary string, endpoint store.WebhookEndpoint, doc webhook.Document, meta deliveryMeta) bool {
+	payload, err := json.Marshal(webhookPayload{Endpoint: endpoint, Document: doc})
+	if err != nil {
+		log.Printf("failed to encode webhook delivery: %v", err)
+		return false
+	}
+
+	encodedMeta, err := json.Marshal(meta)
+	if err != nil {
+		log.Printf("failed to encode webhook delivery: %v", err)
+		return false
+	}
+
+	return h.outbox.Enqueue(outbox.Delivery{
+		ID:              doc.BookmarkID,
+		UserID:          userID,
+		Kind:            webhookKind,
+		Payload:         payload,
+		SourceGuildID:   doc.Source.GuildID,
+		SourceChannelID: doc.Source.ChannelID,
+		Summary:         summary,
+		Meta:            encodedMeta,
+	})
+}
+
+// sendWebhook makes one attempt at an outbox webhook delivery.
+func (h *ReactionHandler) sendWebhook(delivery outbox.Delivery) error {
+	var payload webhookPayload
+	if err := json.Unmarshal(delivery.Payload, &payload); err != nil {
+		return &outbox.RetryError{Err: fmt.Errorf("decode webhook delivery: %w", err), Permanent: true}
+	}
+
+	wait, retryable, err := h.webhooks.Attempt(context.Background(), payload.Endpoint.URL, payload.Endpoint.Secret, payload.Document)
+	if err != nil {
+		return &outbox.RetryError{Err: err, Permanent: !retryable, After: wait}
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/example/discord-bookmark-manager/internal/outbox"
+	"github.com/example/discord-bookmark-manager/internal/store"
+	"github.com/example/discord-bookmark-manager/internal/webhook"
+)
+
+func TestWebhookDeliveriesGoThroughOutbox(t *testing.T) {
+	var status atomic.Int32
+	status.Store(http.StatusServiceUnavailable)
+	var received atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var doc webhook.Document
+		if err := json.NewDecoder(r.Body).Decode(&doc); err == nil {
+			received.Store(doc.BookmarkID)
+		}
+		w.WriteHeader(int(status.Load()))
+	}))
+	t.Cleanup(server.Close)
+
+	deliveries, err := outbox.NewService(nil, "")
+	if err != nil {
+		t.Fatalf("NewService returned error: %v", err)
+	}
+	t.Cleanup(deliveries.Close)
+
+	webhooks := webhook.NewClient()
+	webhooks.SetAllowLoopback(true)
+	h := NewReactionHandler(nil, nil, nil, nil, nil, deliveries, webhooks, nil, nil)
+
+	if h.enqueueWebhook("user", "a message in #general", store.WebhookEndpoint{URL: server.URL}, webhook.Document{BookmarkID: "doc"}, deliveryMeta{}) {
+		t.Fatalf("expected the refused document not to count as delivered")
+	}
+	if received.Load() != "doc" {
+		t.Fatalf("expected the document to be posted right away")
+	}
+	if deliveries.Pending() != 1 {
+		t.Fatalf("expected the failed delivery to stay in the outbox, got %d", deliveries.Pending())
+	}
+
+	status.Store(http.StatusNoContent)
+	if !h.enqueueWebhook("user", "a message in #general", store.WebhookEndpoint{URL: server.URL}, webhook.Document{BookmarkID: "doc"}, deliveryMeta{}) {
+		t.Fatalf("expected the accepted document to count as delivered")
+	}
+	if deliveries.Pending() != 0 {
+		t.Fatalf("expected the accepted delivery to leave the outbox, got %d", deliveries.Pending())
+	}
+}
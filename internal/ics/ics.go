@@ -0,0 +1,105 @@
+// Package ics renders reminders as iCalendar (RFC 5545) documents.
+package ics
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	productID = "-//discord-bookmark-manager//reminders//EN"
+	uidDomain = "discord-bookmark-manager"
+	// eventLength is how long reminder events block in a calendar.
+	eventLength = "PT15M"
+	// maxLineOctets is the folding limit of RFC 5545 content lines.
+	maxLineOctets = 75
+)
+
+// Event is one reminder.
+type Event struct {
+	UID         string
+	Start       time.Time
+	Summary     string
+	Description string
+	URL         string
+	// Daily repeats the event every day from Start.
+	Daily bool
+}
+
+// Calendar renders events as a VCALENDAR named name. Times are written in UTC.
+func Calendar(name string, events []Event, now time.Time) []byte {
+	var b builder
+	b.line("BEGIN:VCALENDAR")
+	b.line("VERSION:2.0")
+	b.line("PRODID:" + productID)
+	b.line("CALSCALE:GREGORIAN")
+	b.line("METHOD:PUBLISH")
+	if name != "" {
+		b.line("X-WR-CALNAME:" + escapeText(name))
+	}
+
+	for _, event := range events {
+		b.line("BEGIN:VEVENT")
+		b.line(fmt.Sprintf("UID:%s@%s", event.UID, uidDomain))
+		b.line("DTSTAMP:" + formatTime(now))
+		b.line("DTSTART:" + formatTime(event.Start))
+		b.line("DURATION:" + eventLength)
+		if event.Daily {
+			b.line("RRULE:FREQ=DAILY")
+		}
+		b.line("SUMMARY:" + escapeText(event.Summary))
+		if event.Description != "" {
+			b.line("DESCRIPTION:" + escapeText(event.Description))
+		}
+		if event.URL != "" {
+			b.line("URL:" + event.URL)
+		}
+		b.line("BEGIN:VALARM")
+		b.line("ACTION:DISPLAY")
+		b.line("TRIGGER:PT0S")
+		b.line("DESCRIPTION:" + escapeText(event.Summary))
+		b.line("END:VALARM")
+		b.line("END:VEVENT")
+	}
+
+	b.line("END:VCALENDAR")
+	return []byte(b.String())
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// escapeText escapes a TEXT property value.
+func escapeText(value string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	).Replace(value)
+}
+
+type builder struct {
+	strings.Builder
+}
+
+// line writes one content line, folded at 75 octets without splitting UTF-8 sequences.
+func (b *builder) line(content string) {
+	limit := maxLineOctets
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(content[cut]) {
+			cut--
+		}
+		b.WriteString(content[:cut] + "\r\n ")
+		content = content[cut:]
+		// Continuation lines start with a space, which counts towards the limit.
+		limit = maxLineOctets - 1
+	}
+	b.WriteString(content + "\r\n")
+}
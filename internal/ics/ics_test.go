@@ -0,0 +1,56 @@
+package ics
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestCalendarRendersEvents(t *testing.T) {
+	start := time.Date(2026, 3, 14, 9, 30, 0, 0, time.FixedZone("CET", 3600))
+	now := time.Date(2026, 3, 13, 0, 0, 0, 0, time.UTC)
+
+	data := string(Calendar("Bookmark reminders", []Event{
+		{UID: "once", Start: start, Summary: "⏰ #general", Description: "Ship it, then; relax\nsoon", URL: "https://discord.com/channels/1/2/3"},
+		{UID: "daily", Start: start, Summary: "⏰ #daily", Daily: true},
+	}, now))
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\nVERSION:2.0\r\n",
+		"X-WR-CALNAME:Bookmark reminders\r\n",
+		"UID:once@discord-bookmark-manager\r\n",
+		"DTSTAMP:20260313T000000Z\r\n",
+		"DTSTART:20260314T083000Z\r\n",
+		`DESCRIPTION:Ship it\, then\; relax\nsoon` + "\r\n",
+		"URL:https://discord.com/channels/1/2/3\r\n",
+		"DTSTART:20260314T083000Z\r\nDURATION:PT15M\r\nSUMMARY:⏰ #general\r\n",
+		"UID:daily@discord-bookmark-manager\r\nDTSTAMP:20260313T000000Z\r\nDTSTART:20260314T083000Z\r\nDURATION:PT15M\r\nRRULE:FREQ=DAILY\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(data, want) {
+			t.Errorf("calendar is missing %q:\n%s", want, data)
+		}
+	}
+	if strings.Count(data, "RRULE") != 1 {
+		t.Fatalf("only the daily event should repeat:\n%s", data)
+	}
+}
+
+func TestLinesAreFoldedOnRuneBoundaries(t *testing.T) {
+	data := string(Calendar("", []Event{{UID: "long", Summary: strings.Repeat("é", 100)}}, time.Now()))
+
+	for _, line := range strings.Split(strings.TrimSuffix(data, "\r\n"), "\r\n") {
+		if len(line) > maxLineOctets {
+			t.Fatalf("line longer than %d octets: %q", maxLineOctets, line)
+		}
+		if !utf8.ValidString(line) {
+			t.Fatalf("line split inside a rune: %q", line)
+		}
+	}
+
+	unfolded := strings.ReplaceAll(data, "\r\n ", "")
+	if !strings.Contains(unfolded, "SUMMARY:"+strings.Repeat("é", 100)+"\r\n") {
+		t.Fatalf("unfolded summary does not round-trip:\n%s", unfolded)
+	}
+}
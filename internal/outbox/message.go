@@ -0,0 +1,85 @@
+package outbox
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Message is a persistable copy of a discordgo.MessageSend. Files are buffered so a send can
+// be retried after the original readers were consumed.
+type Message struct {
+	Content         string                            `json:"content,omitempty"`
+	Embeds          []*discordgo.MessageEmbed         `json:"embeds,omitempty"`
+	Components      json.RawMessage                   `json:"components,omitempty"`
+	Files           []File                            `json:"files,omitempty"`
+	AllowedMentions *discordgo.MessageAllowedMentions `json:"allowedMentions,omitempty"`
+}
+
+// File is a buffered message attachment.
+type File struct {
+	Name        string `json:"name"`
+	ContentType string `json:"contentType,omitempty"`
+	Data        []byte `json:"data"`
+}
+
+// NewMessage buffers send into a Message. The readers of send's files are consumed.
+func NewMessage(send *discordgo.MessageSend) (Message, error) {
+	message := Message{
+		Content:         send.Content,
+		Embeds:          send.Embeds,
+		AllowedMentions: send.AllowedMentions,
+	}
+
+	if len(send.Components) > 0 {
+		components, err := json.Marshal(send.Components)
+		if err != nil {
+			return Message{}, fmt.Errorf("encode components: %w", err)
+		}
+		message.Components = components
+	}
+
+	for _, file := range send.Files {
+		if file == nil || file.Reader == nil {
+			continue
+		}
+		data, err := io.ReadAll(file.Reader)
+		if err != nil {
+			return Message{}, fmt.Errorf("buffer %s: %w", file.Name, err)
+		}
+		message.Files = append(message.Files, File{Name: file.Name, ContentType: file.ContentType, Data: data})
+	}
+
+	return message, nil
+}
+
+// Send rebuilds a discordgo.MessageSend with fresh file readers.
+func (m Message) Send() (*discordgo.MessageSend, error) {
+	send := &discordgo.MessageSend{
+		Content:         m.Content,
+		Embeds:          m.Embeds,
+		AllowedMentions: m.AllowedMentions,
+	}
+
+	if len(m.Components) > 0 {
+		// discordgo only exposes component decoding through Message.
+		var decoded discordgo.Message
+		if err := json.Unmarshal([]byte(`{"components":`+string(m.Components)+`}`), &decoded); err != nil {
+			return nil, fmt.Errorf("decode components: %w", err)
+		}
+		send.Components = decoded.Components
+	}
+
+	for _, file := range m.Files {
+		send.Files = append(send.Files, &discordgo.File{
+			Name:        file.Name,
+			ContentType: file.ContentType,
+			Reader:      bytes.NewReader(file.Data),
+		})
+	}
+
+	return send, nil
+}
@@ -0,0 +1,613 @@
+package outbox
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// maxAttempts is how many sends are tried before a delivery is given up.
+	maxAttempts = 6
+	// baseBackoff is the wait after the first failure. It doubles on every later failure.
+	baseBackoff = 5 * time.Second
+	// maxBackoff caps the wait between two attempts.
+	maxBackoff = 10 * time.Minute
+	// maxNotices caps the undelivered notices queued for a single user.
+	maxNotices = 5
+)
+
+// Delivery is a bookmark message waiting to be sent.
+type Delivery struct {
+	ID     string `json:"id"`
+	UserID string `json:"userId"`
+	// Kind names the sender registered for deliveries that leave Discord, such as webhooks
+	// and email. Those carry their content in Payload instead of Message. An empty Kind
+	// sends Message to Discord.
+	Kind    string          `json:"kind,omitempty"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+	// ChannelID is the destination. An empty ChannelID delivers to the user's DMs.
+	ChannelID string `json:"channelId,omitempty"`
+	// SourceGuildID and SourceChannelID locate the bookmarked message. They pick the DM
+	// fallback when the user's DMs are closed.
+	SourceGuildID   string `json:"sourceGuildId,omitempty"`
+	SourceChannelID string `json:"sourceChannelId,omitempty"`
+	// Summary describes the bookmark in the notice sent when delivery fails.
+	Summary string  `json:"summary"`
+	Message Message `json:"message"`
+	// TooLarge is sent instead of Message when Discord refuses Message's files as too large.
+	TooLarge *Message `json:"tooLarge,omitempty"`
+	// Forum makes the delivery start a new post in the forum channel ChannelID.
+	Forum *ForumPost `json:"forum,omitempty"`
+	// Meta carries caller data handed back to the delivered callback.
+	Meta        json.RawMessage `json:"meta,omitempty"`
+	Attempts    int             `json:"attempts"`
+	NextAttempt time.Time       `json:"nextAttempt"`
+	LastError   string          `json:"lastError,omitempty"`
+}
+
+// ForumPost describes the post a forum delivery creates. The sent message of a forum delivery
+// is the post's starter message, whose ID is also the post's channel ID.
+type ForumPost struct {
+	Title       string   `json:"title"`
+	AppliedTags []string `json:"appliedTags,omitempty"`
+}
+
+// DeliveredFunc runs after a delivery was sent. sent is nil for deliveries with a Kind.
+type DeliveredFunc func(s *discordgo.Session, delivery Delivery, sent *discordgo.Message)
+
+// SendFunc makes one attempt at a delivery of the kind it was registered for. Returning a
+// *RetryError controls whether and when the delivery is retried; other errors back off.
+type SendFunc func(delivery Delivery) error
+
+// RetryError tells the outbox how to handle a failed attempt of a SendFunc.
+type RetryError struct {
+	Err error
+	// Permanent gives the delivery up without further attempts.
+	Permanent bool
+	// After is the wait the receiver asked for. Zero uses the usual backoff.
+	After time.Duration
+}
+
+func (e *RetryError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+// DMFallbackFunc returns the channel to use for userID when their DMs are closed.
+type DMFallbackFunc func(s *discordgo.Session, userID, guildID, channelID string) (string, error)
+
+// IsDMClosed reports whether err is Discord refusing a DM because of the user's privacy
+// settings.
+func IsDMClosed(err error) bool {
+	var restErr *discordgo.RESTError
+	return errors.As(err, &restErr) && restErr.Message != nil && restErr.Message.Code == discordgo.ErrCodeCannotSendMessagesToThisUser
+}
+
+type persistedOutbox struct {
+	Deliveries map[string]Delivery `json:"deliveries"`
+	Notices    map[string][]string `json:"notices,omitempty"`
+}
+
+// Service sends bookmark messages and retries failed sends with backoff. Deliveries that
+// still fail after every retry are reported to the user by DM, or queued as notices shown
+// on their next command when DMs fail too.
+type Service struct {
+	session     *discordgo.Session
+	mu          sync.Mutex
+	pending     map[string]Delivery
+	timers      map[string]*time.Timer
+	notices     map[string][]string
+	filePath    string
+	onDelivered DeliveredFunc
+	dmFallback  DMFallbackFunc
+	senders     map[string]SendFunc
+	closed      bool
+
+	send       func(channelID string, message *discordgo.MessageSend) (*discordgo.Message, error)
+	startForum func(channelID string, post *ForumPost, message *discordgo.MessageSend) (*discordgo.Message, error)
+	openDM     func(userID string) (string, error)
+}
+
+// NewService constructs an outbox bound to session and restores pending deliveries from
+// filePath. Restored deliveries are retried once Start is called.
+func NewService(session *discordgo.Session, filePath string) (*Service, error) {
+	service := &Service{
+		session:  session,
+		pending:  make(map[string]Delivery),
+		timers:   make(map[string]*time.Timer),
+		notices:  make(map[string][]string),
+		senders:  make(map[string]SendFunc),
+		filePath: filePath,
+		send: func(channelID string, message *discordgo.MessageSend) (*discordgo.Message, error) {
+			// Rate limits are retried by the outbox instead of blocking the caller.
+			return session.ChannelMessageSendComplex(channelID, message, discordgo.WithRetryOnRatelimit(false))
+		},
+		startForum: func(channelID string, post *ForumPost, message *discordgo.MessageSend) (*discordgo.Message, error) {
+			thread, err := session.ForumThreadStartComplex(channelID, &discordgo.ThreadStart{
+				Name:        post.Title,
+				AppliedTags: post.AppliedTags,
+			}, message, discordgo.WithRetryOnRatelimit(false))
+			if err != nil {
+				return nil, err
+			}
+			return &discordgo.Message{ID: thread.ID, ChannelID: thread.ID, GuildID: thread.GuildID}, nil
+		},
+		openDM: func(userID string) (string, error) {
+			channel, err := session.UserChannelCreate(userID, discordgo.WithRetryOnRatelimit(false))
+			if err != nil {
+				return "", err
+			}
+			return channel.ID, nil
+		},
+	}
+
+	if err := service.restore(); err != nil {
+		return nil, err
+	}
+
+	return service, nil
+}
+
+// OnDelivered sets the callback that runs after every successful delivery.
+func (s *Service) OnDelivered(fn DeliveredFunc) {
+	s.mu.Lock()
+	s.onDelivered = fn
+	s.mu.Unlock()
+}
+
+// SetDMFallback sets where DM deliveries go when the user's DMs are closed.
+func (s *Service) SetDMFallback(fn DMFallbackFunc) {
+	s.mu.Lock()
+	s.dmFallback = fn
+	s.mu.Unlock()
+}
+
+// RegisterSender sets how deliveries of kind are sent.
+func (s *Service) RegisterSender(kind string, fn SendFunc) {
+	s.mu.Lock()
+	s.senders[kind] = fn
+	s.mu.Unlock()
+}
+
+// Start schedules the deliveries restored from disk.
+func (s *Service) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, delivery := range s.pending {
+		s.scheduleLocked(id, time.Until(delivery.NextAttempt))
+	}
+}
+
+// Enqueue sends delivery right away and reports whether that first attempt delivered it. The
+// delivery is only persisted when the attempt fails, so the common path never writes
+// attachments to disk.
+func (s *Service) Enqueue(delivery Delivery) bool {
+	if delivery.ID == "" {
+		delivery.ID = newDeliveryID()
+	}
+	delivery.Attempts = 0
+
+	s.mu.Lock()
+	s.pending[delivery.ID] = delivery
+	s.mu.Unlock()
+
+	return s.attempt(delivery.ID)
+}
+
+// Pending returns the number of deliveries waiting for a retry.
+func (s *Service) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.pending)
+}
+
+// Notices returns the failure notices queued for userID. They stay queued until
+// ClearNotices confirms they were shown.
+func (s *Service) Notices(userID string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.notices[userID]...)
+}
+
+// ClearNotices removes shown, as returned by Notices, from the notices queued for userID.
+// Notices queued in the meantime stay.
+func (s *Service) ClearNotices(userID string, shown []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	remaining := s.notices[userID]
+	for _, notice := range shown {
+		for idx, queued := range remaining {
+			if queued == notice {
+				remaining = append(remaining[:idx:idx], remaining[idx+1:]...)
+				break
+			}
+		}
+	}
+
+	if len(remaining) == len(s.notices[userID]) {
+		return
+	}
+	if len(remaining) == 0 {
+		delete(s.notices, userID)
+	} else {
+		s.notices[userID] = remaining
+	}
+	if err := s.persistLocked(); err != nil {
+		log.Printf("failed to persist outbox: %v", err)
+	}
+}
+
+// Close stops all retry timers. Pending deliveries stay on disk for the next start.
+func (s *Service) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	for id, timer := range s.timers {
+		timer.Stop()
+		delete(s.timers, id)
+	}
+}
+
+// attempt tries the pending delivery id once and reports whether it was sent.
+func (s *Service) attempt(id string) bool {
+	s.mu.Lock()
+	delivery, ok := s.pending[id]
+	delete(s.timers, id)
+	s.mu.Unlock()
+	if !ok {
+		return false
+	}
+
+	sent, err := s.deliver(delivery)
+	if err != nil {
+		s.failed(delivery, err)
+		return false
+	}
+
+	s.mu.Lock()
+	delete(s.pending, id)
+	if delivery.Attempts > 0 {
+		if err := s.persistLocked(); err != nil {
+			log.Printf("failed to persist outbox: %v", err)
+		}
+	}
+	onDelivered := s.onDelivered
+	s.mu.Unlock()
+
+	if onDelivered != nil {
+		onDelivered(s.session, delivery, sent)
+	}
+
+	return true
+}
+
+func (s *Service) deliver(delivery Delivery) (*discordgo.Message, error) {
+	if delivery.Kind != "" {
+		s.mu.Lock()
+		sender, ok := s.senders[delivery.Kind]
+		s.mu.Unlock()
+		if !ok {
+			return nil, &RetryError{Err: fmt.Errorf("no sender for %s deliveries", delivery.Kind), Permanent: true}
+		}
+		return nil, sender(delivery)
+	}
+
+	sent, err := s.sendMessage(delivery, delivery.Message)
+	if err != nil && delivery.TooLarge != nil && isTooLarge(err) {
+		log.Printf("bookmark delivery %s is too large, sending it without the extra files", delivery.ID)
+		sent, err = s.sendMessage(delivery, *delivery.TooLarge)
+	}
+
+	return sent, err
+}
+
+// isTooLarge reports whether err is Discord refusing a message because its files exceed the
+// channel's upload limit.
+func isTooLarge(err error) bool {
+	var restErr *discordgo.RESTError
+	if !errors.As(err, &restErr) {
+		return false
+	}
+
+	return (restErr.Response != nil && restErr.Response.StatusCode == http.StatusRequestEntityTooLarge) ||
+		(restErr.Message != nil && restErr.Message.Code == discordgo.ErrCodeRequestEntityTooLarge)
+}
+
+// sendMessage sends stored to the destination of delivery.
+func (s *Service) sendMessage(delivery Delivery, stored Message) (*discordgo.Message, error) {
+	channelID := delivery.ChannelID
+	if channelID == "" {
+		dmChannelID, err := s.openDM(delivery.UserID)
+		if err != nil {
+			return nil, err
+		}
+		channelID = dmChannelID
+	}
+
+	message, err := stored.Send()
+	if err != nil {
+		return nil, err
+	}
+
+	if delivery.Forum != nil && delivery.ChannelID != "" {
+		return s.startForum(channelID, delivery.Forum, message)
+	}
+
+	sent, err := s.send(channelID, message)
+	if err == nil || delivery.ChannelID != "" || !IsDMClosed(err) {
+		return sent, err
+	}
+
+	s.mu.Lock()
+	dmFallback := s.dmFallback
+	s.mu.Unlock()
+	if dmFallback == nil {
+		return nil, err
+	}
+
+	fallbackID, fallbackErr := dmFallback(s.session, delivery.UserID, delivery.SourceGuildID, delivery.SourceChannelID)
+	if fallbackErr != nil {
+		log.Printf("no DM fallback for %s: %v", delivery.UserID, fallbackErr)
+		return nil, err
+	}
+
+	// The first attempt consumed the file readers.
+	message, err = stored.Send()
+	if err != nil {
+		return nil, err
+	}
+
+	sent, err = s.send(fallbackID, message)
+	if err == nil && sent.GuildID == "" {
+		sent.GuildID = delivery.SourceGuildID
+	}
+	return sent, err
+}
+
+func (s *Service) failed(delivery Delivery, err error) {
+	delivery.Attempts++
+	delivery.LastError = err.Error()
+
+	wait, retryable := retryDelay(err, delivery.Attempts)
+	if retryable && delivery.Attempts < maxAttempts {
+		log.Printf("bookmark delivery %s failed (attempt %d/%d), retrying in %s: %v", delivery.ID, delivery.Attempts, maxAttempts, wait, err)
+
+		delivery.NextAttempt = time.Now().Add(wait)
+
+		s.mu.Lock()
+		s.pending[delivery.ID] = delivery
+		if err := s.persistLocked(); err != nil {
+			log.Printf("failed to persist outbox: %v", err)
+		}
+		if !s.closed {
+			s.scheduleLocked(delivery.ID, wait)
+		}
+		s.mu.Unlock()
+		return
+	}
+
+	log.Printf("bookmark delivery %s gave up after %d attempts: %v", delivery.ID, delivery.Attempts, err)
+
+	s.mu.Lock()
+	delete(s.pending, delivery.ID)
+	if err := s.persistLocked(); err != nil {
+		log.Printf("failed to persist outbox: %v", err)
+	}
+	s.mu.Unlock()
+
+	s.notifyFailure(delivery)
+}
+
+// notifyFailure tells the user their bookmark was lost. Channel deliveries and deliveries
+// with a Kind are reported by DM; when the DM cannot be sent either, the notice waits for
+// the user's next command.
+func (s *Service) notifyFailure(delivery Delivery) {
+	notice := fmt.Sprintf("⚠️ Your bookmark of %s could not be delivered after %d attempts (%s). React again to retry.", delivery.Summary, delivery.Attempts, delivery.LastError)
+
+	if delivery.ChannelID != "" || delivery.Kind != "" {
+		if dmChannelID, err := s.openDM(delivery.UserID); err == nil {
+			_, err = s.send(dmChannelID, &discordgo.MessageSend{
+				Embeds: []*discordgo.MessageEmbed{{
+					Title:       "⚠️ Bookmark not delivered",
+					Description: notice,
+					Color:       0xED4245,
+				}},
+			})
+			if err == nil {
+				return
+			}
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	queued := append(s.notices[delivery.UserID], notice)
+	if len(queued) > maxNotices {
+		queued = queued[len(queued)-maxNotices:]
+	}
+	s.notices[delivery.UserID] = queued
+	if err := s.persistLocked(); err != nil {
+		log.Printf("failed to persist outbox: %v", err)
+	}
+}
+
+func (s *Service) scheduleLocked(id string, wait time.Duration) {
+	if wait < 0 {
+		wait = 0
+	}
+
+	if existing, ok := s.timers[id]; ok {
+		existing.Stop()
+	}
+
+	s.timers[id] = time.AfterFunc(wait, func() {
+		s.attempt(id)
+	})
+}
+
+// retryDelay reports how long to wait before the next attempt and whether the error is worth
+// retrying at all. A RetryError decides for itself. Rate limits use the retry-after Discord
+// sent; server errors and network failures back off exponentially; other client errors are
+// permanent.
+func retryDelay(err error, attempts int) (time.Duration, bool) {
+	var retryErr *RetryError
+	if errors.As(err, &retryErr) {
+		switch {
+		case retryErr.Permanent:
+			return 0, false
+		case retryErr.After > 0:
+			return retryErr.After, true
+		default:
+			return backoff(attempts), true
+		}
+	}
+
+	var rateLimit *discordgo.RateLimitError
+	if errors.As(err, &rateLimit) && rateLimit.RateLimit != nil && rateLimit.TooManyRequests != nil {
+		return rateLimit.RetryAfter, true
+	}
+
+	var restErr *discordgo.RESTError
+	if errors.As(err, &restErr) && restErr.Response != nil {
+		status := restErr.Response.StatusCode
+		switch {
+		case status == http.StatusTooManyRequests:
+			var body discordgo.TooManyRequests
+			if json.Unmarshal(restErr.ResponseBody, &body) == nil && body.RetryAfter > 0 {
+				return body.RetryAfter, true
+			}
+			return backoff(attempts), true
+		case status >= http.StatusInternalServerError:
+			return backoff(attempts), true
+		default:
+			return 0, false
+		}
+	}
+
+	return backoff(attempts), true
+}
+
+func backoff(attempts int) time.Duration {
+	wait := baseBackoff
+	for i := 1; i < attempts; i++ {
+		wait *= 2
+		if wait >= maxBackoff {
+			return maxBackoff
+		}
+	}
+
+	return wait
+}
+
+func newDeliveryID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+
+	return hex.EncodeToString(buf)
+}
+
+func (s *Service) restore() error {
+	if s.filePath == "" {
+		return nil
+	}
+
+	file, err := os.Open(s.filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	defer file.Close()
+
+	var persisted persistedOutbox
+	if err := json.NewDecoder(file).Decode(&persisted); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return err
+	}
+
+	for id, delivery := range persisted.Deliveries {
+		s.pending[id] = delivery
+	}
+	for userID, notices := range persisted.Notices {
+		s.notices[userID] = notices
+	}
+
+	return nil
+}
+
+// persistLocked writes deliveries that already failed once. Deliveries still on their first
+// attempt are skipped.
+func (s *Service) persistLocked() error {
+	if s.filePath == "" {
+		return nil
+	}
+
+	toPersist := persistedOutbox{
+		Deliveries: make(map[string]Delivery, len(s.pending)),
+		Notices:    s.notices,
+	}
+	for id, delivery := range s.pending {
+		if delivery.Attempts == 0 {
+			continue
+		}
+		toPersist.Deliveries[id] = delivery
+	}
+
+	dir := filepath.Dir(s.filePath)
+	if dir != "." && dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+
+	tempFile, err := os.CreateTemp(dir, "outbox-*.json")
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(tempFile)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(toPersist); err != nil {
+		tempFile.Close()
+		os.Remove(tempFile.Name())
+		return err
+	}
+
+	if err := tempFile.Close(); err != nil {
+		os.Remove(tempFile.Name())
+		return err
+	}
+
+	if err := os.Rename(tempFile.Name(), s.filePath); err != nil {
+		os.Remove(tempFile.Name())
+		return err
+	}
+
+	return nil
+}
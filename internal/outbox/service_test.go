@@ -0,0 +1,327 @@
+package outbox
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func restError(status int, body string) error {
+	return &discordgo.RESTError{
+		Response:     &http.Response{StatusCode: status, Status: http.StatusText(status)},
+		ResponseBody: []byte(body),
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	rateLimited := &discordgo.RateLimitError{RateLimit: &discordgo.RateLimit{
+		TooManyRequests: &discordgo.TooManyRequests{RetryAfter: 3 * time.Second},
+	}}
+
+	tests := []struct {
+		name      string
+		err       error
+		attempts  int
+		wait      time.Duration
+		retryable bool
+	}{
+		{name: "rate limit error", err: rateLimited, attempts: 1, wait: 3 * time.Second, retryable: true},
+		{name: "429 body", err: restError(http.StatusTooManyRequests, `{"retry_after": 1.5}`), attempts: 1, wait: 1500 * time.Millisecond, retryable: true},
+		{name: "server error", err: restError(http.StatusBadGateway, ""), attempts: 3, wait: 20 * time.Second, retryable: true},
+		{name: "network error", err: errors.New("connection reset"), attempts: 1, wait: baseBackoff, retryable: true},
+		{name: "forbidden", err: restError(http.StatusForbidden, `{"code": 50013}`), attempts: 1, retryable: false},
+		{name: "backoff cap", err: errors.New("timeout"), attempts: 20, wait: maxBackoff, retryable: true},
+		{name: "sender retry after", err: &RetryError{Err: errors.New("busy"), After: time.Minute}, attempts: 1, wait: time.Minute, retryable: true},
+		{name: "sender backoff", err: &RetryError{Err: errors.New("timeout")}, attempts: 2, wait: 2 * baseBackoff, retryable: true},
+		{name: "sender permanent", err: &RetryError{Err: errors.New("rejected"), Permanent: true}, attempts: 1, retryable: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wait, retryable := retryDelay(tt.err, tt.attempts)
+			if retryable != tt.retryable {
+				t.Fatalf("expected retryable %v, got %v", tt.retryable, retryable)
+			}
+			if retryable && wait != tt.wait {
+				t.Fatalf("expected wait %s, got %s", tt.wait, wait)
+			}
+		})
+	}
+}
+
+func TestMessageRoundTrip(t *testing.T) {
+	message, err := NewMessage(&discordgo.MessageSend{
+		Content: "hello",
+		Components: []discordgo.MessageComponent{
+			discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+				discordgo.Button{Label: "Done", CustomID: "bm:1:done:abc", Style: discordgo.SuccessButton},
+			}},
+		},
+		Files: []*discordgo.File{{Name: "notes.md", ContentType: "text/markdown", Reader: strings.NewReader("# notes")}},
+	})
+	if err != nil {
+		t.Fatalf("NewMessage returned error: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		send, err := message.Send()
+		if err != nil {
+			t.Fatalf("Send returned error: %v", err)
+		}
+
+		row, ok := send.Components[0].(*discordgo.ActionsRow)
+		if !ok || len(row.Components) != 1 {
+			t.Fatalf("expected one decoded action row, got %#v", send.Components)
+		}
+		if button, ok := row.Components[0].(*discordgo.Button); !ok || button.CustomID != "bm:1:done:abc" {
+			t.Fatalf("expected decoded button, got %#v", row.Components[0])
+		}
+
+		data, _ := io.ReadAll(send.Files[0].Reader)
+		if string(data) != "# notes" {
+			t.Fatalf("expected file contents on send %d, got %q", i, data)
+		}
+	}
+}
+
+func TestEnqueueDeliversAndRunsCallback(t *testing.T) {
+	service, err := NewService(nil, filepath.Join(t.TempDir(), "outbox.json"))
+	if err != nil {
+		t.Fatalf("NewService returned error: %v", err)
+	}
+	service.openDM = func(userID string) (string, error) { return "dm-" + userID, nil }
+	service.send = func(channelID string, message *discordgo.MessageSend) (*discordgo.Message, error) {
+		return &discordgo.Message{ID: "sent", ChannelID: channelID}, nil
+	}
+
+	var delivered *discordgo.Message
+	service.OnDelivered(func(_ *discordgo.Session, delivery Delivery, sent *discordgo.Message) {
+		delivered = sent
+	})
+
+	if !service.Enqueue(Delivery{UserID: "user", Message: Message{Content: "hi"}}) {
+		t.Fatalf("expected Enqueue to report the delivery as sent")
+	}
+
+	if delivered == nil || delivered.ChannelID != "dm-user" {
+		t.Fatalf("expected delivery to the user's DMs, got %+v", delivered)
+	}
+	if service.Pending() != 0 {
+		t.Fatalf("expected no pending deliveries, got %d", service.Pending())
+	}
+}
+
+func TestFailedDeliveryIsPersistedForRetry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.json")
+	service, err := NewService(nil, path)
+	if err != nil {
+		t.Fatalf("NewService returned error: %v", err)
+	}
+	defer service.Close()
+	service.send = func(string, *discordgo.MessageSend) (*discordgo.Message, error) {
+		return nil, restError(http.StatusServiceUnavailable, "")
+	}
+
+	if service.Enqueue(Delivery{UserID: "user", ChannelID: "channel", Message: Message{Content: "hi"}}) {
+		t.Fatalf("expected Enqueue to report the failed attempt")
+	}
+	service.Close()
+
+	reloaded, err := NewService(nil, path)
+	if err != nil {
+		t.Fatalf("reloading outbox returned error: %v", err)
+	}
+	if reloaded.Pending() != 1 {
+		t.Fatalf("expected the failed delivery to be restored, got %d", reloaded.Pending())
+	}
+	for _, delivery := range reloaded.pending {
+		if delivery.Attempts != 1 || delivery.NextAttempt.IsZero() {
+			t.Fatalf("expected attempt bookkeeping to persist, got %+v", delivery)
+		}
+	}
+}
+
+func TestPermanentFailureQueuesNotice(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.json")
+	service, err := NewService(nil, path)
+	if err != nil {
+		t.Fatalf("NewService returned error: %v", err)
+	}
+	service.openDM = func(string) (string, error) {
+		return "", restError(http.StatusForbidden, `{"code": 50007}`)
+	}
+	service.send = func(string, *discordgo.MessageSend) (*discordgo.Message, error) {
+		return nil, restError(http.StatusForbidden, `{"code": 50013}`)
+	}
+
+	service.Enqueue(Delivery{UserID: "user", ChannelID: "channel", Summary: "a message in #general"})
+
+	if service.Pending() != 0 {
+		t.Fatalf("expected permanent failures not to be retried")
+	}
+
+	reloaded, err := NewService(nil, path)
+	if err != nil {
+		t.Fatalf("reloading outbox returned error: %v", err)
+	}
+	notices := reloaded.Notices("user")
+	if len(notices) != 1 || !strings.Contains(notices[0], "#general") {
+		t.Fatalf("expected a persisted notice, got %v", notices)
+	}
+	if again := reloaded.Notices("user"); len(again) != 1 {
+		t.Fatalf("expected notices to stay until they are cleared, got %v", again)
+	}
+
+	reloaded.notices["user"] = append(reloaded.notices["user"], "later notice")
+	reloaded.ClearNotices("user", notices)
+	if again := reloaded.Notices("user"); len(again) != 1 || again[0] != "later notice" {
+		t.Fatalf("expected only the shown notices to be cleared, got %v", again)
+	}
+}
+
+func TestClosedDMsUseFallback(t *testing.T) {
+	service, err := NewService(nil, "")
+	if err != nil {
+		t.Fatalf("NewService returned error: %v", err)
+	}
+	service.openDM = func(string) (string, error) { return "dm", nil }
+	service.send = func(channelID string, message *discordgo.MessageSend) (*discordgo.Message, error) {
+		if channelID == "dm" {
+			return nil, &discordgo.RESTError{
+				Response: &http.Response{StatusCode: http.StatusForbidden},
+				Message:  &discordgo.APIErrorMessage{Code: discordgo.ErrCodeCannotSendMessagesToThisUser},
+			}
+		}
+		return &discordgo.Message{ID: "sent", ChannelID: channelID}, nil
+	}
+	service.SetDMFallback(func(_ *discordgo.Session, userID, guildID, channelID string) (string, error) {
+		return "thread-" + guildID, nil
+	})
+
+	var delivered *discordgo.Message
+	service.OnDelivered(func(_ *discordgo.Session, _ Delivery, sent *discordgo.Message) {
+		delivered = sent
+	})
+
+	service.Enqueue(Delivery{UserID: "user", SourceGuildID: "guild", SourceChannelID: "source"})
+
+	if delivered == nil || delivered.ChannelID != "thread-guild" || delivered.GuildID != "guild" {
+		t.Fatalf("expected delivery to the fallback thread, got %+v", delivered)
+	}
+}
+
+func TestTooLargeDeliveryFallsBack(t *testing.T) {
+	service, err := NewService(nil, "")
+	if err != nil {
+		t.Fatalf("NewService returned error: %v", err)
+	}
+	var sentFiles []int
+	service.send = func(channelID string, message *discordgo.MessageSend) (*discordgo.Message, error) {
+		sentFiles = append(sentFiles, len(message.Files))
+		if len(message.Files) > 1 {
+			return nil, restError(http.StatusRequestEntityTooLarge, `{"message": "Request entity too large", "code": 40005}`)
+		}
+		return &discordgo.Message{ID: "sent", ChannelID: channelID}, nil
+	}
+
+	delivered := service.Enqueue(Delivery{
+		UserID:    "user",
+		ChannelID: "channel",
+		Message:   Message{Files: []File{{Name: "a.png", Data: []byte("a")}, {Name: "b.png", Data: []byte("b")}}},
+		TooLarge:  &Message{Files: []File{{Name: "a.png", Data: []byte("a")}}},
+	})
+
+	if !delivered || len(sentFiles) != 2 || sentFiles[1] != 1 {
+		t.Fatalf("expected the smaller message after the refused upload, got %v (delivered %v)", sentFiles, delivered)
+	}
+	if service.Pending() != 0 {
+		t.Fatalf("expected nothing left to retry, got %d", service.Pending())
+	}
+}
+
+func TestForumDeliveryStartsPost(t *testing.T) {
+	service, err := NewService(nil, "")
+	if err != nil {
+		t.Fatalf("NewService returned error: %v", err)
+	}
+	service.send = func(string, *discordgo.MessageSend) (*discordgo.Message, error) {
+		t.Fatalf("forum deliveries must not be sent as plain messages")
+		return nil, nil
+	}
+
+	var started *ForumPost
+	service.startForum = func(channelID string, post *ForumPost, message *discordgo.MessageSend) (*discordgo.Message, error) {
+		started = post
+		return &discordgo.Message{ID: "post", ChannelID: "post"}, nil
+	}
+
+	service.Enqueue(Delivery{UserID: "user", ChannelID: "forum", Forum: &ForumPost{Title: "Read this", AppliedTags: []string{"tag"}}})
+
+	if started == nil || started.Title != "Read this" || len(started.AppliedTags) != 1 {
+		t.Fatalf("expected a forum post to be started, got %+v", started)
+	}
+}
+
+func TestKindDeliveryUsesRegisteredSender(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.json")
+	service, err := NewService(nil, path)
+	if err != nil {
+		t.Fatalf("NewService returned error: %v", err)
+	}
+	defer service.Close()
+	service.send = func(string, *discordgo.MessageSend) (*discordgo.Message, error) {
+		t.Fatalf("kind deliveries must not be sent to Discord")
+		return nil, nil
+	}
+
+	var payloads []string
+	service.RegisterSender("webhook", func(delivery Delivery) error {
+		var payload struct {
+			URL string `json:"url"`
+		}
+		if err := json.Unmarshal(delivery.Payload, &payload); err != nil {
+			t.Fatalf("decoding payload returned error: %v", err)
+		}
+		payloads = append(payloads, payload.URL)
+		if len(payloads) == 1 {
+			return &RetryError{Err: errors.New("webhook responded 503"), After: time.Hour}
+		}
+		return nil
+	})
+
+	var delivered *Delivery
+	service.OnDelivered(func(_ *discordgo.Session, delivery Delivery, sent *discordgo.Message) {
+		if sent != nil {
+			t.Errorf("expected no sent message, got %+v", sent)
+		}
+		delivered = &delivery
+	})
+
+	service.Enqueue(Delivery{ID: "doc", UserID: "user", Kind: "webhook", Payload: []byte(`{"url":"https://example.com"}`)})
+	if delivered != nil || service.Pending() != 1 {
+		t.Fatalf("expected the failed delivery to wait for a retry")
+	}
+	service.Close()
+
+	reloaded, err := NewService(nil, path)
+	if err != nil {
+		t.Fatalf("reloading outbox returned error: %v", err)
+	}
+	reloaded.RegisterSender("webhook", service.senders["webhook"])
+	reloaded.OnDelivered(service.onDelivered)
+	reloaded.attempt("doc")
+
+	if delivered == nil || delivered.Kind != "webhook" || reloaded.Pending() != 0 {
+		t.Fatalf("expected the restored delivery to be sent, got %+v", delivered)
+	}
+	if len(payloads) != 2 || payloads[1] != "https://example.com" {
+		t.Fatalf("expected the payload to survive the restart, got %v", payloads)
+	}
+}
@@ -4,23 +4,106 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"html"
 	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
+
+	"github.com/example/discord-bookmark-manager/internal/email"
+	"github.com/example/discord-bookmark-manager/internal/ics"
+	"github.com/example/discord-bookmark-manager/internal/outbox"
 )
 
 // Payload contains contextual information used when sending the reminder message.
 type Payload struct {
-	ChannelID      string
-	JumpURL        string
-	BookmarkURL    string
-	ChannelName    string
-	ContentSnippet string
+	ChannelID string
+	// UserID, GuildID and SourceChannelID pick the DM fallback when the user's DMs are closed.
+	UserID          string `json:",omitempty"`
+	GuildID         string `json:",omitempty"`
+	SourceChannelID string `json:",omitempty"`
+	JumpURL         string
+	BookmarkURL     string
+	ChannelName     string
+	ContentSnippet  string
+	// Email sends the reminder to this verified address instead of Discord. ChannelID, when
+	// set, is used if the email cannot be sent.
+	Email string `json:",omitempty"`
+	// Daily repeats the reminder at the same time every day until it is cancelled.
+	Daily bool `json:",omitempty"`
+	// AssigneeID, when set, receives the reminder in AssigneeChannelID instead of UserID, who
+	// saved the bookmark and assigned it.
+	AssigneeID        string `json:",omitempty"`
+	AssigneeChannelID string `json:",omitempty"`
+}
+
+// Recipient returns who the reminder is delivered to.
+func (p Payload) Recipient() string {
+	if p.AssigneeID != "" {
+		return p.AssigneeID
+	}
+
+	return p.UserID
+}
+
+// routed returns the payload as delivered: assigned reminders go to the assignee's DMs, with
+// their DM fallback, and never to the saver's email.
+func (p Payload) routed() Payload {
+	if p.AssigneeID == "" {
+		return p
+	}
+
+	p.UserID = p.AssigneeID
+	p.ChannelID = p.AssigneeChannelID
+	p.Email = ""
+	return p
+}
+
+// CalendarEvent describes the reminder id due at when for calendar apps.
+func (p Payload) CalendarEvent(id string, when time.Time) ics.Event {
+	summary := fmt.Sprintf("⏰ Bookmark from #%s", p.ChannelName)
+	if p.ContentSnippet != "" {
+		summary = fmt.Sprintf("⏰ #%s: %s", p.ChannelName, firstLine(p.ContentSnippet))
+	}
+
+	var description []string
+	if p.ContentSnippet != "" {
+		description = append(description, p.ContentSnippet)
+	}
+	if p.BookmarkURL != "" {
+		description = append(description, "Saved bookmark: "+p.BookmarkURL)
+	}
+
+	return ics.Event{
+		UID:         id,
+		Start:       when,
+		Summary:     summary,
+		Description: strings.Join(description, "\n\n"),
+		URL:         p.JumpURL,
+		Daily:       p.Daily,
+	}
+}
+
+func firstLine(value string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(value), "\n")
+	if runes := []rune(line); len(runes) > 80 {
+		line = string(runes[:79]) + "…"
+	}
+
+	return line
+}
+
+// Pending is a scheduled reminder.
+type Pending struct {
+	ID      string
+	When    time.Time
+	Payload Payload
 }
 
 type scheduledReminder struct {
@@ -36,6 +119,8 @@ type Service struct {
 	mu        sync.Mutex
 	scheduled map[string]*scheduledReminder
 	filePath  string
+	fallback  outbox.DMFallbackFunc
+	mailer    *email.Sender
 }
 
 type persistedReminder struct {
@@ -59,6 +144,20 @@ func NewService(session *discordgo.Session, filePath string) (*Service, error) {
 	return service, nil
 }
 
+// SetDMFallback sets where reminders go when the user's DMs are closed.
+func (s *Service) SetDMFallback(fn outbox.DMFallbackFunc) {
+	s.mu.Lock()
+	s.fallback = fn
+	s.mu.Unlock()
+}
+
+// SetEmail sets the sender used for reminders with an email address.
+func (s *Service) SetEmail(sender *email.Sender) {
+	s.mu.Lock()
+	s.mailer = sender
+	s.mu.Unlock()
+}
+
 // Schedule registers a reminder for the given bookmark message ID.
 func (s *Service) Schedule(messageID string, when time.Time, payload Payload, removeOnComplete bool) {
 	if when.IsZero() {
@@ -73,6 +172,46 @@ func (s *Service) Schedule(messageID string, when time.Time, payload Payload, re
 	s.mu.Unlock()
 }
 
+// ForUser returns the reminders scheduled for userID, soonest first.
+func (s *Service) ForUser(userID string) []Pending {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var pending []Pending
+	for id, reminder := range s.scheduled {
+		if reminder.payload.Recipient() == userID {
+			pending = append(pending, Pending{ID: id, When: reminder.when, Payload: reminder.payload})
+		}
+	}
+
+	sort.Slice(pending, func(i, j int) bool { return pending[i].When.Before(pending[j].When) })
+	return pending
+}
+
+// Assign sends the pending reminder for messageID to assigneeID in channelID, their DM
+// channel. An empty assigneeID returns it to the saver. It reports whether a reminder was
+// pending.
+func (s *Service) Assign(messageID, assigneeID, channelID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reminder, ok := s.scheduled[messageID]
+	if !ok {
+		return false
+	}
+
+	reminder.payload.AssigneeID = assigneeID
+	reminder.payload.AssigneeChannelID = channelID
+	if assigneeID == "" {
+		reminder.payload.AssigneeChannelID = ""
+	}
+	if err := s.persistLocked(); err != nil {
+		log.Printf("failed to persist reminders: %v", err)
+	}
+
+	return true
+}
+
 // Cancel removes any pending reminder for the provided bookmark message ID.
 func (s *Service) Cancel(messageID string) {
 	s.mu.Lock()
@@ -119,7 +258,11 @@ func (s *Service) deliver(messageID string) {
 	s.mu.Lock()
 	reminder, ok := s.scheduled[messageID]
 	if ok {
-		delete(s.scheduled, messageID)
+		if reminder.payload.Daily {
+			s.scheduleLocked(messageID, nextDay(reminder.when, time.Now()), reminder.payload, reminder.removeOnComplete)
+		} else {
+			delete(s.scheduled, messageID)
+		}
 		if err := s.persistLocked(); err != nil {
 			log.Printf("failed to persist reminders: %v", err)
 		}
@@ -130,42 +273,127 @@ func (s *Service) deliver(messageID string) {
 		return
 	}
 
+	payload := reminder.payload.routed()
+	if payload.Email != "" {
+		err := s.deliverEmail(payload)
+		if err == nil {
+			return
+		}
+		log.Printf("failed to email reminder: %v", err)
+		if payload.ChannelID == "" {
+			return
+		}
+	}
+
+	description := fmt.Sprintf("Take another look at #%s.", payload.ChannelName)
+	if reminder.payload.AssigneeID != "" {
+		description = fmt.Sprintf("<@%s> assigned you a bookmark from #%s.", reminder.payload.UserID, payload.ChannelName)
+	}
+
 	embed := &discordgo.MessageEmbed{
 		Title:       "⏰ Reminder",
-		Description: fmt.Sprintf("Take another look at #%s.", reminder.payload.ChannelName),
+		Description: description,
 		Color:       0xFEE75C,
 		Timestamp:   time.Now().Format(time.RFC3339),
 	}
 
-	if reminder.payload.ContentSnippet != "" {
+	if payload.ContentSnippet != "" {
 		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
 			Name:  "📝 Note",
-			Value: reminder.payload.ContentSnippet,
+			Value: payload.ContentSnippet,
 		})
 	}
 
-	if reminder.payload.JumpURL != "" {
+	if payload.JumpURL != "" {
 		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
 			Name:  "🔗 Source Message",
-			Value: fmt.Sprintf("[Open message](%s)", reminder.payload.JumpURL),
+			Value: fmt.Sprintf("[Open message](%s)", payload.JumpURL),
 		})
 	}
 
-	if reminder.payload.BookmarkURL != "" {
+	if payload.BookmarkURL != "" {
 		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
 			Name:  "📬 Saved Bookmark",
-			Value: fmt.Sprintf("[Open DM](%s)", reminder.payload.BookmarkURL),
+			Value: fmt.Sprintf("[Open DM](%s)", payload.BookmarkURL),
 		})
 	}
 
-	_, err := s.session.ChannelMessageSendComplex(reminder.payload.ChannelID, &discordgo.MessageSend{
+	message := &discordgo.MessageSend{
 		Embeds: []*discordgo.MessageEmbed{embed},
-	})
+	}
+	_, err := s.session.ChannelMessageSendComplex(payload.ChannelID, message)
+	if err != nil && outbox.IsDMClosed(err) {
+		err = s.deliverFallback(payload, message, err)
+	}
 	if err != nil {
 		log.Printf("failed to deliver reminder: %v", err)
 	}
 }
 
+// nextDay returns the first occurrence of when's time of day, on a later day, that is after
+// now.
+func nextDay(when, now time.Time) time.Time {
+	next := when.AddDate(0, 0, 1)
+	for !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+
+	return next
+}
+
+// deliverEmail sends the reminder to payload.Email.
+func (s *Service) deliverEmail(payload Payload) error {
+	s.mu.Lock()
+	mailer := s.mailer
+	s.mu.Unlock()
+	if !mailer.Enabled() {
+		return errors.New("email is not configured")
+	}
+
+	text := []string{fmt.Sprintf("Take another look at #%s.", payload.ChannelName)}
+	body := []string{fmt.Sprintf("<p>Take another look at #%s.</p>", html.EscapeString(payload.ChannelName))}
+	if payload.ContentSnippet != "" {
+		text = append(text, "Note: "+payload.ContentSnippet)
+		body = append(body, fmt.Sprintf("<p><strong>📝 Note</strong><br>%s</p>", html.EscapeString(payload.ContentSnippet)))
+	}
+	if payload.JumpURL != "" {
+		text = append(text, "Source message: "+payload.JumpURL)
+		body = append(body, fmt.Sprintf(`<p><a href="%s">🔗 Open message</a></p>`, html.EscapeString(payload.JumpURL)))
+	}
+	if payload.BookmarkURL != "" {
+		text = append(text, "Saved bookmark: "+payload.BookmarkURL)
+		body = append(body, fmt.Sprintf(`<p><a href="%s">📬 Open saved bookmark</a></p>`, html.EscapeString(payload.BookmarkURL)))
+	}
+
+	return mailer.Send(email.Message{
+		To:      payload.Email,
+		Subject: fmt.Sprintf("⏰ Reminder: #%s", payload.ChannelName),
+		Text:    strings.Join(text, "\n\n"),
+		HTML:    strings.Join(body, "\n"),
+	})
+}
+
+// deliverFallback resends a reminder the user's closed DMs refused to the configured fallback.
+func (s *Service) deliverFallback(payload Payload, message *discordgo.MessageSend, dmErr error) error {
+	s.mu.Lock()
+	fallback := s.fallback
+	s.mu.Unlock()
+	if fallback == nil || payload.UserID == "" {
+		return dmErr
+	}
+
+	channelID, err := fallback(s.session, payload.UserID, payload.GuildID, payload.SourceChannelID)
+	if err != nil {
+		return fmt.Errorf("%v; no fallback: %w", dmErr, err)
+	}
+
+	// Fallback channels are shared, so mention the user to make sure they notice.
+	message.Content = fmt.Sprintf("<@%s>", payload.UserID)
+	message.AllowedMentions = &discordgo.MessageAllowedMentions{Users: []string{payload.UserID}}
+	_, err = s.session.ChannelMessageSendComplex(channelID, message)
+	return err
+}
+
 func (s *Service) scheduleLocked(messageID string, when time.Time, payload Payload, removeOnComplete bool) {
 	delay := time.Until(when)
 	if delay <= 0 {
@@ -0,0 +1,516 @@
+package store
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Bookmark records a saved bookmark message together with the source it was copied from.
+type Bookmark struct {
+	ID              string          `json:"id"`
+	UserID          string          `json:"userId"`
+	Emoji           string          `json:"emoji"`
+	Mode            BookmarkMode    `json:"mode"`
+	Color           int             `json:"color"`
+	Capture         CaptureMode     `json:"capture,omitempty"`
+	Destination     DestinationType `json:"destination,omitempty"`
+	TrackEdits      bool            `json:"trackEdits,omitempty"`
+	SourceGuildID   string          `json:"sourceGuildId,omitempty"`
+	SourceChannelID string          `json:"sourceChannelId"`
+	SourceMessageID string          `json:"sourceMessageId"`
+	SavedGuildID    string          `json:"savedGuildId,omitempty"`
+	SavedChannelID  string          `json:"savedChannelId"`
+	SavedMessageID  string          `json:"savedMessageId"`
+	// ForumChannelID is set when the bookmark is its own post in this forum channel. The
+	// post's thread is SavedChannelID.
+	ForumChannelID string `json:"forumChannelId,omitempty"`
+	// VaultPath is the note written for vault bookmarks, relative to the user's vault
+	// directory. The saved message is the receipt sent to the user's DMs.
+	VaultPath           string    `json:"vaultPath,omitempty"`
+	ChannelName         string    `json:"channelName,omitempty"`
+	ReminderDescription string    `json:"reminderDescription,omitempty"`
+	Completed           bool      `json:"completed,omitempty"`
+	SourceDeleted       bool      `json:"sourceDeleted,omitempty"`
+	SavedAt             time.Time `json:"savedAt"`
+	// Title, Content, AuthorName and AttachmentURLs describe the saved message for the
+	// user's feed. Redacted bookmarks keep none of the content.
+	Title          string   `json:"title,omitempty"`
+	Content        string   `json:"content,omitempty"`
+	AuthorName     string   `json:"authorName,omitempty"`
+	AttachmentURLs []string `json:"attachmentUrls,omitempty"`
+	// Savers lists everyone who saved this channel post, the original saver first. It stays
+	// empty until a second member saves the same message to the same channel.
+	Savers []Saver `json:"savers,omitempty"`
+	// AssigneeID is the member who claimed this channel post, or was assigned it, to read it
+	// for the team.
+	AssigneeID string `json:"assigneeId,omitempty"`
+	// Votes maps members to their vote on this channel post, VoteUp or VoteDown.
+	Votes map[string]int `json:"votes,omitempty"`
+}
+
+// Vote values a member can cast on a channel post.
+const (
+	VoteUp   = 1
+	VoteDown = -1
+)
+
+// Saver is one member who saved a shared channel post, with their own Done state.
+type Saver struct {
+	UserID  string    `json:"userId"`
+	SavedAt time.Time `json:"savedAt"`
+	Done    bool      `json:"done,omitempty"`
+}
+
+// Shared reports whether more than one member saved the bookmark.
+func (b Bookmark) Shared() bool {
+	return len(b.Savers) > 1
+}
+
+// SavedBy reports whether userID saved the bookmark.
+func (b Bookmark) SavedBy(userID string) bool {
+	if b.UserID == userID {
+		return true
+	}
+	for _, saver := range b.Savers {
+		if saver.UserID == userID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Tally counts the up and down votes on the bookmark.
+func (b Bookmark) Tally() (up, down int) {
+	for _, vote := range b.Votes {
+		switch vote {
+		case VoteUp:
+			up++
+		case VoteDown:
+			down++
+		}
+	}
+
+	return up, down
+}
+
+// Score is the bookmark's up votes minus its down votes.
+func (b Bookmark) Score() int {
+	up, down := b.Tally()
+	return up - down
+}
+
+// NewBookmarkID returns a short random identifier for a bookmark.
+func NewBookmarkID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+
+	return hex.EncodeToString(buf)
+}
+
+// BookmarkStore indexes saved bookmarks by ID, saved message, and source message.
+type BookmarkStore struct {
+	mu        sync.RWMutex
+	bookmarks map[string]Bookmark
+	// bySource maps source message IDs to the IDs of the bookmarks copied from them, and
+	// bySaved maps saved message IDs to their bookmark's ID.
+	bySource map[string][]string
+	bySaved  map[string]string
+	filePath string
+}
+
+// NewBookmarkStore initializes a BookmarkStore and loads any persisted data from filePath.
+//
+// If filePath is empty, the store behaves as an in-memory only store.
+func NewBookmarkStore(filePath string) (*BookmarkStore, error) {
+	store := &BookmarkStore{
+		bookmarks: make(map[string]Bookmark),
+		bySource:  make(map[string][]string),
+		bySaved:   make(map[string]string),
+		filePath:  filePath,
+	}
+
+	if filePath == "" {
+		return store, nil
+	}
+
+	if err := store.load(); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return store, nil
+		}
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// Add stores a bookmark, replacing any existing entry with the same ID.
+func (s *BookmarkStore) Add(bookmark Bookmark) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, existed := s.bookmarks[bookmark.ID]
+	s.putLocked(bookmark)
+
+	if err := s.saveLocked(); err != nil {
+		if existed {
+			s.putLocked(previous)
+		} else {
+			s.removeLocked(bookmark.ID)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Get retrieves a bookmark by ID.
+func (s *BookmarkStore) Get(id string) (Bookmark, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	bookmark, ok := s.bookmarks[id]
+	return bookmark, ok
+}
+
+// BySavedMessage retrieves the bookmark that was delivered as messageID.
+func (s *BookmarkStore) BySavedMessage(messageID string) (Bookmark, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	bookmark, ok := s.bookmarks[s.bySaved[messageID]]
+	return bookmark, ok
+}
+
+// BySource returns every bookmark copied from the source message ID.
+func (s *BookmarkStore) BySource(messageID string) []Bookmark {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var matches []Bookmark
+	for _, id := range s.bySource[messageID] {
+		matches = append(matches, s.bookmarks[id])
+	}
+
+	return matches
+}
+
+// ByDestination returns the open bookmark copied from the source message ID into channelID,
+// either as a message in it or as a post when channelID is a forum. Thread and range
+// captures are never matched.
+func (s *BookmarkStore) ByDestination(sourceMessageID, channelID string) (Bookmark, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, id := range s.bySource[sourceMessageID] {
+		bookmark := s.bookmarks[id]
+		if bookmark.Completed || bookmark.SavedMessageID == "" {
+			continue
+		}
+		if bookmark.Capture != "" && bookmark.Capture != CaptureMessage {
+			continue
+		}
+		if bookmark.SavedChannelID == channelID || bookmark.ForumChannelID == channelID {
+			return bookmark, true
+		}
+	}
+
+	return Bookmark{}, false
+}
+
+// ByUser returns the bookmarks saved by userID, including shared posts they joined, newest
+// first.
+func (s *BookmarkStore) ByUser(userID string) []Bookmark {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var matches []Bookmark
+	for _, bookmark := range s.bookmarks {
+		if bookmark.SavedBy(userID) {
+			matches = append(matches, bookmark)
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool { return matches[i].SavedAt.After(matches[j].SavedAt) })
+	return matches
+}
+
+// Top returns the open bookmarks posted to channelID since the given time with a positive
+// score, highest first, at most limit of them. A zero since covers every bookmark.
+func (s *BookmarkStore) Top(channelID string, since time.Time, limit int) []Bookmark {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var matches []Bookmark
+	for _, bookmark := range s.bookmarks {
+		if bookmark.Completed || bookmark.SavedMessageID == "" || bookmark.SavedAt.Before(since) {
+			continue
+		}
+		if bookmark.SavedChannelID != channelID && bookmark.ForumChannelID != channelID {
+			continue
+		}
+		if bookmark.Score() > 0 {
+			matches = append(matches, bookmark)
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if scoreI, scoreJ := matches[i].Score(), matches[j].Score(); scoreI != scoreJ {
+			return scoreI > scoreJ
+		}
+		return matches[i].SavedAt.After(matches[j].SavedAt)
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	return matches
+}
+
+// Update applies fn to the stored bookmark and persists the result.
+func (s *BookmarkStore) Update(id string, fn func(*Bookmark)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, ok := s.bookmarks[id]
+	if !ok {
+		return nil
+	}
+
+	next := previous
+	fn(&next)
+	next.ID = id
+	s.putLocked(next)
+
+	if err := s.saveLocked(); err != nil {
+		s.putLocked(previous)
+		return err
+	}
+
+	return nil
+}
+
+// AddSaver records userID as another saver of the bookmark. It returns the updated bookmark
+// and false when userID had already saved it.
+func (s *BookmarkStore) AddSaver(id, userID string, savedAt time.Time) (Bookmark, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, ok := s.bookmarks[id]
+	if !ok {
+		return Bookmark{}, false, nil
+	}
+	if previous.SavedBy(userID) {
+		return previous, false, nil
+	}
+
+	next := previous
+	next.Savers = make([]Saver, 0, len(previous.Savers)+2)
+	if len(previous.Savers) == 0 {
+		next.Savers = append(next.Savers, Saver{UserID: previous.UserID, SavedAt: previous.SavedAt})
+	}
+	next.Savers = append(next.Savers, previous.Savers...)
+	next.Savers = append(next.Savers, Saver{UserID: userID, SavedAt: savedAt})
+	s.putLocked(next)
+
+	if err := s.saveLocked(); err != nil {
+		s.putLocked(previous)
+		return Bookmark{}, false, err
+	}
+
+	return next, true, nil
+}
+
+// MarkSaverDone records that userID is done with the shared bookmark, or that everyone is
+// when userID is empty. The bookmark is completed once every saver is done.
+func (s *BookmarkStore) MarkSaverDone(id, userID string) (Bookmark, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, ok := s.bookmarks[id]
+	if !ok {
+		return Bookmark{}, nil
+	}
+
+	next := previous
+	next.Savers = make([]Saver, len(previous.Savers))
+	allDone := true
+	for idx, saver := range previous.Savers {
+		if userID == "" || saver.UserID == userID {
+			saver.Done = true
+		}
+		allDone = allDone && saver.Done
+		next.Savers[idx] = saver
+	}
+	next.Completed = allDone
+	s.putLocked(next)
+
+	if err := s.saveLocked(); err != nil {
+		s.putLocked(previous)
+		return Bookmark{}, err
+	}
+
+	return next, nil
+}
+
+// Vote records userID's vote on the bookmark. Casting the same vote again withdraws it.
+// It returns the updated bookmark.
+func (s *BookmarkStore) Vote(id, userID string, vote int) (Bookmark, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, ok := s.bookmarks[id]
+	if !ok {
+		return Bookmark{}, nil
+	}
+
+	next := previous
+	next.Votes = make(map[string]int, len(previous.Votes)+1)
+	for voter, cast := range previous.Votes {
+		next.Votes[voter] = cast
+	}
+	if next.Votes[userID] == vote {
+		delete(next.Votes, userID)
+	} else {
+		next.Votes[userID] = vote
+	}
+	s.putLocked(next)
+
+	if err := s.saveLocked(); err != nil {
+		s.putLocked(previous)
+		return Bookmark{}, err
+	}
+
+	return next, nil
+}
+
+// Delete removes a bookmark by ID. It returns true when a bookmark was removed.
+func (s *BookmarkStore) Delete(id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, ok := s.bookmarks[id]
+	if !ok {
+		return false, nil
+	}
+	s.removeLocked(id)
+
+	if err := s.saveLocked(); err != nil {
+		s.putLocked(previous)
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (s *BookmarkStore) load() error {
+	file, err := os.Open(s.filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var persisted map[string]Bookmark
+	decoder := json.NewDecoder(file)
+	if err := decoder.Decode(&persisted); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return err
+	}
+
+	for id, bookmark := range persisted {
+		bookmark.ID = id
+		s.putLocked(bookmark)
+	}
+
+	return nil
+}
+
+// putLocked stores bookmark and keeps the lookup indexes in step with it.
+func (s *BookmarkStore) putLocked(bookmark Bookmark) {
+	s.removeLocked(bookmark.ID)
+	s.bookmarks[bookmark.ID] = bookmark
+
+	if bookmark.SourceMessageID != "" {
+		s.bySource[bookmark.SourceMessageID] = append(s.bySource[bookmark.SourceMessageID], bookmark.ID)
+	}
+	if bookmark.SavedMessageID != "" {
+		s.bySaved[bookmark.SavedMessageID] = bookmark.ID
+	}
+}
+
+// removeLocked deletes the bookmark id and its index entries.
+func (s *BookmarkStore) removeLocked(id string) {
+	bookmark, ok := s.bookmarks[id]
+	if !ok {
+		return
+	}
+	delete(s.bookmarks, id)
+
+	if ids := s.bySource[bookmark.SourceMessageID]; len(ids) > 0 {
+		kept := make([]string, 0, len(ids)-1)
+		for _, other := range ids {
+			if other != id {
+				kept = append(kept, other)
+			}
+		}
+		if len(kept) == 0 {
+			delete(s.bySource, bookmark.SourceMessageID)
+		} else {
+			s.bySource[bookmark.SourceMessageID] = kept
+		}
+	}
+	if s.bySaved[bookmark.SavedMessageID] == id {
+		delete(s.bySaved, bookmark.SavedMessageID)
+	}
+}
+
+func (s *BookmarkStore) saveLocked() error {
+	if s.filePath == "" {
+		return nil
+	}
+
+	dir := filepath.Dir(s.filePath)
+	if dir != "." && dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+
+	tempFile, err := os.CreateTemp(dir, "bookmarks-*.json")
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(tempFile)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(s.bookmarks); err != nil {
+		tempFile.Close()
+		os.Remove(tempFile.Name())
+		return err
+	}
+
+	if err := tempFile.Close(); err != nil {
+		os.Remove(tempFile.Name())
+		return err
+	}
+
+	if err := os.Rename(tempFile.Name(), s.filePath); err != nil {
+		os.Remove(tempFile.Name())
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,216 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBookmarkStorePersistsAndIndexes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "saved.json")
+
+	bookmarks, err := NewBookmarkStore(path)
+	if err != nil {
+		t.Fatalf("NewBookmarkStore returned error: %v", err)
+	}
+
+	bookmark := Bookmark{
+		ID:              NewBookmarkID(),
+		UserID:          "user",
+		SourceChannelID: "source-channel",
+		SourceMessageID: "source",
+		SavedChannelID:  "dm",
+		SavedMessageID:  "saved",
+		TrackEdits:      true,
+	}
+	if err := bookmarks.Add(bookmark); err != nil {
+		t.Fatalf("Add returned error: %v", err)
+	}
+
+	reloaded, err := NewBookmarkStore(path)
+	if err != nil {
+		t.Fatalf("reloading store returned error: %v", err)
+	}
+
+	matches := reloaded.BySource("source")
+	if len(matches) != 1 || matches[0].ID != bookmark.ID || !matches[0].TrackEdits {
+		t.Fatalf("expected reloaded bookmark by source, got %+v", matches)
+	}
+
+	if _, ok := reloaded.BySavedMessage("saved"); !ok {
+		t.Fatalf("expected lookup by saved message to succeed")
+	}
+}
+
+func TestBookmarkStoreUpdateAndDelete(t *testing.T) {
+	bookmarks, err := NewBookmarkStore("")
+	if err != nil {
+		t.Fatalf("NewBookmarkStore returned error: %v", err)
+	}
+
+	if err := bookmarks.Add(Bookmark{ID: "abc", SavedMessageID: "saved"}); err != nil {
+		t.Fatalf("Add returned error: %v", err)
+	}
+
+	if err := bookmarks.Update("abc", func(b *Bookmark) { b.Completed = true }); err != nil {
+		t.Fatalf("Update returned error: %v", err)
+	}
+	if stored, _ := bookmarks.Get("abc"); !stored.Completed {
+		t.Fatalf("expected bookmark to be marked complete")
+	}
+
+	removed, err := bookmarks.Delete("abc")
+	if err != nil || !removed {
+		t.Fatalf("expected Delete to remove the bookmark, got removed=%v err=%v", removed, err)
+	}
+	if _, ok := bookmarks.Get("abc"); ok {
+		t.Fatalf("expected bookmark to be gone")
+	}
+}
+
+func TestBookmarkStoreIndexesFollowChanges(t *testing.T) {
+	bookmarks, err := NewBookmarkStore("")
+	if err != nil {
+		t.Fatalf("NewBookmarkStore returned error: %v", err)
+	}
+
+	for _, bookmark := range []Bookmark{
+		{ID: "a", SourceMessageID: "source", SavedChannelID: "team", SavedMessageID: "saved-a"},
+		{ID: "b", SourceMessageID: "source", SavedChannelID: "dm", SavedMessageID: "saved-b"},
+	} {
+		if err := bookmarks.Add(bookmark); err != nil {
+			t.Fatalf("Add returned error: %v", err)
+		}
+	}
+
+	if err := bookmarks.Update("a", func(b *Bookmark) { b.SavedMessageID = "edited-a" }); err != nil {
+		t.Fatalf("Update returned error: %v", err)
+	}
+	if _, ok := bookmarks.BySavedMessage("saved-a"); ok {
+		t.Fatalf("expected the old saved message to be unindexed")
+	}
+	if found, ok := bookmarks.BySavedMessage("edited-a"); !ok || found.ID != "a" {
+		t.Fatalf("expected lookup by the new saved message, got %+v", found)
+	}
+	if found, ok := bookmarks.ByDestination("source", "team"); !ok || found.ID != "a" {
+		t.Fatalf("expected lookup by destination, got %+v", found)
+	}
+
+	if _, err := bookmarks.Delete("a"); err != nil {
+		t.Fatalf("Delete returned error: %v", err)
+	}
+	if matches := bookmarks.BySource("source"); len(matches) != 1 || matches[0].ID != "b" {
+		t.Fatalf("expected only the remaining bookmark by source, got %+v", matches)
+	}
+	if _, ok := bookmarks.ByDestination("source", "team"); ok {
+		t.Fatalf("expected the deleted bookmark to leave the destination index")
+	}
+}
+
+func TestSharedBookmarkTracksSaversDoneState(t *testing.T) {
+	bookmarks, err := NewBookmarkStore("")
+	if err != nil {
+		t.Fatalf("NewBookmarkStore returned error: %v", err)
+	}
+
+	if err := bookmarks.Add(Bookmark{
+		ID:              "shared",
+		UserID:          "alice",
+		SourceMessageID: "source",
+		SavedChannelID:  "team-reading",
+		SavedMessageID:  "post",
+		Capture:         CaptureMessage,
+	}); err != nil {
+		t.Fatalf("Add returned error: %v", err)
+	}
+
+	existing, ok := bookmarks.ByDestination("source", "team-reading")
+	if !ok || existing.ID != "shared" {
+		t.Fatalf("expected the post in team-reading, got %+v (found %v)", existing, ok)
+	}
+	if _, ok := bookmarks.ByDestination("source", "elsewhere"); ok {
+		t.Fatalf("expected no post in another channel")
+	}
+
+	if _, added, err := bookmarks.AddSaver("shared", "bob", time.Now()); err != nil || !added {
+		t.Fatalf("expected bob to be added, got %v, %v", added, err)
+	}
+	if _, added, _ := bookmarks.AddSaver("shared", "alice", time.Now()); added {
+		t.Fatalf("expected the original saver not to be added twice")
+	}
+	if matches := bookmarks.ByUser("bob"); len(matches) != 1 {
+		t.Fatalf("expected the shared post among bob's bookmarks, got %+v", matches)
+	}
+
+	updated, err := bookmarks.MarkSaverDone("shared", "bob")
+	if err != nil {
+		t.Fatalf("MarkSaverDone returned error: %v", err)
+	}
+	if updated.Completed || len(updated.Savers) != 2 || updated.Savers[0].UserID != "alice" || !updated.Savers[1].Done {
+		t.Fatalf("expected only bob to be done, got %+v", updated)
+	}
+
+	if updated, _ = bookmarks.MarkSaverDone("shared", "alice"); !updated.Completed {
+		t.Fatalf("expected the post to complete once everyone is done, got %+v", updated)
+	}
+	if _, ok := bookmarks.ByDestination("source", "team-reading"); ok {
+		t.Fatalf("expected completed posts not to be joined")
+	}
+}
+
+func TestBookmarkVotesToggleAndRank(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "saved.json")
+	bookmarks, err := NewBookmarkStore(path)
+	if err != nil {
+		t.Fatalf("NewBookmarkStore returned error: %v", err)
+	}
+
+	now := time.Now()
+	for _, bookmark := range []Bookmark{
+		{ID: "old", SavedChannelID: "team-reading", SavedMessageID: "1", SavedAt: now.Add(-30 * 24 * time.Hour)},
+		{ID: "liked", SavedChannelID: "team-reading", SavedMessageID: "2", SavedAt: now.Add(-time.Hour)},
+		{ID: "loved", SavedChannelID: "team-reading", SavedMessageID: "3", SavedAt: now.Add(-2 * time.Hour)},
+		{ID: "done", SavedChannelID: "team-reading", SavedMessageID: "4", SavedAt: now, Completed: true},
+	} {
+		if err := bookmarks.Add(bookmark); err != nil {
+			t.Fatalf("Add returned error: %v", err)
+		}
+	}
+
+	votes := []struct {
+		id, userID string
+		vote       int
+	}{
+		{"old", "alice", VoteUp}, {"old", "bob", VoteUp}, {"old", "carol", VoteUp},
+		{"liked", "alice", VoteUp},
+		{"loved", "alice", VoteUp}, {"loved", "bob", VoteUp}, {"loved", "carol", VoteDown},
+		{"done", "alice", VoteUp},
+	}
+	for _, v := range votes {
+		if _, err := bookmarks.Vote(v.id, v.userID, v.vote); err != nil {
+			t.Fatalf("Vote returned error: %v", err)
+		}
+	}
+
+	// Voting the same way again withdraws the vote; voting the other way switches it.
+	if updated, _ := bookmarks.Vote("loved", "carol", VoteDown); updated.Score() != 2 {
+		t.Fatalf("expected carol's down vote to be withdrawn, got %+v", updated.Votes)
+	}
+	if updated, _ := bookmarks.Vote("liked", "alice", VoteDown); updated.Score() != -1 {
+		t.Fatalf("expected alice's vote to switch, got %+v", updated.Votes)
+	}
+	bookmarks.Vote("liked", "alice", VoteUp)
+
+	reloaded, err := NewBookmarkStore(path)
+	if err != nil {
+		t.Fatalf("reloading returned error: %v", err)
+	}
+
+	top := reloaded.Top("team-reading", now.Add(-7*24*time.Hour), 10)
+	if len(top) != 2 || top[0].ID != "loved" || top[1].ID != "liked" {
+		t.Fatalf("expected loved then liked this week, got %+v", top)
+	}
+	if all := reloaded.Top("team-reading", time.Time{}, 1); len(all) != 1 || all[0].ID != "old" {
+		t.Fatalf("expected old to lead over all time, got %+v", all)
+	}
+}
@@ -0,0 +1,235 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/example/discord-bookmark-manager/internal/emojikey"
+)
+
+// SourceDeleteAction controls what happens to saved bookmarks when their source is deleted.
+type SourceDeleteAction string
+
+const (
+	// SourceDeleteAnnotate keeps the saved copy and marks the source as deleted.
+	SourceDeleteAnnotate SourceDeleteAction = "annotate"
+	// SourceDeletePurge deletes saved copies together with their source.
+	SourceDeletePurge SourceDeleteAction = "purge"
+)
+
+// PrivacyAction controls how bookmarks are handled when the destination channel has a
+// broader audience than the source channel.
+type PrivacyAction string
+
+const (
+	// PrivacyRefuse does not post the bookmark.
+	PrivacyRefuse PrivacyAction = "refuse"
+	// PrivacyRedact posts the bookmark without the source content.
+	PrivacyRedact PrivacyAction = "redact"
+)
+
+// EmojiPrecedence decides which mapping a reaction uses when a member's personal emoji is
+// also one of the server's team emojis.
+type EmojiPrecedence string
+
+const (
+	// PrecedenceBoth saves the message with the team mapping and the personal one.
+	PrecedenceBoth EmojiPrecedence = "both"
+	// PrecedenceTeam saves with the team mapping only.
+	PrecedenceTeam EmojiPrecedence = "team"
+	// PrecedencePersonal saves with the personal mapping only; the team mapping applies to
+	// members who have not configured the emoji themselves.
+	PrecedencePersonal EmojiPrecedence = "personal"
+)
+
+// GuildSettings stores server-wide configuration managed by guild admins.
+type GuildSettings struct {
+	OnSourceDelete SourceDeleteAction `json:"onSourceDelete,omitempty"`
+	Privacy        PrivacyAction      `json:"privacy,omitempty"`
+	// Webhook receives webhook bookmarks saved in this server by members without their own.
+	Webhook *WebhookEndpoint `json:"webhook,omitempty"`
+	// TeamEmojis applies to every member reacting in this server, keyed like personal emojis.
+	TeamEmojis      map[string]EmojiPreference `json:"teamEmojis,omitempty"`
+	EmojiPrecedence EmojiPrecedence            `json:"emojiPrecedence,omitempty"`
+	// Starboard showcases messages that enough members reacted to. Nil turns it off.
+	Starboard *StarboardSettings `json:"starboard,omitempty"`
+}
+
+// StarboardSettings configures a server's hall of fame.
+type StarboardSettings struct {
+	Emoji     string `json:"emoji"`
+	ChannelID string `json:"channelId"`
+	// Threshold is how many distinct members must react before a message is showcased.
+	Threshold int `json:"threshold"`
+}
+
+func normalizeGuildSettings(settings GuildSettings) GuildSettings {
+	if settings.OnSourceDelete == "" {
+		settings.OnSourceDelete = SourceDeleteAnnotate
+	}
+
+	if settings.Privacy == "" {
+		settings.Privacy = PrivacyRefuse
+	}
+
+	if settings.EmojiPrecedence == "" {
+		settings.EmojiPrecedence = PrecedenceBoth
+	}
+
+	if settings.Starboard != nil {
+		starboard := *settings.Starboard
+		starboard.Emoji = emojikey.Normalize(starboard.Emoji)
+		settings.Starboard = &starboard
+	}
+
+	if len(settings.TeamEmojis) > 0 {
+		emojis := make(map[string]EmojiPreference, len(settings.TeamEmojis))
+		for emoji, pref := range settings.TeamEmojis {
+			emojis[emojikey.Normalize(emoji)] = normalizeEmojiPreference(pref)
+		}
+		settings.TeamEmojis = emojis
+	} else {
+		settings.TeamEmojis = nil
+	}
+
+	return settings
+}
+
+// GuildStore provides thread-safe storage for guild specific settings.
+type GuildStore struct {
+	mu       sync.RWMutex
+	guilds   map[string]GuildSettings
+	filePath string
+}
+
+// NewGuildStore initializes a GuildStore and loads any persisted data from filePath.
+//
+// If filePath is empty, the store behaves as an in-memory only store.
+func NewGuildStore(filePath string) (*GuildStore, error) {
+	store := &GuildStore{
+		guilds:   make(map[string]GuildSettings),
+		filePath: filePath,
+	}
+
+	if filePath == "" {
+		return store, nil
+	}
+
+	if err := store.load(); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return store, nil
+		}
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// Get retrieves the settings for guildID, falling back to defaults when none are stored.
+func (s *GuildStore) Get(guildID string) GuildSettings {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return normalizeGuildSettings(s.guilds[guildID])
+}
+
+// TeamEmoji returns the team mapping for emoji in guildID, if any. With foldSkinTones, a
+// mapping for another tone of emoji matches too.
+func (s *GuildStore) TeamEmoji(guildID, emoji string, foldSkinTones bool) (EmojiPreference, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, pref, ok := emojikey.Lookup(s.guilds[guildID].TeamEmojis, emoji, foldSkinTones)
+	if !ok {
+		return EmojiPreference{}, false
+	}
+
+	return normalizeEmojiPreference(pref), true
+}
+
+// Update applies fn to the settings for guildID and persists the result.
+func (s *GuildStore) Update(guildID string, fn func(*GuildSettings)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, existed := s.guilds[guildID]
+	next := normalizeGuildSettings(previous)
+	fn(&next)
+	s.guilds[guildID] = normalizeGuildSettings(next)
+
+	if err := s.saveLocked(); err != nil {
+		if existed {
+			s.guilds[guildID] = previous
+		} else {
+			delete(s.guilds, guildID)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *GuildStore) load() error {
+	file, err := os.Open(s.filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var persisted map[string]GuildSettings
+	decoder := json.NewDecoder(file)
+	if err := decoder.Decode(&persisted); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return err
+	}
+
+	for guildID, settings := range persisted {
+		s.guilds[guildID] = normalizeGuildSettings(settings)
+	}
+
+	return nil
+}
+
+func (s *GuildStore) saveLocked() error {
+	if s.filePath == "" {
+		return nil
+	}
+
+	dir := filepath.Dir(s.filePath)
+	if dir != "." && dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+
+	tempFile, err := os.CreateTemp(dir, "guilds-*.json")
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(tempFile)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(s.guilds); err != nil {
+		tempFile.Close()
+		os.Remove(tempFile.Name())
+		return err
+	}
+
+	if err := tempFile.Close(); err != nil {
+		os.Remove(tempFile.Name())
+		return err
+	}
+
+	if err := os.Rename(tempFile.Name(), s.filePath); err != nil {
+		os.Remove(tempFile.Name())
+		return err
+	}
+
+	return nil
+}
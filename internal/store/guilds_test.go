@@ -0,0 +1,74 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestTeamEmojisPersistAndDefaultToBoth(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "guilds.json")
+
+	guilds, err := NewGuildStore(path)
+	if err != nil {
+		t.Fatalf("NewGuildStore returned error: %v", err)
+	}
+
+	if precedence := guilds.Get("guild").EmojiPrecedence; precedence != PrecedenceBoth {
+		t.Fatalf("expected both by default, got %q", precedence)
+	}
+
+	if err := guilds.Update("guild", func(settings *GuildSettings) {
+		settings.TeamEmojis = map[string]EmojiPreference{
+			"📚": {Mode: ModeBalanced, Destination: DestinationChannel, ChannelID: "team-reading-list"},
+		}
+	}); err != nil {
+		t.Fatalf("Update returned error: %v", err)
+	}
+
+	pref, ok := guilds.TeamEmoji("guild", "📚", false)
+	if !ok || pref.ChannelID != "team-reading-list" || pref.Capture != CaptureMessage {
+		t.Fatalf("expected the normalized team emoji, got %+v (found %v)", pref, ok)
+	}
+
+	snapshot := guilds.Get("guild")
+	if err := guilds.Update("guild", func(settings *GuildSettings) {
+		delete(settings.TeamEmojis, "📚")
+	}); err != nil {
+		t.Fatalf("Update returned error: %v", err)
+	}
+	if _, ok := snapshot.TeamEmojis["📚"]; !ok {
+		t.Fatalf("expected earlier snapshots to be unaffected by later updates")
+	}
+
+	reloaded, err := NewGuildStore(path)
+	if err != nil {
+		t.Fatalf("reloading store returned error: %v", err)
+	}
+	if _, ok := reloaded.TeamEmoji("guild", "📚", false); ok {
+		t.Fatalf("expected the removed team emoji to stay removed after reload")
+	}
+}
+
+func TestGuildEmojiKeysAreNormalized(t *testing.T) {
+	guilds, err := NewGuildStore("")
+	if err != nil {
+		t.Fatalf("NewGuildStore returned error: %v", err)
+	}
+
+	if err := guilds.Update("guild", func(settings *GuildSettings) {
+		settings.TeamEmojis = map[string]EmojiPreference{
+			"a:party:456": {Mode: ModeBalanced, Capture: CaptureRange, RangeEndEmoji: "a:stop:789"},
+		}
+		settings.Starboard = &StarboardSettings{Emoji: "a:star:321", ChannelID: "hall", Threshold: 3}
+	}); err != nil {
+		t.Fatalf("Update returned error: %v", err)
+	}
+
+	pref, ok := guilds.TeamEmoji("guild", "party:456", false)
+	if !ok || pref.RangeEndEmoji != "stop:789" {
+		t.Fatalf("expected the animated team emoji under party:456, got %+v (found %v)", pref, ok)
+	}
+	if emoji := guilds.Get("guild").Starboard.Emoji; emoji != "star:321" {
+		t.Fatalf("expected the starboard emoji star:321, got %q", emoji)
+	}
+}
@@ -1,13 +1,17 @@
 package store
 
 import (
+	"crypto/subtle"
 	"encoding/json"
 	"errors"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/example/discord-bookmark-manager/internal/emojikey"
 	"github.com/example/discord-bookmark-manager/internal/reminders"
 )
 
@@ -31,6 +35,27 @@ const (
 	DestinationDM DestinationType = "dm"
 	// DestinationChannel sends the bookmark to a guild text channel.
 	DestinationChannel DestinationType = "channel"
+	// DestinationWebhook posts the bookmark as JSON to the user's webhook, or the server's
+	// webhook when the user has none.
+	DestinationWebhook DestinationType = "webhook"
+	// DestinationVault writes the bookmark as a Markdown note into the user's vault directory
+	// and sends a receipt to the user's DMs.
+	DestinationVault DestinationType = "vault"
+	// DestinationEmail emails the bookmark to the user's verified address.
+	DestinationEmail DestinationType = "email"
+)
+
+// CaptureMode identifies how much of the conversation a reaction should save.
+type CaptureMode string
+
+const (
+	// CaptureMessage saves only the message that received the reaction.
+	CaptureMessage CaptureMode = "message"
+	// CaptureThread saves the whole thread or forum post started by the reacted message.
+	CaptureThread CaptureMode = "thread"
+	// CaptureRange marks the start of a range that ends when the user reacts with RangeEndEmoji
+	// later in the same channel.
+	CaptureRange CaptureMode = "range"
 )
 
 // EmojiPreference stores configuration for a specific emoji bookmark.
@@ -41,6 +66,40 @@ type EmojiPreference struct {
 	Reminder    *reminders.Preference `json:"reminder,omitempty"`
 	Destination DestinationType       `json:"destination,omitempty"`
 	ChannelID   string                `json:"channelId,omitempty"`
+	Capture     CaptureMode           `json:"capture,omitempty"`
+	// RangeEndEmoji is the marker that closes a range started with this emoji.
+	RangeEndEmoji string `json:"rangeEndEmoji,omitempty"`
+	// Archive re-uploads source attachments onto the bookmark instead of only linking them.
+	Archive bool `json:"archive,omitempty"`
+	// TrackEdits refreshes the saved bookmark when the source message is edited.
+	TrackEdits bool `json:"trackEdits,omitempty"`
+	// Silent removes the user's reaction after a successful save.
+	Silent bool `json:"silent,omitempty"`
+	// Targets lists extra destinations that receive the bookmark alongside the primary one.
+	Targets []DestinationTarget `json:"targets,omitempty"`
+}
+
+// DestinationTarget is one place a bookmark is delivered to, with its own layout.
+type DestinationTarget struct {
+	Destination DestinationType `json:"destination"`
+	ChannelID   string          `json:"channelId,omitempty"`
+	Mode        BookmarkMode    `json:"mode"`
+	Color       int             `json:"color"`
+	HasColor    bool            `json:"hasColor"`
+}
+
+// AllTargets returns the primary destination followed by the extra targets.
+func (p EmojiPreference) AllTargets() []DestinationTarget {
+	targets := make([]DestinationTarget, 0, len(p.Targets)+1)
+	targets = append(targets, DestinationTarget{
+		Destination: p.Destination,
+		ChannelID:   p.ChannelID,
+		Mode:        p.Mode,
+		Color:       p.Color,
+		HasColor:    p.HasColor,
+	})
+
+	return append(targets, p.Targets...)
 }
 
 func normalizeEmojiPreference(pref EmojiPreference) EmojiPreference {
@@ -48,16 +107,219 @@ func normalizeEmojiPreference(pref EmojiPreference) EmojiPreference {
 		pref.Destination = DestinationDM
 	}
 
+	if pref.Capture == "" {
+		pref.Capture = CaptureMessage
+	}
+
+	if pref.Capture != CaptureRange {
+		pref.RangeEndEmoji = ""
+	}
+	pref.RangeEndEmoji = emojikey.Normalize(pref.RangeEndEmoji)
+
 	if pref.Destination != DestinationChannel {
 		pref.ChannelID = ""
 	}
 
+	if len(pref.Targets) > 0 {
+		targets := make([]DestinationTarget, len(pref.Targets))
+		for idx, target := range pref.Targets {
+			if target.Destination == "" {
+				target.Destination = DestinationDM
+			}
+			if target.Destination != DestinationChannel {
+				target.ChannelID = ""
+			}
+			targets[idx] = target
+		}
+		pref.Targets = targets
+	}
+
 	return pref
 }
 
+// EmojiScope says where a personal emoji preference applies.
+type EmojiScope string
+
+const (
+	// ScopeGlobal preferences apply wherever no more specific preference exists. Preferences
+	// saved before scopes existed are global.
+	ScopeGlobal EmojiScope = ""
+	// ScopeDM preferences apply to reactions in direct messages.
+	ScopeDM EmojiScope = "dm"
+
+	guildScopePrefix = "guild:"
+)
+
+// GuildScope returns the scope of preferences that only apply in guildID.
+func GuildScope(guildID string) EmojiScope {
+	return EmojiScope(guildScopePrefix + guildID)
+}
+
+// ScopeOf returns the most specific scope of a reaction in guildID, which is empty in DMs.
+func ScopeOf(guildID string) EmojiScope {
+	if guildID == "" {
+		return ScopeDM
+	}
+
+	return GuildScope(guildID)
+}
+
+// GuildID returns the server a guild scope belongs to, or an empty string for other scopes.
+func (s EmojiScope) GuildID() string {
+	if guildID, ok := strings.CutPrefix(string(s), guildScopePrefix); ok {
+		return guildID
+	}
+
+	return ""
+}
+
 // UserPreferences stores the emoji and presentation configuration for a user.
 type UserPreferences struct {
+	// Emojis holds the user's global emoji preferences.
 	Emojis map[string]EmojiPreference `json:"emojis"`
+	// ScopedEmojis holds preferences that only apply in one server or in DMs, keyed by scope.
+	// They take precedence over global preferences for the same emoji.
+	ScopedEmojis map[EmojiScope]map[string]EmojiPreference `json:"scopedEmojis,omitempty"`
+	DMFallback   DMFallback                                `json:"dmFallback,omitempty"`
+	Webhook      *WebhookEndpoint                          `json:"webhook,omitempty"`
+	// VaultTemplate names the user's vault notes. Empty uses the bot-wide default.
+	VaultTemplate string         `json:"vaultTemplate,omitempty"`
+	Email         *EmailSettings `json:"email,omitempty"`
+	// FeedToken is the secret path segment of the user's bookmark feed.
+	FeedToken string `json:"feedToken,omitempty"`
+}
+
+// EmailSettings stores the user's email address and whether they proved they own it.
+type EmailSettings struct {
+	Address  string `json:"address"`
+	Verified bool   `json:"verified"`
+	// CodeHash is the SHA-256 of the pending verification code.
+	CodeHash    string    `json:"codeHash,omitempty"`
+	CodeExpires time.Time `json:"codeExpires,omitempty"`
+	// CodeAttempts counts wrong guesses of the pending code.
+	CodeAttempts int `json:"codeAttempts,omitempty"`
+	// Reminders sends every reminder to the address instead of Discord.
+	Reminders bool `json:"reminders,omitempty"`
+}
+
+// WebhookEndpoint is an HTTP endpoint that receives signed bookmark documents.
+type WebhookEndpoint struct {
+	URL string `json:"url"`
+	// Secret signs every request so the receiver can verify it came from the bot.
+	Secret string `json:"secret"`
+}
+
+// DMFallbackMode controls where DM bookmarks and reminders go when the user's DMs are closed.
+type DMFallbackMode string
+
+const (
+	// DMFallbackThread delivers to a private thread the bot creates for the user in the
+	// source server.
+	DMFallbackThread DMFallbackMode = "thread"
+	// DMFallbackNone drops deliveries that cannot reach the user's DMs.
+	DMFallbackNone DMFallbackMode = "none"
+)
+
+// DMFallback stores the user's fallback for closed DMs.
+type DMFallback struct {
+	Mode DMFallbackMode `json:"mode,omitempty"`
+	// Channels maps guild IDs to the channel the private thread is created in. Without an
+	// entry the thread is created in the source channel.
+	Channels map[string]string `json:"channels,omitempty"`
+	// Threads maps guild IDs to the private thread the bot created for the user.
+	Threads map[string]string `json:"threads,omitempty"`
+	// Notified is set once the user was told how to reopen their DMs.
+	Notified bool `json:"notified,omitempty"`
+}
+
+func normalizeDMFallback(fallback DMFallback) DMFallback {
+	if fallback.Mode == "" {
+		fallback.Mode = DMFallbackThread
+	}
+
+	return fallback
+}
+
+// EmojisIn returns the preferences saved for exactly scope.
+func (p UserPreferences) EmojisIn(scope EmojiScope) map[string]EmojiPreference {
+	if scope == ScopeGlobal {
+		return p.Emojis
+	}
+
+	return p.ScopedEmojis[scope]
+}
+
+// Effective returns the preferences that apply to reactions in guildID, empty for DMs: the
+// global ones, overridden by those scoped to that server or to DMs.
+func (p UserPreferences) Effective(guildID string) map[string]EmojiPreference {
+	scoped := p.ScopedEmojis[ScopeOf(guildID)]
+	effective := make(map[string]EmojiPreference, len(p.Emojis)+len(scoped))
+	for emoji, pref := range p.Emojis {
+		effective[emoji] = pref
+	}
+	for emoji, pref := range scoped {
+		effective[emoji] = pref
+	}
+
+	return effective
+}
+
+// withEmojis returns prefs with the preferences of scope replaced by emojis.
+func (p UserPreferences) withEmojis(scope EmojiScope, emojis map[string]EmojiPreference) UserPreferences {
+	if scope == ScopeGlobal {
+		p.Emojis = emojis
+		return p
+	}
+
+	scoped := make(map[EmojiScope]map[string]EmojiPreference, len(p.ScopedEmojis)+1)
+	for key, value := range p.ScopedEmojis {
+		scoped[key] = value
+	}
+	if len(emojis) == 0 {
+		delete(scoped, scope)
+	} else {
+		scoped[scope] = emojis
+	}
+	if len(scoped) == 0 {
+		scoped = nil
+	}
+	p.ScopedEmojis = scoped
+
+	return p
+}
+
+// normalizeEmojis returns a normalized copy of emojis, keyed by normalized emoji so that
+// entries saved before keys were normalized keep matching.
+func normalizeEmojis(emojis map[string]EmojiPreference) map[string]EmojiPreference {
+	normalized := make(map[string]EmojiPreference, len(emojis))
+	for emoji, pref := range emojis {
+		normalized[emojikey.Normalize(emoji)] = normalizeEmojiPreference(pref)
+	}
+
+	return normalized
+}
+
+// normalizeScopedEmojis returns a normalized copy of scoped, without empty scopes.
+func normalizeScopedEmojis(scoped map[EmojiScope]map[string]EmojiPreference) map[EmojiScope]map[string]EmojiPreference {
+	if len(scoped) == 0 {
+		return nil
+	}
+
+	normalized := make(map[EmojiScope]map[string]EmojiPreference, len(scoped))
+	for scope, emojis := range scoped {
+		if len(emojis) > 0 {
+			normalized[scope] = normalizeEmojis(emojis)
+		}
+	}
+
+	return normalized
+}
+
+// isEmpty reports whether prefs holds nothing worth persisting.
+func (p UserPreferences) isEmpty() bool {
+	fallback := p.DMFallback
+	return len(p.Emojis) == 0 && len(p.ScopedEmojis) == 0 && p.Webhook == nil && p.VaultTemplate == "" && p.Email == nil && p.FeedToken == "" && (fallback.Mode == "" || fallback.Mode == DMFallbackThread) &&
+		len(fallback.Channels) == 0 && len(fallback.Threads) == 0 && !fallback.Notified
 }
 
 // EmojiStore provides thread-safe storage for user specific emoji preferences.
@@ -90,19 +352,14 @@ func NewEmojiStore(filePath string) (*EmojiStore, error) {
 	return store, nil
 }
 
-// SetEmoji associates emoji preferences with a given user ID and emoji.
-func (s *EmojiStore) SetEmoji(userID, emoji string, pref EmojiPreference) error {
+// SetEmoji associates emoji preferences with a given user ID and emoji in scope.
+func (s *EmojiStore) SetEmoji(userID string, scope EmojiScope, emoji string, pref EmojiPreference) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	userPrefs, ok := s.prefs[userID]
+	emoji = emojikey.Normalize(emoji)
 
-	var current map[string]EmojiPreference
-	if !ok || userPrefs.Emojis == nil {
-		current = make(map[string]EmojiPreference)
-	} else {
-		current = userPrefs.Emojis
-	}
-
+	current := userPrefs.EmojisIn(scope)
 	next := make(map[string]EmojiPreference, len(current)+1)
 	for key, value := range current {
 		next[key] = normalizeEmojiPreference(value)
@@ -110,7 +367,8 @@ func (s *EmojiStore) SetEmoji(userID, emoji string, pref EmojiPreference) error
 	next[emoji] = normalizeEmojiPreference(pref)
 
 	previous := userPrefs
-	s.prefs[userID] = UserPreferences{Emojis: next}
+	userPrefs = userPrefs.withEmojis(scope, next)
+	s.prefs[userID] = userPrefs
 
 	if err := s.saveLocked(); err != nil {
 		if ok {
@@ -124,35 +382,38 @@ func (s *EmojiStore) SetEmoji(userID, emoji string, pref EmojiPreference) error
 	return nil
 }
 
-// DeleteEmoji removes an emoji preference for the given user ID. It returns true when a
-// preference was removed.
-func (s *EmojiStore) DeleteEmoji(userID, emoji string) (bool, error) {
+// DeleteEmoji removes an emoji preference in scope for the given user ID. It returns true
+// when a preference was removed.
+func (s *EmojiStore) DeleteEmoji(userID string, scope EmojiScope, emoji string) (bool, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	userPrefs, ok := s.prefs[userID]
-	if !ok || len(userPrefs.Emojis) == 0 {
+	current := userPrefs.EmojisIn(scope)
+	emoji = emojikey.Normalize(emoji)
+	if !ok || len(current) == 0 {
 		return false, nil
 	}
 
-	if _, exists := userPrefs.Emojis[emoji]; !exists {
+	if _, exists := current[emoji]; !exists {
 		return false, nil
 	}
 
 	previous := userPrefs
 
-	next := make(map[string]EmojiPreference, len(userPrefs.Emojis)-1)
-	for key, value := range userPrefs.Emojis {
+	next := make(map[string]EmojiPreference, len(current)-1)
+	for key, value := range current {
 		if key == emoji {
 			continue
 		}
 		next[key] = value
 	}
 
-	if len(next) == 0 {
+	userPrefs = userPrefs.withEmojis(scope, next)
+	if userPrefs.isEmpty() {
 		delete(s.prefs, userID)
 	} else {
-		s.prefs[userID] = UserPreferences{Emojis: next}
+		s.prefs[userID] = userPrefs
 	}
 
 	if err := s.saveLocked(); err != nil {
@@ -173,21 +434,252 @@ func (s *EmojiStore) Get(userID string) (UserPreferences, bool) {
 	}
 
 	// Ensure the map is non-nil for consumers.
-	if prefs.Emojis == nil {
-		prefs.Emojis = make(map[string]EmojiPreference)
+	prefs.Emojis = normalizeEmojis(prefs.Emojis)
+	prefs.ScopedEmojis = normalizeScopedEmojis(prefs.ScopedEmojis)
+	prefs.DMFallback = normalizeDMFallback(prefs.DMFallback)
+
+	return prefs, true
+}
+
+// DMFallback returns the user's fallback for closed DMs, defaulting to a private thread.
+func (s *EmojiStore) DMFallback(userID string) DMFallback {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return normalizeDMFallback(s.prefs[userID].DMFallback)
+}
+
+// UpdateDMFallback applies fn to the user's fallback settings and persists the result.
+func (s *EmojiStore) UpdateDMFallback(userID string, fn func(*DMFallback)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, existed := s.prefs[userID]
+	next := previous
+	fallback := normalizeDMFallback(previous.DMFallback)
+	fallback.Channels = copyStringMap(fallback.Channels)
+	fallback.Threads = copyStringMap(fallback.Threads)
+	fn(&fallback)
+	next.DMFallback = fallback
+
+	if next.isEmpty() {
+		delete(s.prefs, userID)
 	} else {
-		normalized := make(map[string]EmojiPreference, len(prefs.Emojis))
-		for emoji, pref := range prefs.Emojis {
-			normalized[emoji] = normalizeEmojiPreference(pref)
+		s.prefs[userID] = next
+	}
+
+	if err := s.saveLocked(); err != nil {
+		if existed {
+			s.prefs[userID] = previous
+		} else {
+			delete(s.prefs, userID)
 		}
-		prefs.Emojis = normalized
+		return err
 	}
 
-	return prefs, true
+	return nil
+}
+
+// Webhook returns the user's webhook endpoint, if one is configured.
+func (s *EmojiStore) Webhook(userID string) (WebhookEndpoint, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	endpoint := s.prefs[userID].Webhook
+	if endpoint == nil {
+		return WebhookEndpoint{}, false
+	}
+
+	return *endpoint, true
+}
+
+// SetWebhook stores the user's webhook endpoint. A nil endpoint removes it.
+func (s *EmojiStore) SetWebhook(userID string, endpoint *WebhookEndpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, existed := s.prefs[userID]
+	next := previous
+	if endpoint != nil {
+		copied := *endpoint
+		endpoint = &copied
+	}
+	next.Webhook = endpoint
+
+	if next.isEmpty() {
+		delete(s.prefs, userID)
+	} else {
+		s.prefs[userID] = next
+	}
+
+	if err := s.saveLocked(); err != nil {
+		if existed {
+			s.prefs[userID] = previous
+		} else {
+			delete(s.prefs, userID)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Email returns the user's email settings, if an address was added.
+func (s *EmojiStore) Email(userID string) (EmailSettings, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	settings := s.prefs[userID].Email
+	if settings == nil {
+		return EmailSettings{}, false
+	}
+
+	return *settings, true
+}
+
+// VerifiedEmail returns the user's address once it has been verified.
+func (s *EmojiStore) VerifiedEmail(userID string) (string, bool) {
+	settings, ok := s.Email(userID)
+	if !ok || !settings.Verified {
+		return "", false
+	}
+
+	return settings.Address, true
+}
+
+// UpdateEmail applies fn to the user's email settings and persists the result. fn receives nil
+// when no address was added yet and may return nil to remove the settings.
+func (s *EmojiStore) UpdateEmail(userID string, fn func(*EmailSettings) *EmailSettings) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, existed := s.prefs[userID]
+	next := previous
+	var current *EmailSettings
+	if previous.Email != nil {
+		copied := *previous.Email
+		current = &copied
+	}
+	next.Email = fn(current)
+
+	if next.isEmpty() {
+		delete(s.prefs, userID)
+	} else {
+		s.prefs[userID] = next
+	}
+
+	if err := s.saveLocked(); err != nil {
+		if existed {
+			s.prefs[userID] = previous
+		} else {
+			delete(s.prefs, userID)
+		}
+		return err
+	}
+
+	return nil
 }
 
-// GetEmoji retrieves a specific emoji preference for the given user.
-func (s *EmojiStore) GetEmoji(userID, emoji string) (EmojiPreference, bool) {
+// VaultTemplate returns the user's naming template for vault notes, if one is set.
+func (s *EmojiStore) VaultTemplate(userID string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.prefs[userID].VaultTemplate
+}
+
+// SetVaultTemplate stores the user's naming template for vault notes. An empty template
+// restores the default.
+func (s *EmojiStore) SetVaultTemplate(userID, template string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, existed := s.prefs[userID]
+	next := previous
+	next.VaultTemplate = template
+
+	if next.isEmpty() {
+		delete(s.prefs, userID)
+	} else {
+		s.prefs[userID] = next
+	}
+
+	if err := s.saveLocked(); err != nil {
+		if existed {
+			s.prefs[userID] = previous
+		} else {
+			delete(s.prefs, userID)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// FeedToken returns the token of the user's bookmark feed, if one was created.
+func (s *EmojiStore) FeedToken(userID string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.prefs[userID].FeedToken
+}
+
+// SetFeedToken replaces the user's feed token. An empty token turns the feed off.
+func (s *EmojiStore) SetFeedToken(userID, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, existed := s.prefs[userID]
+	next := previous
+	next.FeedToken = token
+
+	if next.isEmpty() {
+		delete(s.prefs, userID)
+	} else {
+		s.prefs[userID] = next
+	}
+
+	if err := s.saveLocked(); err != nil {
+		if existed {
+			s.prefs[userID] = previous
+		} else {
+			delete(s.prefs, userID)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// UserByFeedToken returns the user whose feed token is token.
+func (s *EmojiStore) UserByFeedToken(token string) (string, bool) {
+	if token == "" {
+		return "", false
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for userID, prefs := range s.prefs {
+		if subtle.ConstantTimeCompare([]byte(prefs.FeedToken), []byte(token)) == 1 {
+			return userID, true
+		}
+	}
+
+	return "", false
+}
+
+func copyStringMap(source map[string]string) map[string]string {
+	copied := make(map[string]string, len(source))
+	for key, value := range source {
+		copied[key] = value
+	}
+
+	return copied
+}
+
+// GetEmoji retrieves the emoji preference the given user saved in exactly scope.
+func (s *EmojiStore) GetEmoji(userID string, scope EmojiScope, emoji string) (EmojiPreference, bool) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -196,7 +688,7 @@ func (s *EmojiStore) GetEmoji(userID, emoji string) (EmojiPreference, bool) {
 		return EmojiPreference{}, false
 	}
 
-	pref, ok := prefs.Emojis[emoji]
+	pref, ok := prefs.EmojisIn(scope)[emojikey.Normalize(emoji)]
 	if !ok {
 		return EmojiPreference{}, false
 	}
@@ -220,16 +712,10 @@ func (s *EmojiStore) load() error {
 		return err
 	}
 
+	// Files written before scopes existed only have Emojis, which load as global preferences.
 	for userID, prefs := range persisted {
-		if prefs.Emojis == nil {
-			prefs.Emojis = make(map[string]EmojiPreference)
-		}
-
-		normalized := make(map[string]EmojiPreference, len(prefs.Emojis))
-		for emoji, pref := range prefs.Emojis {
-			normalized[emoji] = normalizeEmojiPreference(pref)
-		}
-		prefs.Emojis = normalized
+		prefs.Emojis = normalizeEmojis(prefs.Emojis)
+		prefs.ScopedEmojis = normalizeScopedEmojis(prefs.ScopedEmojis)
 		s.prefs[userID] = prefs
 	}
 
@@ -243,11 +729,7 @@ func (s *EmojiStore) saveLocked() error {
 
 	toPersist := make(map[string]UserPreferences, len(s.prefs))
 	for userID, prefs := range s.prefs {
-		if len(prefs.Emojis) == 0 {
-			continue
-		}
-
-		if prefs.Emojis == nil {
+		if prefs.isEmpty() {
 			continue
 		}
 
@@ -0,0 +1,152 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEmojiChangesKeepDMFallback(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prefs.json")
+
+	emojis, err := NewEmojiStore(path)
+	if err != nil {
+		t.Fatalf("NewEmojiStore returned error: %v", err)
+	}
+
+	if err := emojis.UpdateDMFallback("user", func(fallback *DMFallback) {
+		fallback.Mode = DMFallbackNone
+		fallback.Channels["guild"] = "channel"
+	}); err != nil {
+		t.Fatalf("UpdateDMFallback returned error: %v", err)
+	}
+
+	if err := emojis.SetEmoji("user", ScopeGlobal, "🔖", EmojiPreference{Mode: ModeBalanced}); err != nil {
+		t.Fatalf("SetEmoji returned error: %v", err)
+	}
+	if _, err := emojis.DeleteEmoji("user", ScopeGlobal, "🔖"); err != nil {
+		t.Fatalf("DeleteEmoji returned error: %v", err)
+	}
+
+	reloaded, err := NewEmojiStore(path)
+	if err != nil {
+		t.Fatalf("reloading store returned error: %v", err)
+	}
+
+	fallback := reloaded.DMFallback("user")
+	if fallback.Mode != DMFallbackNone || fallback.Channels["guild"] != "channel" {
+		t.Fatalf("expected fallback to survive emoji changes, got %+v", fallback)
+	}
+}
+
+func TestDMFallbackDefaultsToThread(t *testing.T) {
+	emojis, err := NewEmojiStore("")
+	if err != nil {
+		t.Fatalf("NewEmojiStore returned error: %v", err)
+	}
+
+	if mode := emojis.DMFallback("unknown").Mode; mode != DMFallbackThread {
+		t.Fatalf("expected thread fallback by default, got %q", mode)
+	}
+}
+
+func TestAllTargetsListsPrimaryFirst(t *testing.T) {
+	emojis, err := NewEmojiStore("")
+	if err != nil {
+		t.Fatalf("NewEmojiStore returned error: %v", err)
+	}
+
+	if err := emojis.SetEmoji("user", ScopeGlobal, "📣", EmojiPreference{
+		Mode: ModeLightweight,
+		Targets: []DestinationTarget{
+			{Destination: DestinationChannel, ChannelID: "team", Mode: ModeComplete},
+			{ChannelID: "ignored", Mode: ModeBalanced},
+		},
+	}); err != nil {
+		t.Fatalf("SetEmoji returned error: %v", err)
+	}
+
+	pref, _ := emojis.GetEmoji("user", ScopeGlobal, "📣")
+	targets := pref.AllTargets()
+	if len(targets) != 3 {
+		t.Fatalf("expected three targets, got %+v", targets)
+	}
+	if targets[0].Destination != DestinationDM || targets[0].Mode != ModeLightweight {
+		t.Fatalf("expected the primary DM target first, got %+v", targets[0])
+	}
+	if targets[1].ChannelID != "team" || targets[1].Mode != ModeComplete {
+		t.Fatalf("expected the channel target second, got %+v", targets[1])
+	}
+	if targets[2].Destination != DestinationDM || targets[2].ChannelID != "" {
+		t.Fatalf("expected DM targets to drop their channel, got %+v", targets[2])
+	}
+}
+
+func TestScopedEmojisOverrideGlobal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prefs.json")
+	legacy := `{"user": {"emojis": {"🔖": {"mode": "balanced"}}}}`
+	if err := os.WriteFile(path, []byte(legacy), 0o600); err != nil {
+		t.Fatalf("writing legacy preferences: %v", err)
+	}
+
+	emojis, err := NewEmojiStore(path)
+	if err != nil {
+		t.Fatalf("NewEmojiStore returned error: %v", err)
+	}
+	if pref, ok := emojis.GetEmoji("user", ScopeGlobal, "🔖"); !ok || pref.Mode != ModeBalanced {
+		t.Fatalf("expected legacy preferences to load as global, got %+v (found %v)", pref, ok)
+	}
+
+	if err := emojis.SetEmoji("user", GuildScope("work"), "🔖", EmojiPreference{Mode: ModeComplete}); err != nil {
+		t.Fatalf("SetEmoji returned error: %v", err)
+	}
+	if err := emojis.SetEmoji("user", ScopeDM, "🔖", EmojiPreference{Mode: ModeLightweight}); err != nil {
+		t.Fatalf("SetEmoji returned error: %v", err)
+	}
+
+	reloaded, err := NewEmojiStore(path)
+	if err != nil {
+		t.Fatalf("reloading store returned error: %v", err)
+	}
+	prefs, _ := reloaded.Get("user")
+
+	for guildID, want := range map[string]BookmarkMode{"work": ModeComplete, "hobby": ModeBalanced, "": ModeLightweight} {
+		if got := prefs.Effective(guildID)["🔖"].Mode; got != want {
+			t.Fatalf("expected %s mode in guild %q, got %s", want, guildID, got)
+		}
+	}
+
+	if removed, err := reloaded.DeleteEmoji("user", GuildScope("work"), "🔖"); err != nil || !removed {
+		t.Fatalf("expected the work preference to be removed, got %v, %v", removed, err)
+	}
+	prefs, _ = reloaded.Get("user")
+	if got := prefs.Effective("work")["🔖"].Mode; got != ModeBalanced {
+		t.Fatalf("expected the global preference after removing the server one, got %s", got)
+	}
+	if _, ok := prefs.ScopedEmojis[GuildScope("work")]; ok {
+		t.Fatalf("expected empty scopes to be dropped, got %+v", prefs.ScopedEmojis)
+	}
+}
+
+func TestEmojiKeysIgnoreVariationSelectors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prefs.json")
+	legacy := `{"user": {"emojis": {"❤️": {"mode": "balanced"}, "a:party:456": {"mode": "complete"}}}}`
+	if err := os.WriteFile(path, []byte(legacy), 0o600); err != nil {
+		t.Fatalf("writing legacy preferences: %v", err)
+	}
+
+	emojis, err := NewEmojiStore(path)
+	if err != nil {
+		t.Fatalf("NewEmojiStore returned error: %v", err)
+	}
+	if pref, ok := emojis.GetEmoji("user", ScopeGlobal, "❤"); !ok || pref.Mode != ModeBalanced {
+		t.Fatalf("expected ❤️ to load as ❤, got %+v (found %v)", pref, ok)
+	}
+	if pref, ok := emojis.GetEmoji("user", ScopeGlobal, "party:456"); !ok || pref.Mode != ModeComplete {
+		t.Fatalf("expected the animated emoji to load as party:456, got %+v (found %v)", pref, ok)
+	}
+
+	if removed, err := emojis.DeleteEmoji("user", ScopeGlobal, "❤️"); err != nil || !removed {
+		t.Fatalf("expected ❤️ to remove the ❤ preference, got %v, %v", removed, err)
+	}
+}
@@ -0,0 +1,163 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// StarboardPost records the showcase copy of a message that reached its server's starboard
+// threshold.
+type StarboardPost struct {
+	GuildID         string `json:"guildId"`
+	SourceChannelID string `json:"sourceChannelId"`
+	SourceMessageID string `json:"sourceMessageId"`
+	ChannelID       string `json:"channelId"`
+	MessageID       string `json:"messageId"`
+	// Count is the number of distinct members shown on the showcase post.
+	Count int `json:"count"`
+}
+
+// StarboardStore provides thread-safe storage for showcase posts, keyed by source message.
+type StarboardStore struct {
+	mu       sync.RWMutex
+	posts    map[string]StarboardPost
+	filePath string
+}
+
+// NewStarboardStore initializes a StarboardStore and loads any persisted data from filePath.
+//
+// If filePath is empty, the store behaves as an in-memory only store.
+func NewStarboardStore(filePath string) (*StarboardStore, error) {
+	store := &StarboardStore{
+		posts:    make(map[string]StarboardPost),
+		filePath: filePath,
+	}
+
+	if filePath == "" {
+		return store, nil
+	}
+
+	if err := store.load(); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return store, nil
+		}
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// Get retrieves the showcase post of the source message ID.
+func (s *StarboardStore) Get(sourceMessageID string) (StarboardPost, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	post, ok := s.posts[sourceMessageID]
+	return post, ok
+}
+
+// Put stores post, replacing any earlier post for the same source message.
+func (s *StarboardStore) Put(post StarboardPost) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, existed := s.posts[post.SourceMessageID]
+	s.posts[post.SourceMessageID] = post
+
+	if err := s.saveLocked(); err != nil {
+		if existed {
+			s.posts[post.SourceMessageID] = previous
+		} else {
+			delete(s.posts, post.SourceMessageID)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Delete removes the showcase post of the source message ID. It returns true when a post was
+// removed.
+func (s *StarboardStore) Delete(sourceMessageID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, ok := s.posts[sourceMessageID]
+	if !ok {
+		return false, nil
+	}
+	delete(s.posts, sourceMessageID)
+
+	if err := s.saveLocked(); err != nil {
+		s.posts[sourceMessageID] = previous
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (s *StarboardStore) load() error {
+	file, err := os.Open(s.filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var persisted map[string]StarboardPost
+	decoder := json.NewDecoder(file)
+	if err := decoder.Decode(&persisted); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return err
+	}
+
+	for sourceMessageID, post := range persisted {
+		post.SourceMessageID = sourceMessageID
+		s.posts[sourceMessageID] = post
+	}
+
+	return nil
+}
+
+func (s *StarboardStore) saveLocked() error {
+	if s.filePath == "" {
+		return nil
+	}
+
+	dir := filepath.Dir(s.filePath)
+	if dir != "." && dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+
+	tempFile, err := os.CreateTemp(dir, "starboard-*.json")
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(tempFile)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(s.posts); err != nil {
+		tempFile.Close()
+		os.Remove(tempFile.Name())
+		return err
+	}
+
+	if err := tempFile.Close(); err != nil {
+		os.Remove(tempFile.Name())
+		return err
+	}
+
+	if err := os.Rename(tempFile.Name(), s.filePath); err != nil {
+		os.Remove(tempFile.Name())
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestStarboardPostsPersist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "starboard.json")
+
+	posts, err := NewStarboardStore(path)
+	if err != nil {
+		t.Fatalf("NewStarboardStore returned error: %v", err)
+	}
+
+	for _, post := range []StarboardPost{
+		{GuildID: "guild", SourceChannelID: "general", SourceMessageID: "kept", ChannelID: "hall", MessageID: "showcase-1", Count: 3},
+		{GuildID: "guild", SourceChannelID: "general", SourceMessageID: "dropped", ChannelID: "hall", MessageID: "showcase-2", Count: 3},
+	} {
+		if err := posts.Put(post); err != nil {
+			t.Fatalf("Put returned error: %v", err)
+		}
+	}
+	if removed, err := posts.Delete("dropped"); err != nil || !removed {
+		t.Fatalf("expected Delete to remove the post, got %v, %v", removed, err)
+	}
+
+	reloaded, err := NewStarboardStore(path)
+	if err != nil {
+		t.Fatalf("reloading store returned error: %v", err)
+	}
+
+	if post, ok := reloaded.Get("kept"); !ok || post.MessageID != "showcase-1" || post.Count != 3 {
+		t.Fatalf("expected the kept post to survive a reload, got %+v (found %v)", post, ok)
+	}
+	if _, ok := reloaded.Get("dropped"); ok {
+		t.Fatalf("expected the deleted post to stay deleted")
+	}
+}
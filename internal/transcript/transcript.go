@@ -0,0 +1,153 @@
+package transcript
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const timestampLayout = "2006-01-02 15:04"
+
+// Transcript describes a captured conversation together with the context needed to render it.
+type Transcript struct {
+	Title      string
+	SourceURL  string
+	CapturedAt time.Time
+	Messages   []*discordgo.Message
+	// Truncated marks a capture that hit its message limit, so later messages are missing.
+	Truncated bool
+}
+
+// New builds a transcript and orders the messages chronologically.
+func New(title, sourceURL string, messages []*discordgo.Message) *Transcript {
+	ordered := make([]*discordgo.Message, 0, len(messages))
+	for _, msg := range messages {
+		if msg == nil {
+			continue
+		}
+		ordered = append(ordered, msg)
+	}
+
+	sort.SliceStable(ordered, func(a, b int) bool {
+		return ordered[a].Timestamp.Before(ordered[b].Timestamp)
+	})
+
+	return &Transcript{
+		Title:      title,
+		SourceURL:  sourceURL,
+		CapturedAt: time.Now(),
+		Messages:   ordered,
+	}
+}
+
+// Markdown renders the transcript as a Markdown document.
+func (t *Transcript) Markdown() string {
+	var builder strings.Builder
+
+	builder.WriteString(fmt.Sprintf("# %s\n\n", t.Title))
+	if t.SourceURL != "" {
+		builder.WriteString(fmt.Sprintf("- Source: %s\n", t.SourceURL))
+	}
+	builder.WriteString(fmt.Sprintf("- Captured: %s\n", t.CapturedAt.Format(timestampLayout)))
+	builder.WriteString(fmt.Sprintf("- Messages: %d\n", len(t.Messages)))
+	if t.Truncated {
+		builder.WriteString(fmt.Sprintf("- Truncated after %d messages; later messages were not captured\n", len(t.Messages)))
+	}
+
+	for _, msg := range t.Messages {
+		builder.WriteString("\n---\n\n")
+		builder.WriteString(fmt.Sprintf("**%s** — %s\n\n", authorName(msg), msg.Timestamp.Format(timestampLayout)))
+
+		if content := strings.TrimSpace(msg.Content); content != "" {
+			builder.WriteString(content + "\n")
+		}
+
+		if len(msg.Attachments) > 0 {
+			builder.WriteString("\nAttachments:\n")
+			for _, attachment := range msg.Attachments {
+				if attachment == nil {
+					continue
+				}
+				builder.WriteString(fmt.Sprintf("- [%s](%s)\n", attachmentName(attachment), attachment.URL))
+			}
+		}
+	}
+
+	return builder.String()
+}
+
+// HTML renders the transcript as a self-contained HTML page with inline styles.
+func (t *Transcript) HTML() string {
+	var builder strings.Builder
+
+	builder.WriteString("<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"utf-8\">\n")
+	builder.WriteString(fmt.Sprintf("<title>%s</title>\n", html.EscapeString(t.Title)))
+	builder.WriteString("<style>\n" +
+		"body{font-family:-apple-system,Segoe UI,Helvetica,Arial,sans-serif;background:#313338;color:#dbdee1;margin:0;padding:24px;}\n" +
+		"h1{font-size:20px;margin:0 0 8px;}\n" +
+		".meta{color:#949ba4;font-size:13px;margin-bottom:24px;}\n" +
+		".meta a,.attachments a{color:#00a8fc;}\n" +
+		".message{padding:8px 0;border-top:1px solid #3f4147;}\n" +
+		".author{font-weight:600;color:#f2f3f5;}\n" +
+		".time{color:#949ba4;font-size:12px;margin-left:8px;}\n" +
+		".content{white-space:pre-wrap;margin-top:4px;}\n" +
+		".attachments{margin:4px 0 0;padding-left:20px;font-size:13px;}\n" +
+		"</style>\n</head>\n<body>\n")
+
+	builder.WriteString(fmt.Sprintf("<h1>%s</h1>\n<div class=\"meta\">", html.EscapeString(t.Title)))
+	if t.SourceURL != "" {
+		builder.WriteString(fmt.Sprintf("<a href=\"%s\">Open source</a> · ", html.EscapeString(t.SourceURL)))
+	}
+	builder.WriteString(fmt.Sprintf("Captured %s · %d messages", t.CapturedAt.Format(timestampLayout), len(t.Messages)))
+	if t.Truncated {
+		builder.WriteString(fmt.Sprintf(" · truncated after %d messages; later messages were not captured", len(t.Messages)))
+	}
+	builder.WriteString("</div>\n")
+
+	for _, msg := range t.Messages {
+		builder.WriteString("<div class=\"message\">\n")
+		builder.WriteString(fmt.Sprintf("<span class=\"author\">%s</span><span class=\"time\">%s</span>\n",
+			html.EscapeString(authorName(msg)), msg.Timestamp.Format(timestampLayout)))
+
+		if content := strings.TrimSpace(msg.Content); content != "" {
+			builder.WriteString(fmt.Sprintf("<div class=\"content\">%s</div>\n", html.EscapeString(content)))
+		}
+
+		if len(msg.Attachments) > 0 {
+			builder.WriteString("<ul class=\"attachments\">\n")
+			for _, attachment := range msg.Attachments {
+				if attachment == nil {
+					continue
+				}
+				builder.WriteString(fmt.Sprintf("<li><a href=\"%s\">%s</a></li>\n",
+					html.EscapeString(attachment.URL), html.EscapeString(attachmentName(attachment))))
+			}
+			builder.WriteString("</ul>\n")
+		}
+
+		builder.WriteString("</div>\n")
+	}
+
+	builder.WriteString("</body>\n</html>\n")
+	return builder.String()
+}
+
+func authorName(msg *discordgo.Message) string {
+	if msg.Author == nil {
+		return "Unknown author"
+	}
+
+	return msg.Author.String()
+}
+
+func attachmentName(attachment *discordgo.MessageAttachment) string {
+	if attachment.Filename != "" {
+		return attachment.Filename
+	}
+
+	return attachment.URL
+}
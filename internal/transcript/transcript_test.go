@@ -0,0 +1,82 @@
+package transcript
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func sampleMessages() []*discordgo.Message {
+	base := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	return []*discordgo.Message{
+		{
+			ID:        "2",
+			Content:   "second <b>reply</b>",
+			Timestamp: base.Add(time.Minute),
+			Author:    &discordgo.User{Username: "bob", Discriminator: "0002"},
+			Attachments: []*discordgo.MessageAttachment{
+				{Filename: "notes.pdf", URL: "https://cdn.example/notes.pdf"},
+			},
+		},
+		{
+			ID:        "1",
+			Content:   "first message",
+			Timestamp: base,
+			Author:    &discordgo.User{Username: "alice", Discriminator: "0001"},
+		},
+	}
+}
+
+func TestNewOrdersMessagesChronologically(t *testing.T) {
+	capture := New("Thread", "", sampleMessages())
+	if len(capture.Messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(capture.Messages))
+	}
+	if capture.Messages[0].ID != "1" {
+		t.Fatalf("expected the oldest message first, got %s", capture.Messages[0].ID)
+	}
+}
+
+func TestMarkdownIncludesAuthorsAndAttachments(t *testing.T) {
+	rendered := New("Thread", "https://discord.com/channels/1/2", sampleMessages()).Markdown()
+
+	for _, want := range []string{"# Thread", "**alice#0001**", "**bob#0002**", "[notes.pdf](https://cdn.example/notes.pdf)", "2024-05-01 09:01"} {
+		if !strings.Contains(rendered, want) {
+			t.Fatalf("expected markdown to contain %q:\n%s", want, rendered)
+		}
+	}
+	if strings.Index(rendered, "alice") > strings.Index(rendered, "bob") {
+		t.Fatalf("expected alice to appear before bob")
+	}
+}
+
+func TestTruncatedTranscriptSaysSo(t *testing.T) {
+	capture := New("Thread", "", sampleMessages())
+	if strings.Contains(capture.Markdown(), "Truncated") {
+		t.Fatalf("expected no truncation note on a complete capture")
+	}
+
+	capture.Truncated = true
+	if !strings.Contains(capture.Markdown(), "- Truncated after 2 messages") {
+		t.Fatalf("expected a truncation note in markdown:\n%s", capture.Markdown())
+	}
+	if !strings.Contains(capture.HTML(), "truncated after 2 messages") {
+		t.Fatalf("expected a truncation note in HTML")
+	}
+}
+
+func TestHTMLEscapesContent(t *testing.T) {
+	rendered := New("Thread", "", sampleMessages()).HTML()
+
+	if strings.Contains(rendered, "<b>reply</b>") {
+		t.Fatalf("expected message content to be escaped")
+	}
+	if !strings.Contains(rendered, "&lt;b&gt;reply&lt;/b&gt;") {
+		t.Fatalf("expected escaped content in output")
+	}
+	if !strings.Contains(rendered, `href="https://cdn.example/notes.pdf"`) {
+		t.Fatalf("expected attachment link in output")
+	}
+}
@@ -0,0 +1,490 @@
+// Package vault writes bookmarks as Markdown notes with YAML front matter into per-user
+// directories, for note-taking apps that sync a folder of Markdown files.
+package vault
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultTemplate names notes by save date and title.
+	DefaultTemplate = "{date} {title}"
+	// ArchiveDir is the folder, inside each user directory, removed notes are moved to.
+	ArchiveDir = "archive"
+	// attachmentsDir is the folder next to each note that holds its downloaded attachments.
+	attachmentsDir = "attachments"
+
+	maxSegmentLength = 80
+)
+
+// Status values written to the status front matter field.
+const (
+	StatusOpen     = "open"
+	StatusDone     = "done"
+	StatusArchived = "archived"
+)
+
+// Note is one bookmark to write into the vault.
+type Note struct {
+	ID          string
+	Title       string
+	SourceURL   string
+	Author      string
+	AuthorID    string
+	Channel     string
+	SavedAt     time.Time
+	Emoji       string
+	Mode        string
+	Tags        []string
+	Reminder    string
+	Content     string
+	Attachments []Attachment
+	// Extra holds additional Markdown sections, such as embeds or transcripts.
+	Extra []Section
+}
+
+// Attachment is a file from the source message. Data is empty when the download failed, in
+// which case the note links to URL instead.
+type Attachment struct {
+	Name string
+	URL  string
+	Data []byte
+}
+
+// Section is a titled block of Markdown appended to the note body.
+type Section struct {
+	Title string
+	Body  string
+}
+
+// Vault manages the notes under a root directory.
+type Vault struct {
+	root     string
+	template string
+	mu       sync.Mutex
+}
+
+// New returns a Vault rooted at root. Notes saved without a template of their own are named
+// from template, or DefaultTemplate when it is empty.
+func New(root, template string) *Vault {
+	if strings.TrimSpace(template) == "" {
+		template = DefaultTemplate
+	}
+
+	return &Vault{root: root, template: template}
+}
+
+// Enabled reports whether the vault has a root directory to write to.
+func (v *Vault) Enabled() bool {
+	return v != nil && v.root != ""
+}
+
+// Save writes note into userID's directory, naming it from template (or the vault default)
+// and returns the note's path relative to that directory. Attachments with data are written
+// next to the note.
+func (v *Vault) Save(userID, template string, note Note) (string, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	userDir, err := v.userDir(userID)
+	if err != nil {
+		return "", err
+	}
+	relPath := uniquePath(userDir, v.Name(template, note)+".md")
+	notePath := filepath.Join(userDir, filepath.FromSlash(relPath))
+	attachmentDir := filepath.Join(filepath.Dir(notePath), attachmentsDir, note.ID)
+
+	links := make([]string, len(note.Attachments))
+	usedNames := make(map[string]bool)
+	for idx, attachment := range note.Attachments {
+		if len(attachment.Data) == 0 {
+			links[idx] = attachment.URL
+			continue
+		}
+
+		name := sanitizeSegment(attachment.Name)
+		if name == "" {
+			name = fmt.Sprintf("attachment-%d", idx+1)
+		}
+		for base, n := name, 2; usedNames[name]; n++ {
+			name = fmt.Sprintf("%d-%s", n, base)
+		}
+		usedNames[name] = true
+
+		if err := os.MkdirAll(attachmentDir, 0o755); err != nil {
+			return "", fmt.Errorf("create attachment directory: %w", err)
+		}
+		if err := os.WriteFile(filepath.Join(attachmentDir, name), attachment.Data, 0o644); err != nil {
+			return "", fmt.Errorf("write attachment: %w", err)
+		}
+		links[idx] = path.Join(attachmentsDir, note.ID, name)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(notePath), 0o755); err != nil {
+		return "", fmt.Errorf("create note directory: %w", err)
+	}
+	if err := writeFileAtomic(notePath, renderNote(note, links)); err != nil {
+		return "", err
+	}
+
+	return relPath, nil
+}
+
+// Complete marks the note at relPath as done.
+func (v *Vault) Complete(userID, relPath string, at time.Time) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	notePath, err := v.notePath(userID, relPath)
+	if err != nil {
+		return err
+	}
+
+	return updateFrontMatter(notePath, map[string]string{
+		"status":    StatusDone,
+		"completed": at.UTC().Format(time.RFC3339),
+	})
+}
+
+// Archive moves the note at relPath, with its attachments, into the archive folder and returns
+// its new relative path.
+func (v *Vault) Archive(userID, relPath, noteID string, at time.Time) (string, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	notePath, err := v.notePath(userID, relPath)
+	if err != nil {
+		return "", err
+	}
+
+	if err := updateFrontMatter(notePath, map[string]string{
+		"status":   StatusArchived,
+		"archived": at.UTC().Format(time.RFC3339),
+	}); err != nil {
+		return "", err
+	}
+
+	userDir, _ := v.userDir(userID)
+	archivedRel := uniquePath(userDir, path.Join(ArchiveDir, relPath))
+	archivedPath := filepath.Join(userDir, filepath.FromSlash(archivedRel))
+	if err := os.MkdirAll(filepath.Dir(archivedPath), 0o755); err != nil {
+		return "", fmt.Errorf("create archive directory: %w", err)
+	}
+	if err := os.Rename(notePath, archivedPath); err != nil {
+		return "", fmt.Errorf("archive note: %w", err)
+	}
+
+	// Attachment links are relative to the note, so the folder moves with it.
+	attachmentDir := filepath.Join(filepath.Dir(notePath), attachmentsDir, noteID)
+	if _, err := os.Stat(attachmentDir); err == nil {
+		target := filepath.Join(filepath.Dir(archivedPath), attachmentsDir, noteID)
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return "", fmt.Errorf("create archive directory: %w", err)
+		}
+		if err := os.Rename(attachmentDir, target); err != nil {
+			return "", fmt.Errorf("archive attachments: %w", err)
+		}
+	}
+
+	return archivedRel, nil
+}
+
+// Name renders the note name for template, falling back to the vault's default template.
+func (v *Vault) Name(template string, note Note) string {
+	if strings.TrimSpace(template) == "" {
+		template = v.template
+	}
+
+	return RenderName(template, note)
+}
+
+func (v *Vault) userDir(userID string) (string, error) {
+	if v.root == "" {
+		return "", errors.New("vault is not configured")
+	}
+
+	name := sanitizeSegment(userID)
+	if name == "" {
+		return "", fmt.Errorf("invalid user id %q", userID)
+	}
+
+	return filepath.Join(v.root, name), nil
+}
+
+// notePath resolves relPath inside userID's directory, refusing paths that escape it.
+func (v *Vault) notePath(userID, relPath string) (string, error) {
+	userDir, err := v.userDir(userID)
+	if err != nil {
+		return "", err
+	}
+
+	cleaned := path.Clean("/" + filepath.ToSlash(relPath))
+	if cleaned == "/" || !strings.HasSuffix(cleaned, ".md") {
+		return "", fmt.Errorf("invalid note path %q", relPath)
+	}
+
+	return filepath.Join(userDir, filepath.FromSlash(strings.TrimPrefix(cleaned, "/"))), nil
+}
+
+// RenderName expands template for note. Placeholders are {date}, {time}, {title}, {channel},
+// {author}, {emoji} and {id}; "/" separates folders. Each folder and the file name are
+// sanitized so they are valid on every platform and cannot escape the user directory.
+func RenderName(template string, note Note) string {
+	if strings.TrimSpace(template) == "" {
+		template = DefaultTemplate
+	}
+
+	saved := note.SavedAt
+	if saved.IsZero() {
+		saved = time.Now()
+	}
+
+	replacer := strings.NewReplacer(
+		"{date}", saved.Format("2006-01-02"),
+		"{time}", saved.Format("1504"),
+		"{title}", note.Title,
+		"{channel}", note.Channel,
+		"{author}", note.Author,
+		"{emoji}", note.Emoji,
+		"{id}", note.ID,
+	)
+
+	var segments []string
+	for _, raw := range strings.Split(template, "/") {
+		segment := sanitizeSegment(replacer.Replace(raw))
+		if segment == "" || segment == "." || segment == ".." {
+			continue
+		}
+		// Keep new notes out of the archive folder.
+		if len(segments) == 0 && strings.EqualFold(segment, ArchiveDir) {
+			continue
+		}
+		segments = append(segments, segment)
+	}
+
+	if len(segments) == 0 {
+		return sanitizeSegment(note.ID)
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// sanitizeSegment makes value safe as a single file or folder name.
+func sanitizeSegment(value string) string {
+	var builder strings.Builder
+	for _, r := range value {
+		switch {
+		case r == '/' || r == '\\' || r == ':' || r == '*' || r == '?' || r == '"' || r == '<' || r == '>' || r == '|':
+			builder.WriteRune('-')
+		case unicode.IsControl(r):
+			builder.WriteRune(' ')
+		default:
+			builder.WriteRune(r)
+		}
+	}
+
+	cleaned := strings.Join(strings.Fields(builder.String()), " ")
+	cleaned = strings.Trim(cleaned, ". ")
+	if utf8.RuneCountInString(cleaned) > maxSegmentLength {
+		cleaned = strings.TrimSpace(string([]rune(cleaned)[:maxSegmentLength]))
+	}
+
+	return cleaned
+}
+
+// uniquePath returns relPath, or relPath with a " (n)" suffix when a file already exists there.
+func uniquePath(userDir, relPath string) string {
+	ext := path.Ext(relPath)
+	base := strings.TrimSuffix(relPath, ext)
+	candidate := relPath
+	for n := 2; ; n++ {
+		if _, err := os.Stat(filepath.Join(userDir, filepath.FromSlash(candidate))); errors.Is(err, os.ErrNotExist) {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s (%d)%s", base, n, ext)
+	}
+}
+
+func renderNote(note Note, attachmentLinks []string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	writeField(&buf, "id", quote(note.ID))
+	writeField(&buf, "title", quote(note.Title))
+	writeField(&buf, "source", quote(note.SourceURL))
+	writeField(&buf, "author", quote(note.Author))
+	if note.AuthorID != "" {
+		writeField(&buf, "author_id", quote(note.AuthorID))
+	}
+	writeField(&buf, "channel", quote(note.Channel))
+	writeField(&buf, "saved", note.SavedAt.UTC().Format(time.RFC3339))
+	writeField(&buf, "emoji", quote(note.Emoji))
+	if note.Mode != "" {
+		writeField(&buf, "mode", quote(note.Mode))
+	}
+	if len(note.Tags) == 0 {
+		writeField(&buf, "tags", "[]")
+	} else {
+		buf.WriteString("tags:\n")
+		for _, tag := range note.Tags {
+			buf.WriteString("  - " + quote(tag) + "\n")
+		}
+	}
+	if note.Reminder != "" {
+		writeField(&buf, "reminder", quote(note.Reminder))
+	}
+	writeField(&buf, "status", StatusOpen)
+	buf.WriteString("---\n\n")
+
+	buf.WriteString("# " + note.Title + "\n\n")
+	if content := strings.TrimSpace(note.Content); content != "" {
+		buf.WriteString(content + "\n\n")
+	}
+
+	if len(note.Attachments) > 0 {
+		buf.WriteString("## Attachments\n\n")
+		for idx, attachment := range note.Attachments {
+			link := attachmentLinks[idx]
+			prefix := ""
+			if isImageName(attachment.Name) {
+				prefix = "!"
+			}
+			buf.WriteString(fmt.Sprintf("- %s[%s](<%s>)\n", prefix, attachment.Name, link))
+		}
+		buf.WriteString("\n")
+	}
+
+	for _, section := range note.Extra {
+		buf.WriteString("## " + section.Title + "\n\n" + strings.TrimSpace(section.Body) + "\n\n")
+	}
+
+	buf.WriteString(fmt.Sprintf("[Open in Discord](%s)\n", note.SourceURL))
+
+	return buf.Bytes()
+}
+
+func writeField(buf *bytes.Buffer, key, value string) {
+	buf.WriteString(key + ": " + value + "\n")
+}
+
+// quote renders value as a YAML double-quoted scalar. JSON strings are valid YAML.
+func quote(value string) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(value)
+
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func isImageName(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".png", ".jpg", ".jpeg", ".gif", ".webp":
+		return true
+	}
+
+	return false
+}
+
+// updateFrontMatter sets fields in the note's front matter, replacing existing keys and
+// appending new ones, and leaves the body untouched.
+func updateFrontMatter(notePath string, fields map[string]string) error {
+	data, err := os.ReadFile(notePath)
+	if err != nil {
+		return fmt.Errorf("read note: %w", err)
+	}
+
+	updated, err := setFrontMatter(data, fields)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(notePath, updated)
+}
+
+func setFrontMatter(data []byte, fields map[string]string) ([]byte, error) {
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	if !strings.HasPrefix(text, "---\n") {
+		return nil, errors.New("note has no front matter")
+	}
+
+	end := strings.Index(text[4:], "\n---\n")
+	if end < 0 {
+		return nil, errors.New("note front matter is not closed")
+	}
+	header := strings.Split(text[4:4+end], "\n")
+	body := text[4+end+len("\n---\n"):]
+
+	pending := make(map[string]bool, len(fields))
+	for key := range fields {
+		pending[key] = true
+	}
+
+	for idx, line := range header {
+		key, _, found := strings.Cut(line, ":")
+		if !found || strings.HasPrefix(line, " ") {
+			continue
+		}
+		if value, ok := fields[key]; ok {
+			header[idx] = key + ": " + value
+			delete(pending, key)
+		}
+	}
+
+	// Keep appended keys in a stable order.
+	for _, key := range sortedKeys(pending) {
+		header = append(header, key+": "+fields[key])
+	}
+
+	return []byte("---\n" + strings.Join(header, "\n") + "\n---\n" + body), nil
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func writeFileAtomic(target string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".note-*.md")
+	if err != nil {
+		return fmt.Errorf("write note: %w", err)
+	}
+
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("write note: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("write note: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("write note: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("write note: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,159 @@
+package vault
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func sampleNote() Note {
+	return Note{
+		ID:        "abc123",
+		Title:     "Release plan: v2/v3",
+		SourceURL: "https://discord.com/channels/1/2/3",
+		Author:    "alice",
+		AuthorID:  "42",
+		Channel:   "general",
+		SavedAt:   time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC),
+		Emoji:     "🔖",
+		Tags:      []string{"bookmark", "general"},
+		Content:   "Ship it \"soon\"",
+		Attachments: []Attachment{
+			{Name: "diagram.png", URL: "https://cdn.example/diagram.png", Data: []byte("png")},
+			{Name: "big.zip", URL: "https://cdn.example/big.zip"},
+		},
+	}
+}
+
+func TestRenderName(t *testing.T) {
+	note := sampleNote()
+
+	if got := RenderName("", note); got != "2026-03-14 Release plan- v2-v3" {
+		t.Fatalf("unexpected default name %q", got)
+	}
+	if got := RenderName("{channel}/{date}-{id}", note); got != "general/2026-03-14-abc123" {
+		t.Fatalf("unexpected folder name %q", got)
+	}
+	if got := RenderName("../../{id}", note); got != "abc123" {
+		t.Fatalf("expected parent segments to be dropped, got %q", got)
+	}
+	if got := RenderName("archive/{id}", note); got != "abc123" {
+		t.Fatalf("expected the archive folder to be skipped, got %q", got)
+	}
+}
+
+func TestSaveWritesFrontMatterAndAttachments(t *testing.T) {
+	root := t.TempDir()
+	v := New(root, "")
+
+	relPath, err := v.Save("user1", "{date} {title}", sampleNote())
+	if err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(root, "user1", relPath))
+	if err != nil {
+		t.Fatalf("note not written: %v", err)
+	}
+	content := string(data)
+
+	for _, want := range []string{
+		"---\nid: \"abc123\"\n",
+		"source: \"https://discord.com/channels/1/2/3\"\n",
+		"saved: 2026-03-14T09:30:00Z\n",
+		"tags:\n  - \"bookmark\"\n  - \"general\"\n",
+		"status: open\n---\n",
+		"Ship it \"soon\"",
+		"- ![diagram.png](<attachments/abc123/diagram.png>)",
+		"- [big.zip](<https://cdn.example/big.zip>)",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("note is missing %q:\n%s", want, content)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(root, "user1", "attachments", "abc123", "diagram.png")); err != nil {
+		t.Fatalf("attachment not written: %v", err)
+	}
+
+	second, err := v.Save("user1", "{date} {title}", sampleNote())
+	if err != nil {
+		t.Fatalf("second Save returned error: %v", err)
+	}
+	if second == relPath || !strings.HasSuffix(second, " (2).md") {
+		t.Fatalf("expected a distinct name for the second note, got %q", second)
+	}
+}
+
+func TestCompleteUpdatesFrontMatter(t *testing.T) {
+	root := t.TempDir()
+	v := New(root, "")
+
+	relPath, err := v.Save("user1", "", sampleNote())
+	if err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+
+	if err := v.Complete("user1", relPath, time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("Complete returned error: %v", err)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(root, "user1", relPath))
+	content := string(data)
+	if !strings.Contains(content, "status: done\n") || !strings.Contains(content, "completed: 2026-03-15T00:00:00Z\n---\n") {
+		t.Fatalf("front matter not updated:\n%s", content)
+	}
+	if strings.Count(content, "status:") != 1 {
+		t.Fatalf("expected status to be replaced, not duplicated:\n%s", content)
+	}
+}
+
+func TestArchiveMovesNoteAndAttachments(t *testing.T) {
+	root := t.TempDir()
+	v := New(root, "")
+
+	relPath, err := v.Save("user1", "{channel}/{id}", sampleNote())
+	if err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+
+	archived, err := v.Archive("user1", relPath, "abc123", time.Now())
+	if err != nil {
+		t.Fatalf("Archive returned error: %v", err)
+	}
+	if archived != "archive/general/abc123.md" {
+		t.Fatalf("unexpected archive path %q", archived)
+	}
+
+	if _, err := os.Stat(filepath.Join(root, "user1", relPath)); !os.IsNotExist(err) {
+		t.Fatalf("expected the original note to be moved")
+	}
+	data, err := os.ReadFile(filepath.Join(root, "user1", "archive", "general", "abc123.md"))
+	if err != nil {
+		t.Fatalf("archived note missing: %v", err)
+	}
+	if !strings.Contains(string(data), "status: archived\n") {
+		t.Fatalf("archived note not marked:\n%s", data)
+	}
+	if _, err := os.Stat(filepath.Join(root, "user1", "archive", "general", "attachments", "abc123", "diagram.png")); err != nil {
+		t.Fatalf("attachments not moved: %v", err)
+	}
+}
+
+func TestNotePathRejectsEscapes(t *testing.T) {
+	v := New(t.TempDir(), "")
+
+	resolved, err := v.notePath("user1", "../../etc/passwd.md")
+	if err != nil {
+		t.Fatalf("notePath returned error: %v", err)
+	}
+	if !strings.HasPrefix(resolved, filepath.Join(v.root, "user1")) {
+		t.Fatalf("path escaped the user directory: %q", resolved)
+	}
+
+	if _, err := v.notePath("user1", "notes.txt"); err == nil {
+		t.Fatalf("expected non-note paths to be rejected")
+	}
+}
@@ -0,0 +1,299 @@
+// Package webhook delivers saved bookmarks to external HTTP endpoints as signed JSON documents.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	// DocumentVersion is bumped whenever a field of Document changes meaning or is removed.
+	DocumentVersion = 1
+	// EventBookmarkSaved is the only event type sent today.
+	EventBookmarkSaved = "bookmark.saved"
+
+	// SignatureHeader carries "sha256=<hex HMAC of timestamp.body>".
+	SignatureHeader = "X-Bookmark-Signature"
+	// TimestampHeader carries the Unix time the request was signed at.
+	TimestampHeader = "X-Bookmark-Timestamp"
+	// EventHeader repeats the document type for receivers that route before parsing.
+	EventHeader = "X-Bookmark-Event"
+
+	defaultTimeout     = 10 * time.Second
+	defaultMaxAttempts = 4
+	defaultBackoff     = 2 * time.Second
+	maxRetryAfter      = 5 * time.Minute
+)
+
+// Document is the JSON payload posted for every bookmark. Fields are only ever added.
+type Document struct {
+	Version     int          `json:"version"`
+	Type        string       `json:"type"`
+	BookmarkID  string       `json:"bookmarkId"`
+	SavedAt     time.Time    `json:"savedAt"`
+	UserID      string       `json:"userId"`
+	Emoji       string       `json:"emoji"`
+	Mode        string       `json:"mode"`
+	Source      Source       `json:"source"`
+	Author      Author       `json:"author"`
+	Content     string       `json:"content"`
+	CreatedAt   time.Time    `json:"createdAt"`
+	Attachments []Attachment `json:"attachments"`
+	Reminder    *Reminder    `json:"reminder"`
+}
+
+// Source locates the bookmarked message.
+type Source struct {
+	GuildID     string `json:"guildId,omitempty"`
+	ChannelID   string `json:"channelId"`
+	MessageID   string `json:"messageId"`
+	ChannelName string `json:"channelName,omitempty"`
+	URL         string `json:"url"`
+}
+
+// Author is the author of the bookmarked message.
+type Author struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	Bot      bool   `json:"bot"`
+}
+
+// Attachment is a file attached to the bookmarked message.
+type Attachment struct {
+	ID          string `json:"id"`
+	Filename    string `json:"filename"`
+	URL         string `json:"url"`
+	ContentType string `json:"contentType,omitempty"`
+	Size        int    `json:"size"`
+}
+
+// Reminder is the reminder scheduled for the bookmark.
+type Reminder struct {
+	At          time.Time `json:"at"`
+	Description string    `json:"description"`
+}
+
+// ErrForbiddenAddress is returned for endpoints on loopback, private or link-local addresses,
+// which members could otherwise use to reach services next to the bot.
+var ErrForbiddenAddress = errors.New("webhook endpoints must be on a public address")
+
+// sharedAddressSpace is the carrier-grade NAT range, private in practice.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// Client posts documents with a timeout per request and retries transient failures. It only
+// connects to public addresses, checked after name resolution so DNS can't point it inward.
+type Client struct {
+	http          *http.Client
+	maxAttempts   int
+	backoff       time.Duration
+	now           func() time.Time
+	allowLoopback bool
+}
+
+// NewClient constructs a Client with the default timeout and retry policy.
+func NewClient() *Client {
+	c := &Client{
+		maxAttempts: defaultMaxAttempts,
+		backoff:     defaultBackoff,
+		now:         time.Now,
+	}
+
+	dialer := &net.Dialer{Timeout: defaultTimeout, Control: c.checkDial}
+	c.http = &http.Client{
+		Timeout: defaultTimeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			ForceAttemptHTTP2:   true,
+			TLSHandshakeTimeout: defaultTimeout,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+
+	return c
+}
+
+// SetAllowLoopback sets whether endpoints on this machine may receive requests, for local
+// development. Private and link-local addresses are always refused.
+func (c *Client) SetAllowLoopback(allow bool) {
+	c.allowLoopback = allow
+}
+
+// CheckHost refuses host when it is an address, or localhost, the client won't connect to.
+// Other names are checked once resolved, when the client connects.
+func (c *Client) CheckHost(host string) error {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return CheckAddress(net.IPv6loopback, c.allowLoopback)
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return CheckAddress(ip, c.allowLoopback)
+	}
+
+	return nil
+}
+
+// CheckAddress reports whether webhook requests may be sent to ip. Loopback addresses are
+// only allowed with allowLoopback.
+func CheckAddress(ip net.IP, allowLoopback bool) error {
+	switch {
+	case ip == nil:
+		return ErrForbiddenAddress
+	case ip.IsLoopback():
+		if allowLoopback {
+			return nil
+		}
+		return ErrForbiddenAddress
+	case ip.IsUnspecified(), ip.IsPrivate(), ip.IsLinkLocalUnicast(), ip.IsLinkLocalMulticast(),
+		ip.IsInterfaceLocalMulticast(), ip.IsMulticast(), sharedAddressSpace.Contains(ip):
+		return ErrForbiddenAddress
+	}
+
+	return nil
+}
+
+// checkDial vets every connection's resolved address, including redirects.
+func (c *Client) checkDial(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if err := CheckAddress(net.ParseIP(host), c.allowLoopback); err != nil {
+		return fmt.Errorf("refusing to connect to %s: %w", host, err)
+	}
+
+	return nil
+}
+
+// NewSecret returns a random signing secret.
+func NewSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf), nil
+}
+
+// Sign returns the signature header value for body signed at timestamp. Receivers recompute it
+// over the raw request body and compare in constant time.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Send posts doc to url. Network errors, 429 and 5xx responses are retried with exponential
+// backoff, honoring Retry-After; other responses fail immediately.
+func (c *Client) Send(ctx context.Context, url, secret string, doc Document) error {
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("encode webhook document: %w", err)
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= c.maxAttempts; attempt++ {
+		wait, retryable, err := c.post(ctx, url, secret, body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retryable || attempt == c.maxAttempts {
+			break
+		}
+
+		if wait <= 0 {
+			wait = c.backoff << (attempt - 1)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+
+	return lastErr
+}
+
+// Attempt posts doc to url once, for callers that schedule retries themselves. It reports how
+// long the server asked to wait and whether the failure is worth retrying.
+func (c *Client) Attempt(ctx context.Context, url, secret string, doc Document) (time.Duration, bool, error) {
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return 0, false, fmt.Errorf("encode webhook document: %w", err)
+	}
+
+	return c.post(ctx, url, secret, body)
+}
+
+// post makes one signed request. It reports how long the server asked to wait and whether the
+// failure is worth retrying.
+func (c *Client) post(ctx context.Context, url, secret string, body []byte) (time.Duration, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, false, fmt.Errorf("build webhook request: %w", err)
+	}
+
+	timestamp := c.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "discord-bookmark-manager")
+	req.Header.Set(EventHeader, EventBookmarkSaved)
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	if secret != "" {
+		req.Header.Set(SignatureHeader, Sign(secret, timestamp, body))
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		retryable := ctx.Err() == nil && !errors.Is(err, ErrForbiddenAddress)
+		return 0, retryable, fmt.Errorf("post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return 0, false, nil
+	}
+
+	err = fmt.Errorf("webhook responded %s", resp.Status)
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return retryAfter(resp.Header.Get("Retry-After")), true, err
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return retryAfter(resp.Header.Get("Retry-After")), true, err
+	default:
+		return 0, false, err
+	}
+}
+
+// retryAfter parses a Retry-After header given in seconds.
+func retryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+
+	wait := time.Duration(seconds) * time.Second
+	if wait > maxRetryAfter {
+		return maxRetryAfter
+	}
+
+	return wait
+}
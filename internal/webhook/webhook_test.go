@@ -0,0 +1,227 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testClient() *Client {
+	client := NewClient()
+	client.backoff = time.Millisecond
+	client.SetAllowLoopback(true)
+	return client
+}
+
+func TestSendSignsDocument(t *testing.T) {
+	const secret = "s3cret"
+
+	var received Document
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		timestamp, err := strconv.ParseInt(r.Header.Get(TimestampHeader), 10, 64)
+		if err != nil {
+			t.Errorf("invalid timestamp header: %v", err)
+		}
+		expected := Sign(secret, timestamp, body)
+		if !hmac.Equal([]byte(expected), []byte(r.Header.Get(SignatureHeader))) {
+			t.Errorf("signature mismatch: got %q want %q", r.Header.Get(SignatureHeader), expected)
+		}
+		if r.Header.Get(EventHeader) != EventBookmarkSaved {
+			t.Errorf("unexpected event header %q", r.Header.Get(EventHeader))
+		}
+
+		if err := json.Unmarshal(body, &received); err != nil {
+			t.Errorf("invalid body: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	doc := Document{
+		Version:    DocumentVersion,
+		Type:       EventBookmarkSaved,
+		BookmarkID: "abc",
+		Emoji:      "🔖",
+		Mode:       "balanced",
+		Source:     Source{GuildID: "g", ChannelID: "c", MessageID: "m", URL: "https://discord.com/channels/g/c/m"},
+		Author:     Author{ID: "u", Username: "alice"},
+		Content:    "hello",
+		Attachments: []Attachment{
+			{ID: "a", Filename: "notes.txt", URL: "https://cdn.example/notes.txt", Size: 12},
+		},
+	}
+
+	if err := testClient().Send(context.Background(), server.URL, secret, doc); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+
+	if received.BookmarkID != "abc" || received.Source.MessageID != "m" || len(received.Attachments) != 1 {
+		t.Fatalf("unexpected document %+v", received)
+	}
+}
+
+func TestSendRetriesServerErrors(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	if err := testClient().Send(context.Background(), server.URL, "", Document{}); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 attempts, got %d", calls)
+	}
+}
+
+func TestSendDoesNotRetryClientErrors(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	if err := testClient().Send(context.Background(), server.URL, "", Document{}); err == nil {
+		t.Fatalf("expected an error for 401")
+	}
+	if calls != 1 {
+		t.Fatalf("expected a single attempt, got %d", calls)
+	}
+}
+
+func TestSendGivesUpAfterMaxAttempts(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := testClient()
+	if err := client.Send(context.Background(), server.URL, "", Document{}); err == nil {
+		t.Fatalf("expected an error after exhausting retries")
+	}
+	if int(calls) != client.maxAttempts {
+		t.Fatalf("expected %d attempts, got %d", client.maxAttempts, calls)
+	}
+}
+
+func TestSendTimesOut(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := testClient()
+	client.http.Timeout = 20 * time.Millisecond
+	client.maxAttempts = 1
+
+	if err := client.Send(context.Background(), server.URL, "", Document{}); err == nil {
+		t.Fatalf("expected a timeout error")
+	}
+}
+
+func TestAttemptReportsRetryAfter(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	wait, retryable, err := testClient().Attempt(context.Background(), server.URL, "", Document{})
+	if err == nil || !retryable || wait != 7*time.Second {
+		t.Fatalf("expected a retryable failure with a 7s wait, got %s, %v, %v", wait, retryable, err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected a single request, got %d", calls)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	if wait := retryAfter("3"); wait != 3*time.Second {
+		t.Fatalf("expected 3s, got %s", wait)
+	}
+	if wait := retryAfter("86400"); wait != maxRetryAfter {
+		t.Fatalf("expected the cap, got %s", wait)
+	}
+	if wait := retryAfter("soon"); wait != 0 {
+		t.Fatalf("expected no wait for an invalid header, got %s", wait)
+	}
+}
+
+func TestSendRefusesLoopbackByDefault(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.backoff = time.Millisecond
+	err := client.Send(context.Background(), server.URL, "", Document{})
+	if !errors.Is(err, ErrForbiddenAddress) {
+		t.Fatalf("expected the loopback endpoint to be refused, got %v", err)
+	}
+	if calls != 0 {
+		t.Fatalf("expected no request to reach the server, got %d", calls)
+	}
+}
+
+func TestCheckAddress(t *testing.T) {
+	refused := []string{"127.0.0.1", "::1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "fe80::1", "fd00::1", "100.64.0.1", "0.0.0.0", "::ffff:10.0.0.1"}
+	for _, raw := range refused {
+		if err := CheckAddress(net.ParseIP(raw), false); err == nil {
+			t.Errorf("expected %s to be refused", raw)
+		}
+	}
+	for _, raw := range []string{"93.184.216.34", "2606:4700::1"} {
+		if err := CheckAddress(net.ParseIP(raw), false); err != nil {
+			t.Errorf("expected %s to be allowed, got %v", raw, err)
+		}
+	}
+
+	if err := CheckAddress(net.ParseIP("127.0.0.1"), true); err != nil {
+		t.Errorf("expected loopback to be allowed by the flag, got %v", err)
+	}
+	if err := CheckAddress(net.ParseIP("10.0.0.1"), true); err == nil {
+		t.Errorf("expected private addresses to stay refused with the flag")
+	}
+}
+
+func TestCheckHost(t *testing.T) {
+	client := NewClient()
+	for _, host := range []string{"localhost", "api.localhost", "127.0.0.1", "169.254.169.254"} {
+		if err := client.CheckHost(host); err == nil {
+			t.Errorf("expected %s to be refused", host)
+		}
+	}
+	if err := client.CheckHost("example.com"); err != nil {
+		t.Errorf("expected names to be checked when dialing, got %v", err)
+	}
+
+	client.SetAllowLoopback(true)
+	if err := client.CheckHost("localhost"); err != nil {
+		t.Errorf("expected localhost to be allowed by the flag, got %v", err)
+	}
+}